@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+type Endpoints struct {
+	SuspendEndpoint     endpoint.Endpoint
+	ResumeEndpoint      endpoint.Endpoint
+	RevokeTokenEndpoint endpoint.Endpoint
+	AnnounceEndpoint    endpoint.Endpoint
+}
+
+// Suspend Endpoint
+type SuspendRequest struct {
+	AccountId user.AccountId `json:"-"`
+	Reason    string         `json:"reason"`
+}
+
+func (e Endpoints) Suspend(ctx context.Context, accountId user.AccountId, reason string) (*user.Account, error) {
+	response, err := e.SuspendEndpoint(ctx, SuspendRequest{AccountId: accountId, Reason: reason})
+	if err != nil {
+		return nil, err
+	}
+	return response.(*user.Account), nil
+}
+
+func MakeSuspendEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(SuspendRequest)
+		account, err := s.Suspend(ctx, req.AccountId, req.Reason)
+		if err != nil {
+			return nil, err
+		}
+		return account, nil
+	}
+}
+
+// Resume Endpoint
+func (e Endpoints) Resume(ctx context.Context, accountId user.AccountId) (*user.Account, error) {
+	response, err := e.ResumeEndpoint(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+	return response.(*user.Account), nil
+}
+
+func MakeResumeEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		id := request.(user.AccountId)
+		account, err := s.Resume(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return account, nil
+	}
+}
+
+// RevokeToken Endpoint
+func (e Endpoints) RevokeToken(ctx context.Context, accountId user.AccountId) (*user.Account, error) {
+	response, err := e.RevokeTokenEndpoint(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+	return response.(*user.Account), nil
+}
+
+func MakeRevokeTokenEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		id := request.(user.AccountId)
+		account, err := s.RevokeToken(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return account, nil
+	}
+}
+
+// Announce Endpoint
+type AnnounceRequest struct {
+	Message string `json:"message"`
+}
+
+type AnnounceResponse struct{}
+
+func (e Endpoints) Announce(ctx context.Context, message string) error {
+	_, err := e.AnnounceEndpoint(ctx, AnnounceRequest{Message: message})
+	return err
+}
+
+func MakeAnnounceEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(AnnounceRequest)
+		if err := s.Announce(ctx, req.Message); err != nil {
+			return nil, err
+		}
+		return AnnounceResponse{}, nil
+	}
+}
@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+type Middleware func(Service) Service
+
+// EndpointAuthMiddleware only lets requests with the admin token through.
+func EndpointAuthMiddleware(logger log.Logger, adminToken string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			token, _ := ctx.Value("admin_token").(string)
+			if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+				logger.Log("msg", "Rejected admin request")
+				return nil, ErrUnauthorized
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+func EndpointLoggingMiddleware(logger log.Logger) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				logger.Log("layer", "endpoint", "error", err, "took", time.Since(begin))
+			}(time.Now())
+			return next(ctx, request)
+
+		}
+	}
+}
+
+func ServiceLoggingMiddleware(logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return serviceLoggingMiddleware{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+type serviceLoggingMiddleware struct {
+	logger log.Logger
+	next   Service
+}
+
+func (mw serviceLoggingMiddleware) Suspend(ctx context.Context, accountId user.AccountId, reason string) (v *user.Account, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "Suspend",
+			"layer", "service",
+			"accountId", accountId,
+			"reason", reason,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.Suspend(ctx, accountId, reason)
+}
+
+func (mw serviceLoggingMiddleware) Resume(ctx context.Context, accountId user.AccountId) (v *user.Account, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "Resume",
+			"layer", "service",
+			"accountId", accountId,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.Resume(ctx, accountId)
+}
+
+func (mw serviceLoggingMiddleware) RevokeToken(ctx context.Context, accountId user.AccountId) (v *user.Account, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "RevokeToken",
+			"layer", "service",
+			"accountId", accountId,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.RevokeToken(ctx, accountId)
+}
+
+func (mw serviceLoggingMiddleware) Announce(ctx context.Context, message string) (err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "Announce",
+			"layer", "service",
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.Announce(ctx, message)
+}
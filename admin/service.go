@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"errors"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+var (
+	ErrUnauthorized = errors.New("Unauthorized")
+	ErrEmptyMessage = errors.New("Empty Message")
+)
+
+const tokenRevokedReason = "Token Revoked"
+
+/*
+Admin Service
+-------------
+
+The admin service exposes operator only operations on accounts.
+It is protected by a single admin token and is only mounted
+when that token is configured.
+*/
+
+type Service interface {
+	Suspend(ctx context.Context, accountId user.AccountId, reason string) (*user.Account, error)
+	Resume(ctx context.Context, accountId user.AccountId) (*user.Account, error)
+	RevokeToken(ctx context.Context, accountId user.AccountId) (*user.Account, error)
+	Announce(ctx context.Context, message string) error
+}
+
+func NewBasicService(accounts user.AccountStore, queue tunnel.HookQueue) Service {
+	return &basicService{
+		accounts: accounts,
+		queue:    queue,
+	}
+}
+
+type basicService struct {
+	accounts user.AccountStore
+	queue    tunnel.HookQueue
+}
+
+func (s basicService) Suspend(ctx context.Context, accountId user.AccountId, reason string) (*user.Account, error) {
+	account, err := s.accounts.Find(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *account
+	updated.Suspended = true
+	updated.SuspendReason = reason
+	if err := user.AccountsWithContext(ctx, s.accounts).Update(&updated); err != nil {
+		return nil, err
+	}
+
+	// Close the sessions of the account in every gohookd process
+	err = s.queue.Broadcast(&tunnel.QueueMessage{
+		Type:      tunnel.MessageDisconnect,
+		AccountId: accountId,
+		Reason:    user.ErrAccountSuspended.Error(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s basicService) Resume(ctx context.Context, accountId user.AccountId) (*user.Account, error) {
+	account, err := s.accounts.Find(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *account
+	updated.Suspended = false
+	updated.SuspendReason = ""
+	if err := user.AccountsWithContext(ctx, s.accounts).Update(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// RevokeToken replaces the token of the account. The sessions opened
+// with the old token are told about it and closed.
+func (s basicService) RevokeToken(ctx context.Context, accountId user.AccountId) (*user.Account, error) {
+	account, err := s.accounts.Find(accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *account
+	updated.Token = user.AccountToken(uuid.NewV4().String())
+	if err := user.AccountsWithContext(ctx, s.accounts).Update(&updated); err != nil {
+		return nil, err
+	}
+
+	err = s.queue.Broadcast(&tunnel.QueueMessage{
+		Type:      tunnel.MessageDisconnect,
+		AccountId: accountId,
+		Reason:    tokenRevokedReason,
+		Event: &tunnel.Event{
+			Type:    tunnel.EventTokenRevoked,
+			Message: tokenRevokedReason,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Announce sends the message to every connected client.
+func (s basicService) Announce(_ context.Context, message string) error {
+	if message == "" {
+		return ErrEmptyMessage
+	}
+	return s.queue.Broadcast(&tunnel.QueueMessage{
+		Type: tunnel.MessageEvent,
+		Event: &tunnel.Event{
+			Type:    tunnel.EventAnnouncement,
+			Message: message,
+		},
+	})
+}
@@ -0,0 +1,154 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gohook/gohook-server/user"
+	"github.com/gorilla/mux"
+	"golang.org/x/net/context"
+)
+
+func MakeAdminHTTPServer(ctx context.Context, endpoints Endpoints, logger log.Logger) http.Handler {
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(errorEncoder),
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerBefore(extractAdminToken, extractClientIP),
+	}
+	m := mux.NewRouter()
+	m.Handle("/admin/accounts/{accountId}/suspend", httptransport.NewServer(
+		ctx,
+		endpoints.SuspendEndpoint,
+		DecodeHTTPSuspendRequest,
+		EncodeHTTPAccountResponse,
+		options...,
+	)).Methods("POST")
+	m.Handle("/admin/accounts/{accountId}/resume", httptransport.NewServer(
+		ctx,
+		endpoints.ResumeEndpoint,
+		DecodeHTTPResumeRequest,
+		EncodeHTTPAccountResponse,
+		options...,
+	)).Methods("POST")
+	m.Handle("/admin/accounts/{accountId}/revoke-token", httptransport.NewServer(
+		ctx,
+		endpoints.RevokeTokenEndpoint,
+		DecodeHTTPResumeRequest,
+		EncodeHTTPAccountResponse,
+		options...,
+	)).Methods("POST")
+	m.Handle("/admin/announcements", httptransport.NewServer(
+		ctx,
+		endpoints.AnnounceEndpoint,
+		DecodeHTTPAnnounceRequest,
+		EncodeHTTPAnnounceResponse,
+		options...,
+	)).Methods("POST")
+	return m
+}
+
+// RequireToken guards a plain http handler, such as the expvar
+// handler, with the admin token.
+func RequireToken(adminToken string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := extractAdminToken(context.Background(), r).Value("admin_token").(string)
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			errorEncoder(context.Background(), httptransport.Error{Domain: httptransport.DomainDo, Err: ErrUnauthorized}, w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func extractAdminToken(ctx context.Context, r *http.Request) context.Context {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return context.WithValue(ctx, "admin_token", strings.TrimSpace(auth[7:]))
+	}
+	return ctx
+}
+
+func extractClientIP(ctx context.Context, r *http.Request) context.Context {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return context.WithValue(ctx, "client_ip", host)
+}
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+func errorEncoder(_ context.Context, err error, w http.ResponseWriter) {
+	code := http.StatusInternalServerError
+	msg := err.Error()
+
+	if e, ok := err.(httptransport.Error); ok {
+		msg = e.Err.Error()
+		switch e.Domain {
+		case httptransport.DomainDecode:
+			code = http.StatusBadRequest
+
+		case httptransport.DomainDo:
+			code = http.StatusBadRequest
+			if e.Err == ErrUnauthorized {
+				code = http.StatusUnauthorized
+			}
+		}
+	}
+
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorWrapper{Error: msg})
+}
+
+func DecodeHTTPSuspendRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := SuspendRequest{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, err
+		}
+	}
+	req.AccountId = user.AccountId(mux.Vars(r)["accountId"])
+	return req, nil
+}
+
+// DecodeHTTPResumeRequest reads the account id of the path. It is used
+// by every request on a single account without a body.
+func DecodeHTTPResumeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return user.AccountId(mux.Vars(r)["accountId"]), nil
+}
+
+func DecodeHTTPAnnounceRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := AnnounceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func EncodeHTTPAnnounceResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.WriteHeader(http.StatusAccepted)
+	return json.NewEncoder(w).Encode(response)
+}
+
+type accountResponse struct {
+	Id            user.AccountId `json:"id"`
+	Suspended     bool           `json:"suspended"`
+	SuspendReason string         `json:"suspend_reason,omitempty"`
+}
+
+// EncodeHTTPAccountResponse writes the account without its token.
+func EncodeHTTPAccountResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	account := response.(*user.Account)
+	return json.NewEncoder(w).Encode(accountResponse{
+		Id:            account.Id,
+		Suspended:     account.Suspended,
+		SuspendReason: account.SuspendReason,
+	})
+}
@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+type accountStore struct {
+	next   user.AccountStore
+	store  Store
+	logger log.Logger
+	// request the changes are made for, nil for system changes
+	ctx context.Context
+}
+
+// NewAccountStore wraps an account store so that every change to an
+// account is written to the audit log. Changes are recorded as made
+// by the system, unless the store is scoped to a request with
+// user.AccountsWithContext. A failed audit write is logged, the change
+// itself has already been made.
+func NewAccountStore(next user.AccountStore, store Store, logger log.Logger) user.ContextAccountStore {
+	return &accountStore{
+		next:   next,
+		store:  store,
+		logger: logger,
+	}
+}
+
+func (s *accountStore) WithContext(ctx context.Context) user.AccountStore {
+	scoped := *s
+	scoped.ctx = ctx
+	return &scoped
+}
+
+func (s *accountStore) Add(account *user.Account) error {
+	if err := s.next.Add(account); err != nil {
+		return err
+	}
+	s.record(OpAccountAdd, account.Id, nil, account)
+	return nil
+}
+
+func (s *accountStore) Update(account *user.Account) error {
+	before, err := s.next.Find(account.Id)
+	if err != nil {
+		return err
+	}
+	// copy the old state before the underlying store changes it
+	old := *before
+	if err := s.next.Update(account); err != nil {
+		return err
+	}
+	op := OpAccountUpdate
+	switch {
+	case old.Token != account.Token:
+		op = OpTokenChange
+	case !old.Suspended && account.Suspended:
+		op = OpSuspend
+	case old.Suspended && !account.Suspended:
+		op = OpResume
+	}
+	s.record(op, account.Id, &old, account)
+	return nil
+}
+
+func (s *accountStore) Remove(accountId user.AccountId) (*user.Account, error) {
+	account, err := s.next.Remove(accountId)
+	if err != nil {
+		return nil, err
+	}
+	s.record(OpAccountRemove, accountId, account, nil)
+	return account, nil
+}
+
+func (s *accountStore) Find(accountId user.AccountId) (*user.Account, error) {
+	return s.next.Find(accountId)
+}
+
+func (s *accountStore) FindByToken(token user.AccountToken) (*user.Account, error) {
+	return s.next.FindByToken(token)
+}
+
+func (s *accountStore) record(op Operation, accountId user.AccountId, before, after *user.Account) {
+	actor := ActorFromContext(s.ctx)
+	entry := &Entry{
+		AccountId: accountId,
+		Actor:     actor.Name,
+		TokenId:   actor.TokenId,
+		ClientIP:  actor.ClientIP,
+		Operation: op,
+		Before:    accountSnapshot(before),
+		After:     accountSnapshot(after),
+		Time:      time.Now(),
+	}
+	if err := s.store.Add(entry); err != nil {
+		s.logger.Log("msg", "Audit write failed", "account_id", accountId, "operation", op, "err", err)
+	}
+}
+
+// accountSnapshot records an account with its token replaced by
+// the token id.
+func accountSnapshot(account *user.Account) []byte {
+	if account == nil {
+		return nil
+	}
+	snapshot := *account
+	snapshot.Token = user.AccountToken(TokenId(account.Token))
+	b, _ := json.Marshal(snapshot)
+	return b
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+/*
+Audit Log
+---------
+
+The audit log is an append-only record of every change made
+to an account and its hooks. Each entry records who made the
+change, from where, and a snapshot of the affected resource
+before and after the change.
+*/
+
+type EntryID string
+
+type Operation string
+
+const (
+	OpHookCreate    Operation = "hook.create"
+	OpHookDelete    Operation = "hook.delete"
+	OpAccountAdd    Operation = "account.add"
+	OpAccountUpdate Operation = "account.update"
+	OpAccountRemove Operation = "account.remove"
+	OpTokenChange   Operation = "account.token"
+	OpSuspend       Operation = "account.suspend"
+	OpResume        Operation = "account.resume"
+)
+
+// SystemActor is recorded as the actor for changes that are not
+// made on behalf of an authenticated account.
+const SystemActor = "system"
+
+// AdminActor is recorded for changes made through the admin API.
+const AdminActor = "admin"
+
+// Actor is who made a change, and from where.
+type Actor struct {
+	Name     string
+	TokenId  string
+	ClientIP string
+}
+
+// ActorFromContext resolves the actor of a request from the
+// authenticated account or the admin token in ctx. Requests with
+// neither are made by the system.
+func ActorFromContext(ctx context.Context) Actor {
+	if ctx == nil {
+		return Actor{Name: SystemActor}
+	}
+	clientIP, _ := ctx.Value("client_ip").(string)
+	if account, ok := ctx.Value("account").(*user.Account); ok && account != nil {
+		return Actor{
+			Name:     string(account.Id),
+			TokenId:  TokenId(account.Token),
+			ClientIP: clientIP,
+		}
+	}
+	if token, _ := ctx.Value("admin_token").(string); token != "" {
+		return Actor{
+			Name:     AdminActor,
+			TokenId:  TokenId(user.AccountToken(token)),
+			ClientIP: clientIP,
+		}
+	}
+	return Actor{Name: SystemActor, ClientIP: clientIP}
+}
+
+type Entry struct {
+	Id        EntryID        `json:"id"`
+	AccountId user.AccountId `json:"account_id"`
+	Actor     string         `json:"actor"`
+	TokenId   string         `json:"token_id"`
+	ClientIP  string         `json:"client_ip"`
+	Operation Operation      `json:"operation"`
+	// Before and After hold JSON snapshots of the changed resource.
+	Before []byte    `json:"before"`
+	After  []byte    `json:"after"`
+	Time   time.Time `json:"time"`
+}
+
+type EntryList []*Entry
+
+// Store is an append-only store for audit entries.
+type Store interface {
+	Add(entry *Entry) error
+	// FindByAccount returns the entries for an account, newest first.
+	FindByAccount(accountId user.AccountId) (EntryList, error)
+}
+
+// TokenId derives a stable identifier for a token that can be
+// recorded without leaking the token itself.
+func TokenId(token user.AccountToken) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
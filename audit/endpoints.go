@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/net/context"
+)
+
+type Endpoints struct {
+	AuditLogEndpoint endpoint.Endpoint
+}
+
+// AuditLog Endpoint
+type auditLogRequest struct{}
+
+func (e Endpoints) AuditLog(ctx context.Context) (EntryList, error) {
+	response, err := e.AuditLogEndpoint(ctx, auditLogRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return response.(EntryList), nil
+}
+
+func MakeAuditLogEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (response interface{}, err error) {
+		entries, err := s.AuditLog(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return entries, nil
+	}
+}
@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"golang.org/x/net/context"
+)
+
+type Middleware func(Service) Service
+
+func ServiceLoggingMiddleware(logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return serviceLoggingMiddleware{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+type serviceLoggingMiddleware struct {
+	logger log.Logger
+	next   Service
+}
+
+func (mw serviceLoggingMiddleware) AuditLog(ctx context.Context) (v EntryList, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "AuditLog",
+			"layer", "service",
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.AuditLog(ctx)
+}
@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+type Service interface {
+	AuditLog(ctx context.Context) (EntryList, error)
+}
+
+func NewBasicService(store Store) Service {
+	return &basicService{
+		store: store,
+	}
+}
+
+type basicService struct {
+	store Store
+}
+
+func (s basicService) AuditLog(ctx context.Context) (EntryList, error) {
+	account := ctx.Value("account").(*user.Account)
+	entries, err := s.store.FindByAccount(account.Id)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
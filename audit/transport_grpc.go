@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"github.com/gohook/gohook-server/pb"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+type AuditServer struct {
+	auditLog grpctransport.Handler
+}
+
+func extractAuthToken(ctx context.Context, md *metadata.MD) context.Context {
+	if token, ok := (*md)["token"]; ok && len(token) > 0 {
+		return context.WithValue(ctx, "token", token[0])
+	}
+	return ctx
+}
+
+func MakeAuditServer(ctx context.Context, endpoints Endpoints, logger log.Logger) *AuditServer {
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorLogger(logger),
+		grpctransport.ServerBefore(extractAuthToken),
+	}
+	return &AuditServer{
+		auditLog: grpctransport.NewServer(
+			ctx,
+			endpoints.AuditLogEndpoint,
+			DecodeGRPCAuditLogRequest,
+			EncodeGRPCAuditLogResponse,
+			options...,
+		),
+	}
+}
+
+// AuditLog transport handler
+func (s *AuditServer) AuditLog(ctx context.Context, req *pb.AuditLogRequest) (*pb.AuditLogResponse, error) {
+	_, rep, err := s.auditLog.ServeGRPC(ctx, req)
+	if err != nil {
+		if err == user.ErrAccountSuspended {
+			return nil, grpc.Errorf(codes.PermissionDenied, "%v", err)
+		}
+		return nil, err
+	}
+	return rep.(*pb.AuditLogResponse), nil
+}
+
+// AuditLog transforms
+func EncodeGRPCAuditLogRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.AuditLogRequest{}, nil
+}
+
+func DecodeGRPCAuditLogRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return auditLogRequest{}, nil
+}
+
+func EncodeGRPCAuditLogResponse(_ context.Context, response interface{}) (interface{}, error) {
+	entries := response.(EntryList)
+	pbEntries := []*pb.AuditEntry{}
+	for _, e := range entries {
+		pbEntries = append(pbEntries, &pb.AuditEntry{
+			Id:        string(e.Id),
+			AccountId: string(e.AccountId),
+			Actor:     e.Actor,
+			TokenId:   e.TokenId,
+			ClientIp:  e.ClientIP,
+			Operation: string(e.Operation),
+			Before:    e.Before,
+			After:     e.After,
+			Time:      e.Time.UnixNano(),
+		})
+	}
+	return &pb.AuditLogResponse{Entries: pbEntries}, nil
+}
+
+func DecodeGRPCAuditLogResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	resp := grpcReply.(*pb.AuditLogResponse)
+	entries := EntryList{}
+	for _, e := range resp.Entries {
+		entries = append(entries, &Entry{
+			Id:        EntryID(e.Id),
+			AccountId: user.AccountId(e.AccountId),
+			Actor:     e.Actor,
+			TokenId:   e.TokenId,
+			ClientIP:  e.ClientIp,
+			Operation: Operation(e.Operation),
+			Before:    e.Before,
+			After:     e.After,
+			Time:      time.Unix(0, e.Time),
+		})
+	}
+	return entries, nil
+}
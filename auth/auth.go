@@ -15,5 +15,12 @@ func NewAuthService(accounts user.AccountStore) user.AuthService {
 }
 
 func (a basicAuthService) AuthAccountFromToken(token string) (*user.Account, error) {
-	return a.accounts.FindByToken(user.AccountToken(token))
+	account, err := a.accounts.FindByToken(user.AccountToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if account.Suspended {
+		return nil, user.ErrAccountSuspended
+	}
+	return account, nil
 }
@@ -0,0 +1,115 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	accountsBucket = []byte("accounts")
+	// token -> account id
+	tokensBucket = []byte("account_tokens")
+)
+
+type BoltAccountStore struct {
+	db *bolt.DB
+}
+
+func NewBoltAccountStore(db *bolt.DB) (user.AccountStore, error) {
+	if err := createBuckets(db, accountsBucket, tokensBucket); err != nil {
+		return nil, err
+	}
+	return &BoltAccountStore{
+		db: db,
+	}, nil
+}
+
+func (d *BoltAccountStore) Add(u *user.Account) error {
+	u.Id = user.AccountId(uuid.NewV4().String())
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return putAccount(tx, u)
+	})
+}
+
+func (d *BoltAccountStore) Update(u *user.Account) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		old, err := getAccount(tx, u.Id)
+		if err != nil {
+			return err
+		}
+		// the token may have been rotated
+		if err := tx.Bucket(tokensBucket).Delete([]byte(old.Token)); err != nil {
+			return err
+		}
+		return putAccount(tx, u)
+	})
+}
+
+func (d *BoltAccountStore) Remove(id user.AccountId) (*user.Account, error) {
+	var account *user.Account
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		if account, err = getAccount(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Bucket(tokensBucket).Delete([]byte(account.Token)); err != nil {
+			return err
+		}
+		return tx.Bucket(accountsBucket).Delete([]byte(id))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (d *BoltAccountStore) Find(id user.AccountId) (*user.Account, error) {
+	var account *user.Account
+	err := d.db.View(func(tx *bolt.Tx) error {
+		var err error
+		account, err = getAccount(tx, id)
+		return err
+	})
+	return account, err
+}
+
+func (d *BoltAccountStore) FindByToken(token user.AccountToken) (*user.Account, error) {
+	var account *user.Account
+	err := d.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(tokensBucket).Get([]byte(token))
+		if id == nil {
+			return errors.New("Not Found")
+		}
+		var err error
+		account, err = getAccount(tx, user.AccountId(id))
+		return err
+	})
+	return account, err
+}
+
+func getAccount(tx *bolt.Tx, id user.AccountId) (*user.Account, error) {
+	data := tx.Bucket(accountsBucket).Get([]byte(id))
+	if data == nil {
+		return nil, errors.New("Not Found")
+	}
+	account := &user.Account{}
+	if err := json.Unmarshal(data, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func putAccount(tx *bolt.Tx, u *user.Account) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(accountsBucket).Put([]byte(u.Id), data); err != nil {
+		return err
+	}
+	return tx.Bucket(tokensBucket).Put([]byte(u.Token), []byte(u.Id))
+}
@@ -0,0 +1,64 @@
+package boltdb
+
+import (
+	"encoding/json"
+
+	"github.com/gohook/gohook-server/audit"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// entries are keyed by insertion order like the history
+var auditBucket = []byte("audit")
+
+type BoltAuditStore struct {
+	db *bolt.DB
+}
+
+func NewBoltAuditStore(db *bolt.DB) (audit.Store, error) {
+	if err := createBuckets(db, auditBucket); err != nil {
+		return nil, err
+	}
+	return &BoltAuditStore{
+		db: db,
+	}, nil
+}
+
+// Add inserts a new entry. Entries are never updated or removed.
+func (d *BoltAuditStore) Add(e *audit.Entry) error {
+	e.Id = audit.EntryID(uuid.NewV4().String())
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(auditBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(itob(seq), data)
+	})
+}
+
+func (d *BoltAuditStore) FindByAccount(accountId user.AccountId) (audit.EntryList, error) {
+	result := audit.EntryList{}
+	err := d.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(auditBucket).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			e := &audit.Entry{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return err
+			}
+			if e.AccountId == accountId {
+				result = append(result, e)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package boltdb
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+/*
+Bolt Stores
+-----------
+
+The stores of this package keep everything in a single bolt
+database file, so a gohookd process can run without Mongo and
+Redis. Bolt locks the file, so only one process can use it at a
+time.
+*/
+
+// DBFile is the name of the database file in the data directory.
+const DBFile = "gohook.db"
+
+// Open opens the database of the data directory, creating both when
+// they do not exist yet.
+func Open(dir string) (*bolt.DB, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return bolt.Open(filepath.Join(dir, DBFile), 0600, &bolt.Options{Timeout: time.Second})
+}
+
+func createBuckets(db *bolt.DB, names ...[]byte) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, name := range names {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// itob encodes sequence numbers as keys that sort in numeric order.
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
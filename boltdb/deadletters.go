@@ -0,0 +1,154 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// holds a bucket per account with its letters keyed by insertion
+// order
+var deadLettersBucket = []byte("dead_letters")
+
+type BoltDeadLetterStore struct {
+	db *bolt.DB
+}
+
+func NewBoltDeadLetterStore(db *bolt.DB) (tunnel.DeadLetterStore, error) {
+	if err := createBuckets(db, deadLettersBucket); err != nil {
+		return nil, err
+	}
+	return &BoltDeadLetterStore{
+		db: db,
+	}, nil
+}
+
+func (d *BoltDeadLetterStore) Add(l *tunnel.DeadLetter) error {
+	l.Id = tunnel.DeadLetterId(uuid.NewV4().String())
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(deadLettersBucket).CreateBucketIfNotExists([]byte(l.AccountId))
+		if err != nil {
+			return err
+		}
+
+		// the expired letters come first
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.First() {
+			old := tunnel.DeadLetter{}
+			if err := json.Unmarshal(v, &old); err == nil && time.Since(old.Time) <= tunnel.DeadLetterRetention {
+				break
+			}
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(itob(seq), data)
+	})
+}
+
+func (d *BoltDeadLetterStore) FindByAccount(accountId user.AccountId) (tunnel.DeadLetterList, error) {
+	result := tunnel.DeadLetterList{}
+	err := d.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(deadLettersBucket).Bucket([]byte(accountId))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			l := &tunnel.DeadLetter{}
+			if err := json.Unmarshal(v, l); err != nil {
+				return err
+			}
+			if time.Since(l.Time) <= tunnel.DeadLetterRetention {
+				result = append(result, l)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (d *BoltDeadLetterStore) Find(accountId user.AccountId, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	var letter *tunnel.DeadLetter
+	err := d.db.View(func(tx *bolt.Tx) error {
+		var err error
+		letter, _, err = findDeadLetter(tx, accountId, id)
+		return err
+	})
+	return letter, err
+}
+
+func (d *BoltDeadLetterStore) Remove(accountId user.AccountId, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	var letter *tunnel.DeadLetter
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		var (
+			key []byte
+			err error
+		)
+		if letter, key, err = findDeadLetter(tx, accountId, id); err != nil {
+			return err
+		}
+		return tx.Bucket(deadLettersBucket).Bucket([]byte(accountId)).Delete(key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return letter, nil
+}
+
+func (d *BoltDeadLetterStore) Purge(accountId user.AccountId) (int, error) {
+	n := 0
+	err := d.db.Update(func(tx *bolt.Tx) error {
+		parent := tx.Bucket(deadLettersBucket)
+		b := parent.Bucket([]byte(accountId))
+		if b == nil {
+			return nil
+		}
+		if err := b.ForEach(func(k, v []byte) error {
+			n++
+			return nil
+		}); err != nil {
+			return err
+		}
+		return parent.DeleteBucket([]byte(accountId))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// findDeadLetter returns the letter together with its key.
+func findDeadLetter(tx *bolt.Tx, accountId user.AccountId, id tunnel.DeadLetterId) (*tunnel.DeadLetter, []byte, error) {
+	b := tx.Bucket(deadLettersBucket).Bucket([]byte(accountId))
+	if b == nil {
+		return nil, nil, tunnel.ErrDeadLetterNotFound
+	}
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		l := &tunnel.DeadLetter{}
+		if err := json.Unmarshal(v, l); err != nil {
+			return nil, nil, err
+		}
+		if l.Id == id {
+			return l, k, nil
+		}
+	}
+	return nil, nil, tunnel.ErrDeadLetterNotFound
+}
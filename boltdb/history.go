@@ -0,0 +1,87 @@
+package boltdb
+
+import (
+	"encoding/json"
+
+	"github.com/gohook/gohook-server/gohookd"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// deliveries are keyed by insertion order, so reading the bucket
+// backwards returns the newest first
+var historyBucket = []byte("history")
+
+type BoltHistoryStore struct {
+	db        *bolt.DB
+	accountId user.AccountId
+	scoped    bool
+}
+
+func NewBoltHistoryStore(db *bolt.DB) (gohookd.HistoryStore, error) {
+	if err := createBuckets(db, historyBucket); err != nil {
+		return nil, err
+	}
+	return &BoltHistoryStore{
+		db:     db,
+		scoped: false,
+	}, nil
+}
+
+func (d BoltHistoryStore) Scope(accountId user.AccountId) gohookd.HistoryStore {
+	d.accountId = accountId
+	d.scoped = true
+	return &d
+}
+
+func (d *BoltHistoryStore) Add(m *gohookd.Delivery) error {
+	if d.scoped {
+		m.AccountId = d.accountId
+	}
+	m.Id = gohookd.DeliveryID(uuid.NewV4().String())
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(itob(seq), data)
+	})
+}
+
+func (d *BoltHistoryStore) FindAll() (gohookd.DeliveryList, error) {
+	return d.find(func(*gohookd.Delivery) bool { return true })
+}
+
+func (d *BoltHistoryStore) FindByHook(hookId gohookd.HookID) (gohookd.DeliveryList, error) {
+	return d.find(func(m *gohookd.Delivery) bool { return m.HookId == hookId })
+}
+
+func (d *BoltHistoryStore) find(match func(*gohookd.Delivery) bool) (gohookd.DeliveryList, error) {
+	result := gohookd.DeliveryList{}
+	err := d.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(historyBucket).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			m := &gohookd.Delivery{}
+			if err := json.Unmarshal(v, m); err != nil {
+				return err
+			}
+			if d.scoped && m.AccountId != d.accountId {
+				continue
+			}
+			if match(m) {
+				result = append(result, m)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gohook/gohook-server/gohookd"
+	"github.com/gohook/gohook-server/user"
+	bolt "go.etcd.io/bbolt"
+)
+
+var hooksBucket = []byte("hooks")
+
+type BoltHookStore struct {
+	db        *bolt.DB
+	accountId user.AccountId
+	scoped    bool
+}
+
+func NewBoltHookStore(db *bolt.DB) (gohookd.HookStore, error) {
+	if err := createBuckets(db, hooksBucket); err != nil {
+		return nil, err
+	}
+	return &BoltHookStore{
+		db:     db,
+		scoped: false,
+	}, nil
+}
+
+func (d BoltHookStore) Scope(accountId user.AccountId) gohookd.HookStore {
+	d.accountId = accountId
+	d.scoped = true
+	return &d
+}
+
+func (d *BoltHookStore) Add(m *gohookd.Hook) error {
+	if d.scoped {
+		m.AccountId = d.accountId
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(hooksBucket).Put([]byte(m.Id), data)
+	})
+}
+
+func (d *BoltHookStore) Find(id gohookd.HookID) (*gohookd.Hook, error) {
+	var result *gohookd.Hook
+	err := d.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(hooksBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		result = &gohookd.Hook{}
+		return json.Unmarshal(data, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || (d.scoped && result.AccountId != d.accountId) {
+		return nil, errors.New("Not Found")
+	}
+	return result, nil
+}
+
+func (d *BoltHookStore) FindAll() (gohookd.HookList, error) {
+	result := gohookd.HookList{}
+	err := d.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(hooksBucket).ForEach(func(k, v []byte) error {
+			hook := &gohookd.Hook{}
+			if err := json.Unmarshal(v, hook); err != nil {
+				return err
+			}
+			if !d.scoped || hook.AccountId == d.accountId {
+				result = append(result, hook)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return gohookd.HookList{}, err
+	}
+	return result, nil
+}
+
+func (d *BoltHookStore) Remove(id gohookd.HookID) (*gohookd.Hook, error) {
+	hook, err := d.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	err = d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(hooksBucket).Delete([]byte(hook.Id))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
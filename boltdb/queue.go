@@ -0,0 +1,242 @@
+package boltdb
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// queued messages, keyed by insertion order
+	queueBucket = []byte("queue")
+	// delayed hook calls, keyed by when they are due and insertion
+	// order
+	delayedBucket = []byte("delayed")
+)
+
+// number of messages read from the bucket at once
+const queueBatch = 100
+
+// BoltQueue implements the HookQueue on top of the database file.
+// Messages stay in the file until they are acked, so the messages a
+// process did not handle before it stopped are delivered once it is
+// back. Like the in memory queue it only delivers within the process,
+// and it has a single listener.
+type BoltQueue struct {
+	db *bolt.DB
+
+	// signals the listener that messages were added
+	wake chan struct{}
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func NewBoltQueue(db *bolt.DB) (tunnel.HookQueue, error) {
+	if err := createBuckets(db, queueBucket, delayedBucket); err != nil {
+		return nil, err
+	}
+	return &BoltQueue{
+		db:     db,
+		wake:   make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}, nil
+}
+
+func (q *BoltQueue) Broadcast(m *tunnel.QueueMessage) error {
+	if q.isClosed() {
+		return tunnel.ErrQueueClosed
+	}
+	data, err := tunnel.EncodeQueueMessage(m)
+	if err != nil {
+		return err
+	}
+	err = q.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(queueBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(itob(seq), data)
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Delay keeps the message in the file until its call is due.
+func (q *BoltQueue) Delay(m *tunnel.QueueMessage) error {
+	if q.isClosed() {
+		return tunnel.ErrQueueClosed
+	}
+	data, err := tunnel.EncodeQueueMessage(m)
+	if err != nil {
+		return err
+	}
+	return q.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(delayedBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(delayedKey(m.Hook.NotBefore, seq), data)
+	})
+}
+
+// Due hands out the delayed messages due by now, the earliest first.
+// They are moved to the end of their lease until they are acked.
+func (q *BoltQueue) Due(now time.Time, max int) ([]*tunnel.QueueMessage, error) {
+	if q.isClosed() {
+		return nil, tunnel.ErrQueueClosed
+	}
+	msgs := []*tunnel.QueueMessage{}
+	err := q.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(delayedBucket)
+		end := delayedKey(now, ^uint64(0))
+		leased := map[string][]byte{}
+
+		c := b.Cursor()
+		for k, v := c.First(); k != nil && bytes.Compare(k, end) <= 0 && len(msgs) < max; k, v = c.Next() {
+			msg, err := tunnel.DecodeQueueMessage(v)
+			if err != nil {
+				// left in place for a newer release to read
+				fmt.Printf("[bolt] Failed to decode delayed message %x. %v\n", k, err)
+				continue
+			}
+			key := delayedKey(now.Add(tunnel.DelayLease), btoi(k[8:]))
+			msg.Ack = func() error {
+				return q.db.Update(func(tx *bolt.Tx) error {
+					return tx.Bucket(delayedBucket).Delete(key)
+				})
+			}
+			msgs = append(msgs, msg)
+			leased[string(k)] = key
+		}
+
+		// moved once the cursor is done with the bucket
+		for k, key := range leased {
+			v := append([]byte(nil), b.Get([]byte(k))...)
+			if err := b.Put(key, v); err != nil {
+				return err
+			}
+			if err := b.Delete([]byte(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// delayedKey orders delayed messages by when they are due.
+func delayedKey(due time.Time, seq uint64) []byte {
+	return append(itob(uint64(due.UnixNano())), itob(seq)...)
+}
+
+// Listen delivers the messages left in the file first. Every message
+// has to be acked with its Ack func once it has been handled.
+func (q *BoltQueue) Listen() (tunnel.ReceiveC, error) {
+	c := make(tunnel.ReceiveC)
+
+	go func(c tunnel.ReceiveC) {
+		defer close(c)
+
+		var after uint64
+		for {
+			msgs, last, err := q.pending(after)
+			if err != nil {
+				fmt.Printf("[bolt] Error reading queue. Closing channel. %v\n", err)
+				return
+			}
+			for _, msg := range msgs {
+				select {
+				case c <- msg:
+				case <-q.closed:
+					return
+				}
+			}
+			after = last
+			if len(msgs) == queueBatch {
+				continue
+			}
+
+			select {
+			case <-q.wake:
+			case <-q.closed:
+				return
+			}
+		}
+	}(c)
+
+	return c, nil
+}
+
+// pending reads the messages after the given key. It returns the key
+// of the last message read.
+func (q *BoltQueue) pending(after uint64) ([]*tunnel.QueueMessage, uint64, error) {
+	msgs := []*tunnel.QueueMessage{}
+	last := after
+	err := q.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(queueBucket).Cursor()
+		for k, v := c.Seek(itob(after + 1)); k != nil && len(msgs) < queueBatch; k, v = c.Next() {
+			last = btoi(k)
+			msg, err := tunnel.DecodeQueueMessage(v)
+			if err != nil {
+				// left in place for a newer release to read
+				fmt.Printf("[bolt] Failed to decode message %d. %v\n", last, err)
+				continue
+			}
+			key := last
+			msg.Ack = func() error {
+				return q.ack(key)
+			}
+			msgs = append(msgs, msg)
+		}
+		return nil
+	})
+	return msgs, last, err
+}
+
+func (q *BoltQueue) ack(key uint64) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(queueBucket).Delete(itob(key))
+	})
+}
+
+// Health reports ErrQueueClosed once the queue has been closed.
+func (q *BoltQueue) Health() error {
+	if q.isClosed() {
+		return tunnel.ErrQueueClosed
+	}
+	return nil
+}
+
+// Close stops the listener. The database is left open.
+func (q *BoltQueue) Close() error {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+	})
+	return nil
+}
+
+func (q *BoltQueue) isClosed() bool {
+	select {
+	case <-q.closed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,235 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// holds a bucket per account, keyed by sequence number. The
+	// sequence of the account bucket is the last sequence number
+	// handed out, and survives the entries.
+	backlogsBucket = []byte("backlogs")
+	// account id -> acked sequence number
+	ackedBucket = []byte("acked")
+	// resume token -> resumeToken
+	resumeTokensBucket = []byte("resume_tokens")
+)
+
+type backlogEntry struct {
+	Time time.Time
+	Hook tunnel.HookCall
+}
+
+type resumeToken struct {
+	State   tunnel.ResumeState
+	Expires time.Time
+}
+
+// BoltResumeStore keeps the backlogs on disk, so calls waiting for a
+// client are kept over restarts.
+type BoltResumeStore struct {
+	db *bolt.DB
+}
+
+func NewBoltResumeStore(db *bolt.DB) (tunnel.ResumeStore, error) {
+	if err := createBuckets(db, backlogsBucket, ackedBucket, resumeTokensBucket); err != nil {
+		return nil, err
+	}
+	return &BoltResumeStore{
+		db: db,
+	}, nil
+}
+
+func (r *BoltResumeStore) Append(accountId user.AccountId, call tunnel.HookCall) (int64, error) {
+	var seq uint64
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(backlogsBucket).CreateBucketIfNotExists([]byte(accountId))
+		if err != nil {
+			return err
+		}
+		if seq, err = b.NextSequence(); err != nil {
+			return err
+		}
+		call.Seq = int64(seq)
+		data, err := json.Marshal(backlogEntry{Time: time.Now(), Hook: call})
+		if err != nil {
+			return err
+		}
+		if err := b.Put(itob(seq), data); err != nil {
+			return err
+		}
+
+		// keep the last ResumeBacklog calls
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && btoi(k)+tunnel.ResumeBacklog <= seq; k, _ = c.First() {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(seq), nil
+}
+
+func (r *BoltResumeStore) Since(accountId user.AccountId, seq int64) ([]tunnel.HookCall, bool, error) {
+	calls := []tunnel.HookCall{}
+	var current int64
+	err := r.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(backlogsBucket).Bucket([]byte(accountId))
+		if b == nil {
+			return nil
+		}
+		current = int64(b.Sequence())
+
+		c := b.Cursor()
+		for k, v := c.Seek(itob(uint64(seq + 1))); k != nil; k, v = c.Next() {
+			entry := backlogEntry{}
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			if time.Since(entry.Time) > tunnel.ResumeRetention {
+				continue
+			}
+			calls = append(calls, entry.Hook)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(calls) > 0 {
+		return calls, calls[0].Seq == seq+1, nil
+	}
+	return calls, current <= seq, nil
+}
+
+func (r *BoltResumeStore) NewToken(state tunnel.ResumeState) (tunnel.ResumeToken, error) {
+	token := tunnel.ResumeToken(uuid.NewV4().String())
+	data, err := json.Marshal(resumeToken{
+		State:   state,
+		Expires: time.Now().Add(tunnel.ResumeRetention),
+	})
+	if err != nil {
+		return "", err
+	}
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(resumeTokensBucket)
+		if err := expireTokens(b); err != nil {
+			return err
+		}
+		return b.Put([]byte(token), data)
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *BoltResumeStore) FindToken(token tunnel.ResumeToken) (*tunnel.ResumeState, error) {
+	var t *resumeToken
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		t, err = getResumeToken(tx.Bucket(resumeTokensBucket), token)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || time.Now().After(t.Expires) {
+		return nil, tunnel.ErrResumeExpired
+	}
+	return &t.State, nil
+}
+
+func (r *BoltResumeStore) Touch(token tunnel.ResumeToken) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(resumeTokensBucket)
+		t, err := getResumeToken(b, token)
+		if err != nil || t == nil {
+			return err
+		}
+		t.Expires = time.Now().Add(tunnel.ResumeRetention)
+		data, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(token), data)
+	})
+}
+
+func (r *BoltResumeStore) Ack(accountId user.AccountId, seq int64) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(ackedBucket)
+		// only move the cursor forward, like the redis store
+		if acked := b.Get([]byte(accountId)); acked != nil && int64(btoi(acked)) >= seq {
+			return nil
+		}
+		return b.Put([]byte(accountId), itob(uint64(seq)))
+	})
+}
+
+func (r *BoltResumeStore) Current(accountId user.AccountId) (int64, error) {
+	var current int64
+	err := r.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(backlogsBucket).Bucket([]byte(accountId)); b != nil {
+			current = int64(b.Sequence())
+		}
+		return nil
+	})
+	return current, err
+}
+
+func (r *BoltResumeStore) Acked(accountId user.AccountId) (int64, error) {
+	var acked int64
+	err := r.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(ackedBucket).Get([]byte(accountId)); v != nil {
+			acked = int64(btoi(v))
+		}
+		return nil
+	})
+	return acked, err
+}
+
+func getResumeToken(b *bolt.Bucket, token tunnel.ResumeToken) (*resumeToken, error) {
+	data := b.Get([]byte(token))
+	if data == nil {
+		return nil, nil
+	}
+	t := &resumeToken{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// expireTokens drops expired tokens.
+func expireTokens(b *bolt.Bucket) error {
+	now := time.Now()
+	expired := [][]byte{}
+	err := b.ForEach(func(k, v []byte) error {
+		t := resumeToken{}
+		if err := json.Unmarshal(v, &t); err != nil || now.After(t.Expires) {
+			expired = append(expired, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range expired {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -7,8 +7,11 @@ import (
 	"github.com/sony/gobreaker"
 	"google.golang.org/grpc"
 
+	"github.com/gohook/gohook-server/audit"
+	"github.com/gohook/gohook-server/deadletter"
 	"github.com/gohook/gohook-server/gohookd"
 	"github.com/gohook/gohook-server/pb"
+	"github.com/gohook/gohook-server/tunnel"
 
 	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
@@ -19,10 +22,47 @@ import (
 type GohookClient struct {
 	pbClient pb.GohookClient
 	gohookd.Service
+	audit    audit.Service
+	sessions tunnel.Service
+	letters  deadletter.Service
 }
 
-func (c *GohookClient) Tunnel(ctx context.Context, req *pb.TunnelRequest, opts ...grpc.CallOption) (pb.Gohook_TunnelClient, error) {
-	return c.pbClient.Tunnel(ctx, req, opts...)
+func (c *GohookClient) AuditLog(ctx context.Context) (audit.EntryList, error) {
+	return c.audit.AuditLog(ctx)
+}
+
+// Tunnel opens the tunnel stream. The caller has to send a TunnelOpen
+// message first and answer every Ping with a Pong.
+func (c *GohookClient) ListSessions(ctx context.Context) (tunnel.SessionInfoList, error) {
+	return c.sessions.ListSessions(ctx)
+}
+
+func (c *GohookClient) KickSession(ctx context.Context, id tunnel.SessionId) error {
+	return c.sessions.KickSession(ctx, id)
+}
+
+func (c *GohookClient) Presence(ctx context.Context) (*tunnel.PresenceInfo, error) {
+	return c.sessions.Presence(ctx)
+}
+
+func (c *GohookClient) ListDeadLetters(ctx context.Context) (tunnel.DeadLetterList, error) {
+	return c.letters.ListDeadLetters(ctx)
+}
+
+func (c *GohookClient) GetDeadLetter(ctx context.Context, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	return c.letters.GetDeadLetter(ctx, id)
+}
+
+func (c *GohookClient) RequeueDeadLetter(ctx context.Context, id tunnel.DeadLetterId) error {
+	return c.letters.RequeueDeadLetter(ctx, id)
+}
+
+func (c *GohookClient) PurgeDeadLetters(ctx context.Context, ids []tunnel.DeadLetterId) (int, error) {
+	return c.letters.PurgeDeadLetters(ctx, ids)
+}
+
+func (c *GohookClient) Tunnel(ctx context.Context, opts ...grpc.CallOption) (pb.Gohook_TunnelClient, error) {
+	return c.pbClient.Tunnel(ctx, opts...)
 }
 
 func New(conn *grpc.ClientConn, logger log.Logger) GohookClient {
@@ -75,6 +115,134 @@ func New(conn *grpc.ClientConn, logger log.Logger) GohookClient {
 		}))(deleteEndpoint)
 	}
 
+	var auditLogEndpoint endpoint.Endpoint
+	{
+		auditLogEndpoint = grpctransport.NewClient(
+			conn,
+			"Gohook",
+			"AuditLog",
+			audit.EncodeGRPCAuditLogRequest,
+			audit.DecodeGRPCAuditLogResponse,
+			pb.AuditLogResponse{},
+		).Endpoint()
+		auditLogEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "AuditLog",
+			Timeout: 30 * time.Second,
+		}))(auditLogEndpoint)
+	}
+
+	var listSessionsEndpoint endpoint.Endpoint
+	{
+		listSessionsEndpoint = grpctransport.NewClient(
+			conn,
+			"Gohook",
+			"ListSessions",
+			tunnel.EncodeGRPCListSessionsRequest,
+			tunnel.DecodeGRPCListSessionsResponse,
+			pb.ListSessionsResponse{},
+		).Endpoint()
+		listSessionsEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "ListSessions",
+			Timeout: 30 * time.Second,
+		}))(listSessionsEndpoint)
+	}
+
+	var kickSessionEndpoint endpoint.Endpoint
+	{
+		kickSessionEndpoint = grpctransport.NewClient(
+			conn,
+			"Gohook",
+			"KickSession",
+			tunnel.EncodeGRPCKickSessionRequest,
+			tunnel.DecodeGRPCKickSessionResponse,
+			pb.KickSessionResponse{},
+		).Endpoint()
+		kickSessionEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "KickSession",
+			Timeout: 30 * time.Second,
+		}))(kickSessionEndpoint)
+	}
+
+	var presenceEndpoint endpoint.Endpoint
+	{
+		presenceEndpoint = grpctransport.NewClient(
+			conn,
+			"Gohook",
+			"Presence",
+			tunnel.EncodeGRPCPresenceRequest,
+			tunnel.DecodeGRPCPresenceResponse,
+			pb.PresenceResponse{},
+		).Endpoint()
+		presenceEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "Presence",
+			Timeout: 30 * time.Second,
+		}))(presenceEndpoint)
+	}
+
+	var listDeadLettersEndpoint endpoint.Endpoint
+	{
+		listDeadLettersEndpoint = grpctransport.NewClient(
+			conn,
+			"Gohook",
+			"ListDeadLetters",
+			deadletter.EncodeGRPCListDeadLettersRequest,
+			deadletter.DecodeGRPCListDeadLettersResponse,
+			pb.ListDeadLettersResponse{},
+		).Endpoint()
+		listDeadLettersEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "ListDeadLetters",
+			Timeout: 30 * time.Second,
+		}))(listDeadLettersEndpoint)
+	}
+
+	var getDeadLetterEndpoint endpoint.Endpoint
+	{
+		getDeadLetterEndpoint = grpctransport.NewClient(
+			conn,
+			"Gohook",
+			"GetDeadLetter",
+			deadletter.EncodeGRPCGetDeadLetterRequest,
+			deadletter.DecodeGRPCGetDeadLetterResponse,
+			pb.GetDeadLetterResponse{},
+		).Endpoint()
+		getDeadLetterEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "GetDeadLetter",
+			Timeout: 30 * time.Second,
+		}))(getDeadLetterEndpoint)
+	}
+
+	var requeueDeadLetterEndpoint endpoint.Endpoint
+	{
+		requeueDeadLetterEndpoint = grpctransport.NewClient(
+			conn,
+			"Gohook",
+			"RequeueDeadLetter",
+			deadletter.EncodeGRPCRequeueDeadLetterRequest,
+			deadletter.DecodeGRPCRequeueDeadLetterResponse,
+			pb.RequeueDeadLetterResponse{},
+		).Endpoint()
+		requeueDeadLetterEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "RequeueDeadLetter",
+			Timeout: 30 * time.Second,
+		}))(requeueDeadLetterEndpoint)
+	}
+
+	var purgeDeadLettersEndpoint endpoint.Endpoint
+	{
+		purgeDeadLettersEndpoint = grpctransport.NewClient(
+			conn,
+			"Gohook",
+			"PurgeDeadLetters",
+			deadletter.EncodeGRPCPurgeDeadLettersRequest,
+			deadletter.DecodeGRPCPurgeDeadLettersResponse,
+			pb.PurgeDeadLettersResponse{},
+		).Endpoint()
+		purgeDeadLettersEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:    "PurgeDeadLetters",
+			Timeout: 30 * time.Second,
+		}))(purgeDeadLettersEndpoint)
+	}
+
 	return GohookClient{
 		pbClient: pb.NewGohookClient(conn),
 		Service: gohookd.Endpoints{
@@ -82,5 +250,19 @@ func New(conn *grpc.ClientConn, logger log.Logger) GohookClient {
 			CreateEndpoint: createEndpoint,
 			DeleteEndpoint: deleteEndpoint,
 		},
+		audit: audit.Endpoints{
+			AuditLogEndpoint: auditLogEndpoint,
+		},
+		sessions: tunnel.Endpoints{
+			ListSessionsEndpoint: listSessionsEndpoint,
+			KickSessionEndpoint:  kickSessionEndpoint,
+			PresenceEndpoint:     presenceEndpoint,
+		},
+		letters: deadletter.Endpoints{
+			ListDeadLettersEndpoint:   listDeadLettersEndpoint,
+			GetDeadLetterEndpoint:     getDeadLetterEndpoint,
+			RequeueDeadLetterEndpoint: requeueDeadLetterEndpoint,
+			PurgeDeadLettersEndpoint:  purgeDeadLettersEndpoint,
+		},
 	}
 }
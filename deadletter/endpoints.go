@@ -0,0 +1,101 @@
+package deadletter
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"github.com/gohook/gohook-server/tunnel"
+	"golang.org/x/net/context"
+)
+
+type Endpoints struct {
+	ListDeadLettersEndpoint   endpoint.Endpoint
+	GetDeadLetterEndpoint     endpoint.Endpoint
+	RequeueDeadLetterEndpoint endpoint.Endpoint
+	PurgeDeadLettersEndpoint  endpoint.Endpoint
+}
+
+// ListDeadLetters Endpoint
+type listDeadLettersRequest struct{}
+
+func (e Endpoints) ListDeadLetters(ctx context.Context) (tunnel.DeadLetterList, error) {
+	response, err := e.ListDeadLettersEndpoint(ctx, listDeadLettersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return response.(tunnel.DeadLetterList), nil
+}
+
+func MakeListDeadLettersEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (response interface{}, err error) {
+		letters, err := s.ListDeadLetters(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return letters, nil
+	}
+}
+
+// GetDeadLetter Endpoint
+func (e Endpoints) GetDeadLetter(ctx context.Context, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	response, err := e.GetDeadLetterEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return response.(*tunnel.DeadLetter), nil
+}
+
+func MakeGetDeadLetterEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		id := request.(tunnel.DeadLetterId)
+		letter, err := s.GetDeadLetter(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return letter, nil
+	}
+}
+
+// RequeueDeadLetter Endpoint
+type requeueDeadLetterResponse struct{}
+
+func (e Endpoints) RequeueDeadLetter(ctx context.Context, id tunnel.DeadLetterId) error {
+	_, err := e.RequeueDeadLetterEndpoint(ctx, id)
+	return err
+}
+
+func MakeRequeueDeadLetterEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		id := request.(tunnel.DeadLetterId)
+		if err := s.RequeueDeadLetter(ctx, id); err != nil {
+			return nil, err
+		}
+		return requeueDeadLetterResponse{}, nil
+	}
+}
+
+// PurgeDeadLetters Endpoint
+type purgeDeadLettersRequest struct {
+	Ids []tunnel.DeadLetterId
+}
+
+type purgeDeadLettersResponse struct {
+	Purged int
+}
+
+func (e Endpoints) PurgeDeadLetters(ctx context.Context, ids []tunnel.DeadLetterId) (int, error) {
+	response, err := e.PurgeDeadLettersEndpoint(ctx, purgeDeadLettersRequest{Ids: ids})
+	if err != nil {
+		return 0, err
+	}
+	return response.(purgeDeadLettersResponse).Purged, nil
+}
+
+func MakePurgeDeadLettersEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(purgeDeadLettersRequest)
+		purged, err := s.PurgeDeadLetters(ctx, req.Ids)
+		if err != nil {
+			return nil, err
+		}
+		return purgeDeadLettersResponse{Purged: purged}, nil
+	}
+}
@@ -0,0 +1,77 @@
+package deadletter
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/tunnel"
+	"golang.org/x/net/context"
+)
+
+type Middleware func(Service) Service
+
+func ServiceLoggingMiddleware(logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return serviceLoggingMiddleware{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+type serviceLoggingMiddleware struct {
+	logger log.Logger
+	next   Service
+}
+
+func (mw serviceLoggingMiddleware) ListDeadLetters(ctx context.Context) (v tunnel.DeadLetterList, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "ListDeadLetters",
+			"layer", "service",
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.ListDeadLetters(ctx)
+}
+
+func (mw serviceLoggingMiddleware) GetDeadLetter(ctx context.Context, id tunnel.DeadLetterId) (v *tunnel.DeadLetter, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "GetDeadLetter",
+			"layer", "service",
+			"deadLetterId", id,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.GetDeadLetter(ctx, id)
+}
+
+func (mw serviceLoggingMiddleware) RequeueDeadLetter(ctx context.Context, id tunnel.DeadLetterId) (err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "RequeueDeadLetter",
+			"layer", "service",
+			"deadLetterId", id,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.RequeueDeadLetter(ctx, id)
+}
+
+func (mw serviceLoggingMiddleware) PurgeDeadLetters(ctx context.Context, ids []tunnel.DeadLetterId) (v int, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "PurgeDeadLetters",
+			"layer", "service",
+			"ids", len(ids),
+			"purged", v,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.PurgeDeadLetters(ctx, ids)
+}
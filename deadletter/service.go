@@ -0,0 +1,94 @@
+package deadletter
+
+import (
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+/*
+Dead Letter Service
+-------------------
+
+The dead letter service lets a client see the hook calls of its
+account that could not be delivered, and have them delivered
+again. Requeued calls are broadcast on the queue like new calls,
+so they get a new sequence number.
+*/
+
+type Service interface {
+	ListDeadLetters(ctx context.Context) (tunnel.DeadLetterList, error)
+	GetDeadLetter(ctx context.Context, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error)
+	RequeueDeadLetter(ctx context.Context, id tunnel.DeadLetterId) error
+	// PurgeDeadLetters removes the given letters, or every letter of
+	// the account when no ids are given.
+	PurgeDeadLetters(ctx context.Context, ids []tunnel.DeadLetterId) (int, error)
+}
+
+func NewBasicService(store tunnel.DeadLetterStore, queue tunnel.HookQueue) Service {
+	return &basicService{
+		store: store,
+		queue: queue,
+	}
+}
+
+type basicService struct {
+	store tunnel.DeadLetterStore
+	queue tunnel.HookQueue
+}
+
+func (s basicService) ListDeadLetters(ctx context.Context) (tunnel.DeadLetterList, error) {
+	account := ctx.Value("account").(*user.Account)
+	return s.store.FindByAccount(account.Id)
+}
+
+func (s basicService) GetDeadLetter(ctx context.Context, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	account := ctx.Value("account").(*user.Account)
+	return s.store.Find(account.Id, id)
+}
+
+func (s basicService) RequeueDeadLetter(ctx context.Context, id tunnel.DeadLetterId) error {
+	account := ctx.Value("account").(*user.Account)
+	letter, err := s.store.Find(account.Id, id)
+	if err != nil {
+		return err
+	}
+
+	// requeued calls are delivered right away and do not expire
+	call := letter.Call
+	call.Seq = 0
+	call.Expires, call.NotBefore = time.Time{}, time.Time{}
+	err = tunnel.BroadcastContext(ctx, s.queue, &tunnel.QueueMessage{
+		Type:      tunnel.MessageHook,
+		AccountId: account.Id,
+		Hook:      call,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.Remove(account.Id, id)
+	return err
+}
+
+func (s basicService) PurgeDeadLetters(ctx context.Context, ids []tunnel.DeadLetterId) (int, error) {
+	account := ctx.Value("account").(*user.Account)
+	if len(ids) == 0 {
+		return s.store.Purge(account.Id)
+	}
+
+	purged := 0
+	for _, id := range ids {
+		_, err := s.store.Remove(account.Id, id)
+		if err == tunnel.ErrDeadLetterNotFound {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,247 @@
+package deadletter
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"github.com/gohook/gohook-server/pb"
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+type DeadLetterServer struct {
+	listDeadLetters   grpctransport.Handler
+	getDeadLetter     grpctransport.Handler
+	requeueDeadLetter grpctransport.Handler
+	purgeDeadLetters  grpctransport.Handler
+}
+
+func extractAuthToken(ctx context.Context, md *metadata.MD) context.Context {
+	if token, ok := (*md)["token"]; ok && len(token) > 0 {
+		return context.WithValue(ctx, "token", token[0])
+	}
+	return ctx
+}
+
+func MakeDeadLetterServer(ctx context.Context, endpoints Endpoints, logger log.Logger) *DeadLetterServer {
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorLogger(logger),
+		grpctransport.ServerBefore(extractAuthToken),
+	}
+	return &DeadLetterServer{
+		listDeadLetters: grpctransport.NewServer(
+			ctx,
+			endpoints.ListDeadLettersEndpoint,
+			DecodeGRPCListDeadLettersRequest,
+			EncodeGRPCListDeadLettersResponse,
+			options...,
+		),
+		getDeadLetter: grpctransport.NewServer(
+			ctx,
+			endpoints.GetDeadLetterEndpoint,
+			DecodeGRPCGetDeadLetterRequest,
+			EncodeGRPCGetDeadLetterResponse,
+			options...,
+		),
+		requeueDeadLetter: grpctransport.NewServer(
+			ctx,
+			endpoints.RequeueDeadLetterEndpoint,
+			DecodeGRPCRequeueDeadLetterRequest,
+			EncodeGRPCRequeueDeadLetterResponse,
+			options...,
+		),
+		purgeDeadLetters: grpctransport.NewServer(
+			ctx,
+			endpoints.PurgeDeadLettersEndpoint,
+			DecodeGRPCPurgeDeadLettersRequest,
+			EncodeGRPCPurgeDeadLettersResponse,
+			options...,
+		),
+	}
+}
+
+// grpcError maps service errors to their gRPC status codes.
+func grpcError(err error) error {
+	switch err {
+	case user.ErrAccountSuspended:
+		return grpc.Errorf(codes.PermissionDenied, "%v", err)
+	case tunnel.ErrDeadLetterNotFound:
+		return grpc.Errorf(codes.NotFound, "%v", err)
+	}
+	return err
+}
+
+// ListDeadLetters transport handler
+func (s *DeadLetterServer) ListDeadLetters(ctx context.Context, req *pb.ListDeadLettersRequest) (*pb.ListDeadLettersResponse, error) {
+	_, rep, err := s.listDeadLetters.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return rep.(*pb.ListDeadLettersResponse), nil
+}
+
+// GetDeadLetter transport handler
+func (s *DeadLetterServer) GetDeadLetter(ctx context.Context, req *pb.GetDeadLetterRequest) (*pb.GetDeadLetterResponse, error) {
+	_, rep, err := s.getDeadLetter.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return rep.(*pb.GetDeadLetterResponse), nil
+}
+
+// RequeueDeadLetter transport handler
+func (s *DeadLetterServer) RequeueDeadLetter(ctx context.Context, req *pb.RequeueDeadLetterRequest) (*pb.RequeueDeadLetterResponse, error) {
+	_, rep, err := s.requeueDeadLetter.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return rep.(*pb.RequeueDeadLetterResponse), nil
+}
+
+// PurgeDeadLetters transport handler
+func (s *DeadLetterServer) PurgeDeadLetters(ctx context.Context, req *pb.PurgeDeadLettersRequest) (*pb.PurgeDeadLettersResponse, error) {
+	_, rep, err := s.purgeDeadLetters.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return rep.(*pb.PurgeDeadLettersResponse), nil
+}
+
+// encodeDeadLetter converts the letter to its wire format. Bodies are
+// only sent when the letter is inspected.
+func encodeDeadLetter(l *tunnel.DeadLetter, body bool) *pb.DeadLetter {
+	letter := &pb.DeadLetter{
+		Id:       string(l.Id),
+		HookId:   l.Call.Id,
+		Method:   l.Call.Method,
+		Labels:   l.Call.Labels,
+		Seq:      l.Call.Seq,
+		Reason:   l.Reason,
+		Attempts: int32(l.Attempts),
+		Time:     l.Time.UnixNano(),
+	}
+	if body {
+		letter.Body = l.Call.Body
+	}
+	return letter
+}
+
+func decodeDeadLetter(l *pb.DeadLetter) *tunnel.DeadLetter {
+	return &tunnel.DeadLetter{
+		Id: tunnel.DeadLetterId(l.Id),
+		Call: tunnel.HookCall{
+			Seq:    l.Seq,
+			Id:     l.HookId,
+			Method: l.Method,
+			Body:   l.Body,
+			Labels: l.GetLabels(),
+		},
+		Reason:   l.Reason,
+		Attempts: int(l.Attempts),
+		Time:     time.Unix(0, l.Time),
+	}
+}
+
+// ListDeadLetters transforms
+func EncodeGRPCListDeadLettersRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.ListDeadLettersRequest{}, nil
+}
+
+func DecodeGRPCListDeadLettersRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return listDeadLettersRequest{}, nil
+}
+
+func EncodeGRPCListDeadLettersResponse(_ context.Context, response interface{}) (interface{}, error) {
+	letters := response.(tunnel.DeadLetterList)
+	pbLetters := []*pb.DeadLetter{}
+	for _, l := range letters {
+		pbLetters = append(pbLetters, encodeDeadLetter(l, false))
+	}
+	return &pb.ListDeadLettersResponse{DeadLetters: pbLetters}, nil
+}
+
+func DecodeGRPCListDeadLettersResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	resp := grpcReply.(*pb.ListDeadLettersResponse)
+	letters := tunnel.DeadLetterList{}
+	for _, l := range resp.DeadLetters {
+		letters = append(letters, decodeDeadLetter(l))
+	}
+	return letters, nil
+}
+
+// GetDeadLetter transforms
+func EncodeGRPCGetDeadLetterRequest(_ context.Context, request interface{}) (interface{}, error) {
+	id := request.(tunnel.DeadLetterId)
+	return &pb.GetDeadLetterRequest{Id: string(id)}, nil
+}
+
+func DecodeGRPCGetDeadLetterRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.GetDeadLetterRequest)
+	return tunnel.DeadLetterId(req.Id), nil
+}
+
+func EncodeGRPCGetDeadLetterResponse(_ context.Context, response interface{}) (interface{}, error) {
+	letter := response.(*tunnel.DeadLetter)
+	return &pb.GetDeadLetterResponse{DeadLetter: encodeDeadLetter(letter, true)}, nil
+}
+
+func DecodeGRPCGetDeadLetterResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	resp := grpcReply.(*pb.GetDeadLetterResponse)
+	if resp.DeadLetter == nil {
+		return nil, tunnel.ErrDeadLetterNotFound
+	}
+	return decodeDeadLetter(resp.DeadLetter), nil
+}
+
+// RequeueDeadLetter transforms
+func EncodeGRPCRequeueDeadLetterRequest(_ context.Context, request interface{}) (interface{}, error) {
+	id := request.(tunnel.DeadLetterId)
+	return &pb.RequeueDeadLetterRequest{Id: string(id)}, nil
+}
+
+func DecodeGRPCRequeueDeadLetterRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.RequeueDeadLetterRequest)
+	return tunnel.DeadLetterId(req.Id), nil
+}
+
+func EncodeGRPCRequeueDeadLetterResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.RequeueDeadLetterResponse{}, nil
+}
+
+func DecodeGRPCRequeueDeadLetterResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return requeueDeadLetterResponse{}, nil
+}
+
+// PurgeDeadLetters transforms
+func EncodeGRPCPurgeDeadLettersRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(purgeDeadLettersRequest)
+	ids := []string{}
+	for _, id := range req.Ids {
+		ids = append(ids, string(id))
+	}
+	return &pb.PurgeDeadLettersRequest{Ids: ids}, nil
+}
+
+func DecodeGRPCPurgeDeadLettersRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.PurgeDeadLettersRequest)
+	ids := []tunnel.DeadLetterId{}
+	for _, id := range req.Ids {
+		ids = append(ids, tunnel.DeadLetterId(id))
+	}
+	return purgeDeadLettersRequest{Ids: ids}, nil
+}
+
+func EncodeGRPCPurgeDeadLettersResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(purgeDeadLettersResponse)
+	return &pb.PurgeDeadLettersResponse{Purged: int32(resp.Purged)}, nil
+}
+
+func DecodeGRPCPurgeDeadLettersResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	resp := grpcReply.(*pb.PurgeDeadLettersResponse)
+	return purgeDeadLettersResponse{Purged: int(resp.Purged)}, nil
+}
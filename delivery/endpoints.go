@@ -0,0 +1,66 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/net/context"
+)
+
+type Endpoints struct {
+	PollEndpoint endpoint.Endpoint
+	AckEndpoint  endpoint.Endpoint
+}
+
+// Poll Endpoint
+type PollRequest struct {
+	Wait  time.Duration
+	Limit int
+}
+
+func (e Endpoints) Poll(ctx context.Context, wait time.Duration, limit int) (*Deliveries, error) {
+	response, err := e.PollEndpoint(ctx, PollRequest{Wait: wait, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return response.(*Deliveries), nil
+}
+
+func MakePollEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(PollRequest)
+		deliveries, err := s.Poll(ctx, req.Wait, req.Limit)
+		if err != nil {
+			return nil, err
+		}
+		return deliveries, nil
+	}
+}
+
+// Ack Endpoint
+type AckRequest struct {
+	Seq int64 `json:"seq"`
+}
+
+type AckResponse struct {
+	Acked int64 `json:"acked"`
+}
+
+func (e Endpoints) Ack(ctx context.Context, seq int64) (int64, error) {
+	response, err := e.AckEndpoint(ctx, AckRequest{Seq: seq})
+	if err != nil {
+		return 0, err
+	}
+	return response.(AckResponse).Acked, nil
+}
+
+func MakeAckEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(AckRequest)
+		acked, err := s.Ack(ctx, req.Seq)
+		if err != nil {
+			return nil, err
+		}
+		return AckResponse{Acked: acked}, nil
+	}
+}
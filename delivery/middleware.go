@@ -0,0 +1,94 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+type Middleware func(Service) Service
+
+// EndpointAuthMiddleware puts the account of the token into the
+// context. Unknown tokens are rejected with ErrUnauthorized.
+func EndpointAuthMiddleware(logger log.Logger, auth user.AuthService) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			token, _ := ctx.Value("token").(string)
+			if token == "" {
+				return nil, ErrUnauthorized
+			}
+			account, err := auth.AuthAccountFromToken(token)
+			if err != nil {
+				if err == user.ErrAccountSuspended {
+					return nil, err
+				}
+				logger.Log("msg", "Rejected delivery request", "err", err)
+				return nil, ErrUnauthorized
+			}
+			ctx = context.WithValue(ctx, "account", account)
+			return next(ctx, request)
+		}
+	}
+}
+
+func EndpointLoggingMiddleware(logger log.Logger) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				logger.Log("layer", "endpoint", "error", err, "took", time.Since(begin))
+			}(time.Now())
+			return next(ctx, request)
+
+		}
+	}
+}
+
+func ServiceLoggingMiddleware(logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return serviceLoggingMiddleware{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+type serviceLoggingMiddleware struct {
+	logger log.Logger
+	next   Service
+}
+
+func (mw serviceLoggingMiddleware) Poll(ctx context.Context, wait time.Duration, limit int) (v *Deliveries, err error) {
+	defer func(begin time.Time) {
+		delivered := 0
+		if v != nil {
+			delivered = len(v.Calls)
+		}
+		mw.logger.Log(
+			"method", "Poll",
+			"layer", "service",
+			"wait", wait,
+			"limit", limit,
+			"delivered", delivered,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.Poll(ctx, wait, limit)
+}
+
+func (mw serviceLoggingMiddleware) Ack(ctx context.Context, seq int64) (v int64, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "Ack",
+			"layer", "service",
+			"seq", seq,
+			"acked", v,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.Ack(ctx, seq)
+}
@@ -0,0 +1,188 @@
+package delivery
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+var (
+	ErrUnauthorized = errors.New("Unauthorized")
+	ErrInvalidWait  = errors.New("Invalid Wait")
+	ErrInvalidLimit = errors.New("Invalid Limit")
+	ErrInvalidSeq   = errors.New("Invalid Sequence Number")
+)
+
+const (
+	DefaultWait = 30 * time.Second
+	MaxWait     = time.Minute
+
+	DefaultLimit = 100
+	MaxLimit     = tunnel.ResumeBacklog
+)
+
+/*
+Delivery Service
+----------------
+
+The delivery service lets clients that can not keep a tunnel open
+poll for hook calls instead. Calls are read from the same backlog
+the tunnel resumes sessions from, after a per account cursor that
+clients move by acking the calls they handled. Calls are given out
+again until they are acked.
+
+A client switching to the tunnel opens it with the last call it
+acked as last_seq. A tunnel client switching to polling acks the
+last call it handled first.
+
+Expired calls are not handed out. They are recorded as expired once
+an ack moves the cursor past them.
+*/
+
+type Service interface {
+	Poll(ctx context.Context, wait time.Duration, limit int) (*Deliveries, error)
+	Ack(ctx context.Context, seq int64) (int64, error)
+}
+
+type Deliveries struct {
+	Calls []tunnel.HookCall `json:"deliveries"`
+	// Acked is the sequence number of the last call acked.
+	Acked int64 `json:"acked"`
+	// Complete is false when calls expired before they were acked.
+	Complete bool `json:"complete"`
+}
+
+func NewBasicService(resumes tunnel.ResumeStore, watcher tunnel.Watcher, expiries tunnel.ExpiryRecorder, logger log.Logger) Service {
+	return &basicService{
+		resumes:  resumes,
+		watcher:  watcher,
+		expiries: expiries,
+		logger:   logger,
+	}
+}
+
+type basicService struct {
+	resumes  tunnel.ResumeStore
+	watcher  tunnel.Watcher
+	expiries tunnel.ExpiryRecorder
+	logger   log.Logger
+}
+
+// Poll returns the calls after the cursor of the account. When there
+// are none, it waits up to wait for one to come in.
+func (s basicService) Poll(ctx context.Context, wait time.Duration, limit int) (*Deliveries, error) {
+	account := ctx.Value("account").(*user.Account)
+	if wait < 0 || wait > MaxWait {
+		return nil, ErrInvalidWait
+	}
+	if limit <= 0 || limit > MaxLimit {
+		return nil, ErrInvalidLimit
+	}
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	for {
+		// watch before reading the backlog so a call coming in
+		// meanwhile is not missed
+		woken, stop := s.watcher.Watch(account.Id)
+		deliveries, err := s.pending(account.Id, limit)
+		if err != nil || len(deliveries.Calls) > 0 {
+			stop()
+			return deliveries, err
+		}
+
+		select {
+		case <-woken:
+			stop()
+		case <-timeout.C:
+			stop()
+			return deliveries, nil
+		case <-ctx.Done():
+			stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s basicService) pending(accountId user.AccountId, limit int) (*Deliveries, error) {
+	acked, err := s.resumes.Acked(accountId)
+	if err != nil {
+		return nil, err
+	}
+	backlog, complete, err := s.resumes.Since(accountId, acked)
+	if err != nil {
+		return nil, err
+	}
+	// expired calls are not handed out, the cursor moves past them
+	// with the next ack, which records them
+	calls := []tunnel.HookCall{}
+	now := time.Now()
+	for _, call := range backlog {
+		if !call.Expired(now) {
+			calls = append(calls, call)
+		}
+	}
+	if len(calls) > limit {
+		calls = calls[:limit]
+	}
+	return &Deliveries{
+		Calls:    calls,
+		Acked:    acked,
+		Complete: complete,
+	}, nil
+}
+
+// Ack moves the cursor of the account to seq, and returns where the
+// cursor is. It never moves back. Sequence numbers not given out yet
+// are refused with ErrInvalidSeq.
+func (s basicService) Ack(ctx context.Context, seq int64) (int64, error) {
+	account := ctx.Value("account").(*user.Account)
+	if seq <= 0 {
+		return 0, ErrInvalidSeq
+	}
+	// the cursor never moves back, so an ack past the calls sent so
+	// far would hide every call after it
+	current, err := s.resumes.Current(account.Id)
+	if err != nil {
+		return 0, err
+	}
+	if seq > current {
+		return 0, ErrInvalidSeq
+	}
+	acked, err := s.resumes.Acked(account.Id)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.resumes.Ack(account.Id, seq); err != nil {
+		return 0, err
+	}
+	if seq > acked {
+		s.recordExpired(account.Id, acked, seq)
+	}
+	return s.resumes.Acked(account.Id)
+}
+
+// recordExpired records the expired calls the cursor moved past.
+func (s basicService) recordExpired(accountId user.AccountId, from, to int64) {
+	if s.expiries == nil {
+		return
+	}
+	backlog, _, err := s.resumes.Since(accountId, from)
+	if err != nil {
+		s.logger.Log("msg", "Reading expired calls failed", "account_id", accountId, "err", err)
+		return
+	}
+	now := time.Now()
+	for _, call := range backlog {
+		if call.Seq > to || !call.Expired(now) {
+			continue
+		}
+		if err := s.expiries.RecordExpired(accountId, call, tunnel.ReasonExpiredInBacklog); err != nil {
+			s.logger.Log("msg", "Recording expired call failed", "account_id", accountId, "hook", call.Id, "err", err)
+		}
+	}
+}
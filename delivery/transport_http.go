@@ -0,0 +1,116 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gohook/gohook-server/user"
+	"github.com/gorilla/mux"
+	"golang.org/x/net/context"
+)
+
+func MakeDeliveryHTTPServer(ctx context.Context, endpoints Endpoints, logger log.Logger) http.Handler {
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(errorEncoder),
+		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerBefore(extractAuthToken),
+	}
+	m := mux.NewRouter()
+	m.Handle("/v1/deliveries", httptransport.NewServer(
+		ctx,
+		endpoints.PollEndpoint,
+		DecodeHTTPPollRequest,
+		EncodeHTTPResponse,
+		options...,
+	)).Methods("GET")
+	m.Handle("/v1/deliveries/ack", httptransport.NewServer(
+		ctx,
+		endpoints.AckEndpoint,
+		DecodeHTTPAckRequest,
+		EncodeHTTPResponse,
+		options...,
+	)).Methods("POST")
+	return m
+}
+
+func extractAuthToken(ctx context.Context, r *http.Request) context.Context {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return context.WithValue(ctx, "token", strings.TrimSpace(auth[7:]))
+	}
+	return ctx
+}
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+func errorEncoder(_ context.Context, err error, w http.ResponseWriter) {
+	code := http.StatusInternalServerError
+	msg := err.Error()
+
+	if e, ok := err.(httptransport.Error); ok {
+		msg = e.Err.Error()
+		switch e.Domain {
+		case httptransport.DomainDecode:
+			code = http.StatusBadRequest
+
+		case httptransport.DomainDo:
+			code = http.StatusBadRequest
+			switch e.Err {
+			case ErrUnauthorized:
+				code = http.StatusUnauthorized
+				w.Header().Set("WWW-Authenticate", `Bearer realm="gohook"`)
+			case user.ErrAccountSuspended:
+				code = http.StatusForbidden
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorWrapper{Error: msg})
+}
+
+// DecodeHTTPPollRequest reads the wait duration, such as 30s, and the
+// maximum number of calls from the query string.
+func DecodeHTTPPollRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := PollRequest{
+		Wait:  DefaultWait,
+		Limit: DefaultLimit,
+	}
+	q := r.URL.Query()
+	if v := q.Get("wait"); v != "" {
+		wait, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, ErrInvalidWait
+		}
+		req.Wait = wait
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, ErrInvalidLimit
+		}
+		req.Limit = limit
+	}
+	return req, nil
+}
+
+func DecodeHTTPAckRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := AckRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func EncodeHTTPResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
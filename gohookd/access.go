@@ -0,0 +1,232 @@
+package gohookd
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+)
+
+var (
+	ErrInvalidCIDR   = errors.New("Invalid CIDR")
+	ErrInvalidAuth   = errors.New("Invalid Auth Settings")
+	ErrUnauthorized  = errors.New("Unauthorized")
+	ErrForbidden     = errors.New("Forbidden")
+	ErrUnknownCaller = errors.New("Unable to determine caller address")
+)
+
+type AuthType string
+
+// hashPrefix marks secrets stored as their sha256 digest. Hooks
+// created before keep their secrets in plain text.
+const hashPrefix = "sha256:"
+
+const (
+	AuthNone   AuthType = ""
+	AuthBasic  AuthType = "basic"
+	AuthBearer AuthType = "bearer"
+)
+
+// HookAuth describes the credentials a caller has to present
+// when triggering a hook.
+type HookAuth struct {
+	Type     AuthType `json:"type"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Token    string   `json:"token"`
+}
+
+// Credentials are the credentials presented by a caller.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// AccessPolicy restricts who is allowed to trigger a hook. The
+// zero value allows every caller.
+type AccessPolicy struct {
+	// AllowedCIDRs limits callers to the listed networks. A plain
+	// ip address is treated as a single host network.
+	AllowedCIDRs []string `json:"allowed_cidrs"`
+
+	// TrustedProxies is the number of proxies in front of the server
+	// whose X-Forwarded-For entries can be trusted.
+	TrustedProxies int `json:"trusted_proxies"`
+
+	Auth HookAuth `json:"auth"`
+}
+
+func (p AccessPolicy) Validate() error {
+	if _, err := p.networks(); err != nil {
+		return err
+	}
+	if p.TrustedProxies < 0 {
+		return ErrInvalidAuth
+	}
+	switch p.Auth.Type {
+	case AuthNone:
+	case AuthBasic:
+		if p.Auth.Username == "" || p.Auth.Password == "" {
+			return ErrInvalidAuth
+		}
+	case AuthBearer:
+		if p.Auth.Token == "" {
+			return ErrInvalidAuth
+		}
+	default:
+		return ErrInvalidAuth
+	}
+	return nil
+}
+
+func (p AccessPolicy) networks() ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(p.AllowedCIDRs))
+	for _, cidr := range p.AllowedCIDRs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, ErrInvalidCIDR
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, ErrInvalidCIDR
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Redacted returns a copy of the policy without any secrets so it
+// can be handed back to clients.
+func (p AccessPolicy) Redacted() AccessPolicy {
+	p.Auth.Password = ""
+	p.Auth.Token = ""
+	return p
+}
+
+// Hashed returns a copy of the policy with the password and token
+// replaced by their digest, so the hook stores never keep them.
+func (p AccessPolicy) Hashed() AccessPolicy {
+	if p.Auth.Password != "" {
+		p.Auth.Password = hashSecret(p.Auth.Password)
+	}
+	if p.Auth.Token != "" {
+		p.Auth.Token = hashSecret(p.Auth.Token)
+	}
+	return p
+}
+
+// ClientIP resolves the address of the original caller. The remote
+// address and the X-Forwarded-For chain are walked from the right,
+// skipping the number of trusted proxies. The remote address is used
+// when the chain is shorter than that.
+func (p AccessPolicy) ClientIP(remoteAddr string, forwardedFor []string) (net.IP, error) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	chain := []string{}
+	for _, header := range forwardedFor {
+		for _, addr := range strings.Split(header, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				chain = append(chain, addr)
+			}
+		}
+	}
+	chain = append(chain, host)
+
+	// a chain shorter than the trusted proxies was not set by them,
+	// its first entry is whatever the caller sent
+	i := len(chain) - 1 - p.TrustedProxies
+	if i < 0 {
+		i = len(chain) - 1
+	}
+	ip := net.ParseIP(chain[i])
+	if ip == nil {
+		return nil, ErrUnknownCaller
+	}
+	return ip, nil
+}
+
+// AllowsIP checks the caller address against the allowed networks.
+func (p AccessPolicy) AllowsIP(ip net.IP) bool {
+	if len(p.AllowedCIDRs) == 0 {
+		return true
+	}
+	nets, err := p.networks()
+	if err != nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthError is returned when the presented credentials do not match
+// the required ones. Scheme is the auth type the caller has to use.
+type AuthError struct {
+	Scheme AuthType
+}
+
+func (e AuthError) Error() string {
+	return ErrUnauthorized.Error()
+}
+
+// Challenge is the WWW-Authenticate challenge for the scheme.
+func (e AuthError) Challenge() string {
+	if e.Scheme == AuthBearer {
+		return `Bearer realm="gohook"`
+	}
+	return `Basic realm="gohook"`
+}
+
+// Authenticate checks the presented credentials against the
+// required ones.
+func (p AccessPolicy) Authenticate(c Credentials) error {
+	switch p.Auth.Type {
+	case AuthNone:
+		return nil
+	case AuthBasic:
+		if secureCompare(c.Username, p.Auth.Username) && matchSecret(c.Password, p.Auth.Password) {
+			return nil
+		}
+	case AuthBearer:
+		if c.Token != "" && matchSecret(c.Token, p.Auth.Token) {
+			return nil
+		}
+	}
+	return AuthError{Scheme: p.Auth.Type}
+}
+
+func secureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// matchSecret compares a presented secret with a stored one, hashed
+// or in plain text.
+func matchSecret(presented, stored string) bool {
+	if strings.HasPrefix(stored, hashPrefix) {
+		return secureCompare(hashSecret(presented), stored)
+	}
+	return secureCompare(presented, stored)
+}
@@ -0,0 +1,43 @@
+package gohookd
+
+import (
+	"time"
+
+	"github.com/gohook/gohook-server/user"
+)
+
+type DeliveryID string
+
+type DeliveryStatus string
+
+const (
+	DeliveryQueued   DeliveryStatus = "queued"
+	DeliveryRejected DeliveryStatus = "rejected"
+	// DeliveryExpired calls were dropped once their hook's TTL passed.
+	DeliveryExpired DeliveryStatus = "expired"
+)
+
+// Delivery records a single call to a hook and what happened to it.
+type Delivery struct {
+	Id        DeliveryID     `json:"id"`
+	HookId    HookID         `json:"hook_id"`
+	AccountId user.AccountId `json:"account_id"`
+	Method    string         `json:"method"`
+	RemoteIP  string         `json:"remote_ip"`
+	Status    DeliveryStatus `json:"status"`
+	Reason    string         `json:"reason"`
+	Time      time.Time      `json:"time"`
+}
+
+type DeliveryList []*Delivery
+
+// HistoryStore is an interface defining the methods used to store
+// the delivery history of hooks
+type HistoryStore interface {
+	Add(delivery *Delivery) error
+	FindAll() (DeliveryList, error)
+	FindByHook(hookId HookID) (DeliveryList, error)
+
+	// Scope requests to a user
+	Scope(accountId user.AccountId) HistoryStore
+}
@@ -1,11 +1,41 @@
 package gohookd
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gohook/gohook-server/user"
 )
 
+var (
+	ErrInvalidOfflinePolicy = errors.New("Invalid Offline Policy")
+	ErrInvalidTiming        = errors.New("Invalid Delivery Timing")
+)
+
 type HookID string
 
+// OfflinePolicy decides how a call to a hook is answered when no
+// client of the account is connected.
+type OfflinePolicy string
+
+const (
+	// OfflineAccept accepts the call like any other.
+	OfflineAccept OfflinePolicy = ""
+	// OfflineQueue accepts the call with 202 Accepted. It waits in the
+	// tunnel backlog for a client to resume.
+	OfflineQueue OfflinePolicy = "queue"
+	// OfflineReject answers 503 Service Unavailable with Retry-After.
+	OfflineReject OfflinePolicy = "reject"
+)
+
+func (p OfflinePolicy) Validate() error {
+	switch p {
+	case OfflineAccept, OfflineQueue, OfflineReject:
+		return nil
+	}
+	return ErrInvalidOfflinePolicy
+}
+
 type HookList []*Hook
 
 type Hook struct {
@@ -13,10 +43,38 @@ type Hook struct {
 	Url       string         `json:"url"`
 	Method    string         `json:"method"`
 	AccountId user.AccountId `json:"account_id"`
+	Access    AccessPolicy   `json:"access"`
+	// Labels let tunnel sessions subscribe to groups of hooks.
+	Labels  map[string]string `json:"labels"`
+	Offline OfflinePolicy     `json:"offline"`
+	// TTL drops calls not delivered in time, Delay holds calls back
+	// before delivery. Zero leaves them unset.
+	TTL   time.Duration `json:"ttl"`
+	Delay time.Duration `json:"delay"`
+	// Calls of a higher priority are sent ahead of waiting calls.
+	Priority int `json:"priority"`
 }
 
 type HookRequest struct {
-	Method string `json:"method"`
+	Method   string            `json:"method"`
+	Access   AccessPolicy      `json:"access"`
+	Labels   map[string]string `json:"labels"`
+	Offline  OfflinePolicy     `json:"offline"`
+	TTL      time.Duration     `json:"ttl"`
+	Delay    time.Duration     `json:"delay"`
+	Priority int               `json:"priority"`
+}
+
+// ValidateTiming rejects negative durations, and delays that leave
+// calls expired before they are delivered.
+func (r HookRequest) ValidateTiming() error {
+	if r.TTL < 0 || r.Delay < 0 {
+		return ErrInvalidTiming
+	}
+	if r.TTL > 0 && r.Delay >= r.TTL {
+		return ErrInvalidTiming
+	}
+	return nil
 }
 
 // HookStore is an interface defining the methods used to store hooks
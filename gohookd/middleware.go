@@ -1,10 +1,12 @@
 package gohookd
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/audit"
 	"github.com/gohook/gohook-server/user"
 	"golang.org/x/net/context"
 )
@@ -86,3 +88,72 @@ func (mw serviceLoggingMiddleware) Delete(ctx context.Context, deleteID HookID)
 	}(time.Now())
 	return mw.next.Delete(ctx, deleteID)
 }
+
+// ServiceAuditMiddleware writes every successful change made through
+// the service to the audit log. A failed audit write is logged, the
+// change itself has already been made.
+func ServiceAuditMiddleware(store audit.Store, logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return serviceAuditMiddleware{
+			store:  store,
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+type serviceAuditMiddleware struct {
+	store  audit.Store
+	logger log.Logger
+	next   Service
+}
+
+func (mw serviceAuditMiddleware) List(ctx context.Context) (HookList, error) {
+	return mw.next.List(ctx)
+}
+
+func (mw serviceAuditMiddleware) Create(ctx context.Context, request HookRequest) (*Hook, error) {
+	hook, err := mw.next.Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	mw.record(ctx, audit.OpHookCreate, nil, hook)
+	return hook, nil
+}
+
+func (mw serviceAuditMiddleware) Delete(ctx context.Context, id HookID) (*Hook, error) {
+	hook, err := mw.next.Delete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	mw.record(ctx, audit.OpHookDelete, hook, nil)
+	return hook, nil
+}
+
+func (mw serviceAuditMiddleware) record(ctx context.Context, op audit.Operation, before, after *Hook) {
+	account := ctx.Value("account").(*user.Account)
+	actor := audit.ActorFromContext(ctx)
+	err := mw.store.Add(&audit.Entry{
+		AccountId: account.Id,
+		Actor:     actor.Name,
+		TokenId:   actor.TokenId,
+		ClientIP:  actor.ClientIP,
+		Operation: op,
+		Before:    hookSnapshot(before),
+		After:     hookSnapshot(after),
+		Time:      time.Now(),
+	})
+	if err != nil {
+		mw.logger.Log("msg", "Audit write failed", "account_id", account.Id, "operation", op, "err", err)
+	}
+}
+
+func hookSnapshot(hook *Hook) []byte {
+	if hook == nil {
+		return nil
+	}
+	snapshot := *hook
+	snapshot.Access = hook.Access.Redacted()
+	b, _ := json.Marshal(snapshot)
+	return b
+}
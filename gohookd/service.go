@@ -3,6 +3,7 @@ package gohookd
 import (
 	"fmt"
 
+	"github.com/gohook/gohook-server/tunnel"
 	"github.com/gohook/gohook-server/user"
 	"github.com/ventu-io/go-shortid"
 	"golang.org/x/net/context"
@@ -34,10 +35,11 @@ func WithOrigin(origin string) *ServiceOpts {
 	return opts
 }
 
-func NewBasicService(store HookStore, authService user.AuthService, opts *ServiceOpts) Service {
+func NewBasicService(store HookStore, authService user.AuthService, queue tunnel.HookQueue, opts *ServiceOpts) Service {
 	return &basicService{
 		hooks: store,
 		auth:  authService,
+		queue: queue,
 		opts:  opts,
 	}
 }
@@ -45,6 +47,7 @@ func NewBasicService(store HookStore, authService user.AuthService, opts *Servic
 type basicService struct {
 	hooks HookStore
 	auth  user.AuthService
+	queue tunnel.HookQueue
 	opts  *ServiceOpts
 }
 
@@ -59,6 +62,24 @@ func (s basicService) List(ctx context.Context) (HookList, error) {
 
 func (s *basicService) Create(ctx context.Context, request HookRequest) (*Hook, error) {
 	account := ctx.Value("account").(*user.Account)
+	if err := request.Access.Validate(); err != nil {
+		return nil, err
+	}
+	if err := request.Offline.Validate(); err != nil {
+		return nil, err
+	}
+	if err := request.ValidateTiming(); err != nil {
+		return nil, err
+	}
+	hooks := s.hooks.Scope(account.Id)
+	limits := account.EffectiveLimits()
+	existing, err := hooks.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.CheckHooks(len(existing)); err != nil {
+		return nil, err
+	}
 	sid, err := shortid.New(1, "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_+", 53646)
 	if err != nil {
 		return nil, err
@@ -68,14 +89,32 @@ func (s *basicService) Create(ctx context.Context, request HookRequest) (*Hook,
 		return nil, err
 	}
 	newHook := &Hook{
-		Id:     HookID(id),
-		Url:    fmt.Sprintf("%s://%s/%s/%s", s.opts.Protocol, s.opts.Origin, account.Id, id),
-		Method: request.Method,
+		Id:       HookID(id),
+		Url:      fmt.Sprintf("%s://%s/%s/%s", s.opts.Protocol, s.opts.Origin, account.Id, id),
+		Method:   request.Method,
+		Access:   request.Access.Hashed(),
+		Labels:   request.Labels,
+		Offline:  request.Offline,
+		TTL:      request.TTL,
+		Delay:    request.Delay,
+		Priority: request.Priority,
+	}
+	err = hooks.Add(newHook)
+	if err != nil {
+		return nil, err
+	}
+	// Concurrent creates can pass the check above together. The hooks
+	// are counted again once this one is stored, and it is removed
+	// when it went over the limit.
+	all, err := hooks.FindAll()
+	if err == nil {
+		err = limits.CheckHooks(len(all) - 1)
 	}
-	err = s.hooks.Scope(account.Id).Add(newHook)
 	if err != nil {
+		hooks.Remove(newHook.Id)
 		return nil, err
 	}
+	s.notify(account.Id, tunnel.EventHookCreated, newHook)
 	return newHook, nil
 }
 
@@ -85,5 +124,25 @@ func (s *basicService) Delete(ctx context.Context, id HookID) (*Hook, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.notify(account.Id, tunnel.EventHookDeleted, hook)
 	return hook, nil
 }
+
+// notify tells the connected clients of the account about the hook.
+// The change has already been made, so a failed broadcast is not
+// returned to the caller. Clients can still List the hooks.
+func (s *basicService) notify(accountId user.AccountId, eventType tunnel.EventType, hook *Hook) {
+	s.queue.Broadcast(&tunnel.QueueMessage{
+		Type:      tunnel.MessageEvent,
+		AccountId: accountId,
+		Event: &tunnel.Event{
+			Type: eventType,
+			Hook: tunnel.HookInfo{
+				Id:     string(hook.Id),
+				Url:    hook.Url,
+				Method: hook.Method,
+				Labels: hook.Labels,
+			},
+		},
+	})
+}
@@ -2,12 +2,18 @@ package gohookd
 
 import (
 	"errors"
+	"net"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	grpctransport "github.com/go-kit/kit/transport/grpc"
 	"github.com/gohook/gohook-server/pb"
+	"github.com/gohook/gohook-server/user"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
 type GohookdServer struct {
@@ -23,10 +29,21 @@ func extractAuthToken(ctx context.Context, md *metadata.MD) context.Context {
 	return ctx
 }
 
+func extractClientIP(ctx context.Context, _ *metadata.MD) context.Context {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return context.WithValue(ctx, "client_ip", host)
+	}
+	return ctx
+}
+
 func MakeGohookdServer(ctx context.Context, endpoints Endpoints, logger log.Logger) *GohookdServer {
 	options := []grpctransport.ServerOption{
 		grpctransport.ServerErrorLogger(logger),
-		grpctransport.ServerBefore(extractAuthToken),
+		grpctransport.ServerBefore(extractAuthToken, extractClientIP),
 	}
 	return &GohookdServer{
 		list: grpctransport.NewServer(
@@ -53,11 +70,26 @@ func MakeGohookdServer(ctx context.Context, endpoints Endpoints, logger log.Logg
 	}
 }
 
+// grpcError maps service errors to their gRPC status codes.
+func grpcError(err error) error {
+	switch err.(type) {
+	case user.QuotaError:
+		return grpc.Errorf(codes.ResourceExhausted, "%v", err)
+	}
+	switch err {
+	case ErrInvalidCIDR, ErrInvalidAuth, ErrInvalidOfflinePolicy, ErrInvalidTiming:
+		return grpc.Errorf(codes.InvalidArgument, "%v", err)
+	case user.ErrAccountSuspended:
+		return grpc.Errorf(codes.PermissionDenied, "%v", err)
+	}
+	return err
+}
+
 // List transport handler
 func (s *GohookdServer) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
 	_, rep, err := s.list.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 	return rep.(*pb.ListResponse), nil
 }
@@ -66,7 +98,7 @@ func (s *GohookdServer) List(ctx context.Context, req *pb.ListRequest) (*pb.List
 func (s *GohookdServer) Create(ctx context.Context, req *pb.CreateRequest) (*pb.CreateResponse, error) {
 	_, rep, err := s.create.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 	return rep.(*pb.CreateResponse), nil
 }
@@ -75,7 +107,7 @@ func (s *GohookdServer) Create(ctx context.Context, req *pb.CreateRequest) (*pb.
 func (s *GohookdServer) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
 	_, rep, err := s.delete.ServeGRPC(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 	return rep.(*pb.DeleteResponse), nil
 }
@@ -99,9 +131,15 @@ func EncodeGRPCListResponse(_ context.Context, response interface{}) (interface{
 			return nil, errors.New("Invalid Method Name")
 		}
 		pbHooks = append(pbHooks, &pb.Hook{
-			Id:     string(h.Id),
-			Url:    h.Url,
-			Method: pb.Method(method),
+			Id:       string(h.Id),
+			Url:      h.Url,
+			Method:   pb.Method(method),
+			Access:   encodeAccessPolicy(h.Access.Redacted()),
+			Labels:   h.Labels,
+			Offline:  encodeOfflinePolicy(h.Offline),
+			TtlMs:    encodeDuration(h.TTL),
+			DelayMs:  encodeDuration(h.Delay),
+			Priority: int32(h.Priority),
 		})
 	}
 	return &pb.ListResponse{pbHooks}, nil
@@ -116,9 +154,15 @@ func DecodeGRPCListResponse(_ context.Context, grpcReply interface{}) (interface
 			return nil, errors.New("Invalid Method ID")
 		}
 		modelHooks = append(modelHooks, &Hook{
-			Id:     HookID(h.Id),
-			Url:    h.Url,
-			Method: methodName,
+			Id:       HookID(h.Id),
+			Url:      h.Url,
+			Method:   methodName,
+			Access:   decodeAccessPolicy(h.Access),
+			Labels:   h.Labels,
+			Offline:  decodeOfflinePolicy(h.Offline),
+			TTL:      decodeDuration(h.TtlMs),
+			Delay:    decodeDuration(h.DelayMs),
+			Priority: int(h.Priority),
 		})
 	}
 	return modelHooks, nil
@@ -132,7 +176,13 @@ func EncodeGRPCCreateRequest(_ context.Context, request interface{}) (interface{
 		return nil, errors.New("1 Invalid Method Name")
 	}
 	createReq := &pb.HookRequest{
-		Method: pb.Method(methodID),
+		Method:   pb.Method(methodID),
+		Access:   encodeAccessPolicy(hook.Access),
+		Labels:   hook.Labels,
+		Offline:  encodeOfflinePolicy(hook.Offline),
+		TtlMs:    encodeDuration(hook.TTL),
+		DelayMs:  encodeDuration(hook.Delay),
+		Priority: int32(hook.Priority),
 	}
 	return &pb.CreateRequest{createReq}, nil
 }
@@ -145,7 +195,13 @@ func DecodeGRPCCreateRequest(_ context.Context, grpcReq interface{}) (interface{
 		return nil, errors.New("2 Invalid Method Name")
 	}
 	hook := HookRequest{
-		Method: method,
+		Method:   method,
+		Access:   decodeAccessPolicy(hookReq.Access),
+		Labels:   hookReq.Labels,
+		Offline:  decodeOfflinePolicy(hookReq.Offline),
+		TTL:      decodeDuration(hookReq.TtlMs),
+		Delay:    decodeDuration(hookReq.DelayMs),
+		Priority: int(hookReq.Priority),
 	}
 	return hook, nil
 }
@@ -157,9 +213,15 @@ func EncodeGRPCCreateResponse(_ context.Context, response interface{}) (interfac
 		return nil, errors.New("3 Invalid Method Name")
 	}
 	hook := &pb.Hook{
-		Id:     string(createRes.Id),
-		Url:    createRes.Url,
-		Method: pb.Method(method),
+		Id:       string(createRes.Id),
+		Url:      createRes.Url,
+		Method:   pb.Method(method),
+		Access:   encodeAccessPolicy(createRes.Access.Redacted()),
+		Labels:   createRes.Labels,
+		Offline:  encodeOfflinePolicy(createRes.Offline),
+		TtlMs:    encodeDuration(createRes.TTL),
+		DelayMs:  encodeDuration(createRes.Delay),
+		Priority: int32(createRes.Priority),
 	}
 	return &pb.CreateResponse{hook}, nil
 }
@@ -172,9 +234,15 @@ func DecodeGRPCCreateResponse(_ context.Context, response interface{}) (interfac
 		return nil, errors.New("4 Invalid Method Name")
 	}
 	hook := &Hook{
-		Id:     HookID(hookRes.Id),
-		Url:    hookRes.Url,
-		Method: method,
+		Id:       HookID(hookRes.Id),
+		Url:      hookRes.Url,
+		Method:   method,
+		Access:   decodeAccessPolicy(hookRes.Access),
+		Labels:   hookRes.Labels,
+		Offline:  decodeOfflinePolicy(hookRes.Offline),
+		TTL:      decodeDuration(hookRes.TtlMs),
+		Delay:    decodeDuration(hookRes.DelayMs),
+		Priority: int(hookRes.Priority),
 	}
 	return hook, nil
 }
@@ -197,9 +265,15 @@ func EncodeGRPCDeleteResponse(_ context.Context, response interface{}) (interfac
 		return nil, errors.New("Invalid Method Name")
 	}
 	hook := &pb.Hook{
-		Id:     string(deleteRes.Id),
-		Url:    deleteRes.Url,
-		Method: pb.Method(method),
+		Id:       string(deleteRes.Id),
+		Url:      deleteRes.Url,
+		Method:   pb.Method(method),
+		Access:   encodeAccessPolicy(deleteRes.Access.Redacted()),
+		Labels:   deleteRes.Labels,
+		Offline:  encodeOfflinePolicy(deleteRes.Offline),
+		TtlMs:    encodeDuration(deleteRes.TTL),
+		DelayMs:  encodeDuration(deleteRes.Delay),
+		Priority: int32(deleteRes.Priority),
 	}
 	return &pb.DeleteResponse{hook}, nil
 }
@@ -212,9 +286,88 @@ func DecodeGRPCDeleteResponse(_ context.Context, grpcReply interface{}) (interfa
 		return nil, errors.New("Invalid Method Name")
 	}
 	hook := &Hook{
-		Id:     HookID(hookRes.Id),
-		Url:    hookRes.Url,
-		Method: method,
+		Id:       HookID(hookRes.Id),
+		Url:      hookRes.Url,
+		Method:   method,
+		Access:   decodeAccessPolicy(hookRes.Access),
+		Labels:   hookRes.Labels,
+		Offline:  decodeOfflinePolicy(hookRes.Offline),
+		TTL:      decodeDuration(hookRes.TtlMs),
+		Delay:    decodeDuration(hookRes.DelayMs),
+		Priority: int(hookRes.Priority),
 	}
 	return hook, nil
 }
+
+// Access policy transforms
+func encodeAccessPolicy(p AccessPolicy) *pb.AccessPolicy {
+	authType := pb.AuthType_AUTH_NONE
+	switch p.Auth.Type {
+	case AuthBasic:
+		authType = pb.AuthType_AUTH_BASIC
+	case AuthBearer:
+		authType = pb.AuthType_AUTH_BEARER
+	}
+	return &pb.AccessPolicy{
+		AllowedCidrs:   p.AllowedCIDRs,
+		TrustedProxies: int32(p.TrustedProxies),
+		Auth: &pb.HookAuth{
+			Type:     authType,
+			Username: p.Auth.Username,
+			Password: p.Auth.Password,
+			Token:    p.Auth.Token,
+		},
+	}
+}
+
+func decodeAccessPolicy(p *pb.AccessPolicy) AccessPolicy {
+	if p == nil {
+		return AccessPolicy{}
+	}
+	policy := AccessPolicy{
+		AllowedCIDRs:   p.AllowedCidrs,
+		TrustedProxies: int(p.TrustedProxies),
+	}
+	if auth := p.GetAuth(); auth != nil {
+		switch auth.Type {
+		case pb.AuthType_AUTH_BASIC:
+			policy.Auth.Type = AuthBasic
+		case pb.AuthType_AUTH_BEARER:
+			policy.Auth.Type = AuthBearer
+		}
+		policy.Auth.Username = auth.Username
+		policy.Auth.Password = auth.Password
+		policy.Auth.Token = auth.Token
+	}
+	return policy
+}
+
+// Durations are sent in milliseconds
+func encodeDuration(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
+
+func decodeDuration(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+// Offline policy transforms
+func encodeOfflinePolicy(p OfflinePolicy) pb.OfflinePolicy {
+	switch p {
+	case OfflineQueue:
+		return pb.OfflinePolicy_OFFLINE_QUEUE
+	case OfflineReject:
+		return pb.OfflinePolicy_OFFLINE_REJECT
+	}
+	return pb.OfflinePolicy_OFFLINE_ACCEPT
+}
+
+func decodeOfflinePolicy(p pb.OfflinePolicy) OfflinePolicy {
+	switch p {
+	case pb.OfflinePolicy_OFFLINE_QUEUE:
+		return OfflineQueue
+	case pb.OfflinePolicy_OFFLINE_REJECT:
+		return OfflineReject
+	}
+	return OfflineAccept
+}
@@ -34,6 +34,16 @@ func (i *InMemAccounts) Add(a *user.Account) error {
 	return nil
 }
 
+func (i *InMemAccounts) Update(a *user.Account) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if _, ok := i.accounts[a.Id]; !ok {
+		return errors.New("Not Found")
+	}
+	i.accounts[a.Id] = a
+	return nil
+}
+
 func (i *InMemAccounts) Remove(id user.AccountId) (*user.Account, error) {
 	i.mtx.Lock()
 	defer i.mtx.Unlock()
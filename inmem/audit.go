@@ -0,0 +1,40 @@
+package inmem
+
+import (
+	"sync"
+
+	"github.com/gohook/gohook-server/audit"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+)
+
+type InMemAudit struct {
+	mtx     sync.RWMutex
+	entries audit.EntryList
+}
+
+func NewInMemAudit() audit.Store {
+	return &InMemAudit{
+		entries: audit.EntryList{},
+	}
+}
+
+func (i *InMemAudit) Add(e *audit.Entry) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	e.Id = audit.EntryID(uuid.NewV4().String())
+	i.entries = append(i.entries, e)
+	return nil
+}
+
+func (i *InMemAudit) FindByAccount(accountId user.AccountId) (audit.EntryList, error) {
+	i.mtx.RLock()
+	defer i.mtx.RUnlock()
+	entries := audit.EntryList{}
+	for n := len(i.entries) - 1; n >= 0; n-- {
+		if i.entries[n].AccountId == accountId {
+			entries = append(entries, i.entries[n])
+		}
+	}
+	return entries, nil
+}
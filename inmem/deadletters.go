@@ -0,0 +1,80 @@
+package inmem
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+)
+
+type InMemDeadLetters struct {
+	mtx     sync.RWMutex
+	letters map[user.AccountId]tunnel.DeadLetterList
+}
+
+func NewInMemDeadLetters() tunnel.DeadLetterStore {
+	return &InMemDeadLetters{
+		letters: make(map[user.AccountId]tunnel.DeadLetterList),
+	}
+}
+
+func (i *InMemDeadLetters) Add(l *tunnel.DeadLetter) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	l.Id = tunnel.DeadLetterId(uuid.NewV4().String())
+
+	// letters are added in order, so the expired ones come first
+	letters := i.letters[l.AccountId]
+	for len(letters) > 0 && time.Since(letters[0].Time) > tunnel.DeadLetterRetention {
+		letters = letters[1:]
+	}
+	i.letters[l.AccountId] = append(letters, l)
+	return nil
+}
+
+func (i *InMemDeadLetters) FindByAccount(accountId user.AccountId) (tunnel.DeadLetterList, error) {
+	i.mtx.RLock()
+	defer i.mtx.RUnlock()
+	letters := tunnel.DeadLetterList{}
+	all := i.letters[accountId]
+	for n := len(all) - 1; n >= 0; n-- {
+		if time.Since(all[n].Time) <= tunnel.DeadLetterRetention {
+			letters = append(letters, all[n])
+		}
+	}
+	return letters, nil
+}
+
+func (i *InMemDeadLetters) Find(accountId user.AccountId, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	i.mtx.RLock()
+	defer i.mtx.RUnlock()
+	for _, l := range i.letters[accountId] {
+		if l.Id == id {
+			return l, nil
+		}
+	}
+	return nil, tunnel.ErrDeadLetterNotFound
+}
+
+func (i *InMemDeadLetters) Remove(accountId user.AccountId, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	letters := i.letters[accountId]
+	for n, l := range letters {
+		if l.Id == id {
+			i.letters[accountId] = append(letters[:n:n], letters[n+1:]...)
+			return l, nil
+		}
+	}
+	return nil, tunnel.ErrDeadLetterNotFound
+}
+
+func (i *InMemDeadLetters) Purge(accountId user.AccountId) (int, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	n := len(i.letters[accountId])
+	delete(i.letters, accountId)
+	return n, nil
+}
@@ -0,0 +1,64 @@
+package inmem
+
+import (
+	"sync"
+
+	"github.com/gohook/gohook-server/gohookd"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+)
+
+type InMemHistory struct {
+	mtx        *sync.RWMutex
+	deliveries *gohookd.DeliveryList
+	accountId  user.AccountId
+	scoped     bool
+}
+
+func NewInMemHistory() gohookd.HistoryStore {
+	return &InMemHistory{
+		mtx:        &sync.RWMutex{},
+		deliveries: &gohookd.DeliveryList{},
+		scoped:     false,
+	}
+}
+
+func (i InMemHistory) Scope(accountId user.AccountId) gohookd.HistoryStore {
+	i.accountId = accountId
+	i.scoped = true
+	return &i
+}
+
+func (i *InMemHistory) Add(d *gohookd.Delivery) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if i.scoped {
+		d.AccountId = i.accountId
+	}
+	d.Id = gohookd.DeliveryID(uuid.NewV4().String())
+	*i.deliveries = append(*i.deliveries, d)
+	return nil
+}
+
+func (i *InMemHistory) FindAll() (gohookd.DeliveryList, error) {
+	return i.find(func(*gohookd.Delivery) bool { return true })
+}
+
+func (i *InMemHistory) FindByHook(hookId gohookd.HookID) (gohookd.DeliveryList, error) {
+	return i.find(func(d *gohookd.Delivery) bool { return d.HookId == hookId })
+}
+
+func (i *InMemHistory) find(match func(*gohookd.Delivery) bool) (gohookd.DeliveryList, error) {
+	i.mtx.RLock()
+	defer i.mtx.RUnlock()
+	h := gohookd.DeliveryList{}
+	for _, val := range *i.deliveries {
+		if i.scoped && val.AccountId != i.accountId {
+			continue
+		}
+		if match(val) {
+			h = append(h, val)
+		}
+	}
+	return h, nil
+}
@@ -0,0 +1,62 @@
+package inmem
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+)
+
+type InMemPresence struct {
+	mtx       sync.Mutex
+	instances map[user.AccountId]map[tunnel.InstanceId]time.Time
+}
+
+func NewInMemPresence() tunnel.Presence {
+	return &InMemPresence{
+		instances: make(map[user.AccountId]map[tunnel.InstanceId]time.Time),
+	}
+}
+
+func (i *InMemPresence) Put(accountId user.AccountId, instance tunnel.InstanceId) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	instances, ok := i.instances[accountId]
+	if !ok {
+		instances = make(map[tunnel.InstanceId]time.Time)
+		i.instances[accountId] = instances
+	}
+	instances[instance] = time.Now().Add(tunnel.PresenceExpiry)
+	return nil
+}
+
+func (i *InMemPresence) Remove(accountId user.AccountId, instance tunnel.InstanceId) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if instances, ok := i.instances[accountId]; ok {
+		delete(instances, instance)
+		if len(instances) == 0 {
+			delete(i.instances, accountId)
+		}
+	}
+	return nil
+}
+
+func (i *InMemPresence) Find(accountId user.AccountId) (*tunnel.PresenceInfo, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	info := &tunnel.PresenceInfo{
+		AccountId: accountId,
+		Instances: []tunnel.InstanceId{},
+	}
+	now := time.Now()
+	for instance, expires := range i.instances[accountId] {
+		if now.After(expires) {
+			delete(i.instances[accountId], instance)
+			continue
+		}
+		info.Instances = append(info.Instances, instance)
+	}
+	return info, nil
+}
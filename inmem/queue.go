@@ -1,7 +1,13 @@
 package inmem
 
 import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/gohook/gohook-server/tunnel"
+	"golang.org/x/net/context"
 )
 
 /*
@@ -14,29 +20,194 @@ within the same process. This makes testing much easier and
 allows the single gohookd process to run without external
 dependencies.
 
-THIS DOES NOT SCALE. Only use for testing and single client
-setups.
+Every Listen call gets a channel of its own, and every message
+is sent to each of them. Channels are buffered so Broadcast does
+not wait for the listeners to read.
 
 Since gohookd processes can't communicate when they receive
 a hook message, there is no guarantee that the hook message
-will go to the process that the client is connected to.
+will go to the process that the client is connected to. Only
+use it for tests and single process setups.
 */
 
+// DefaultQueueBuffer is the number of messages a listener can fall
+// behind before messages to it are dropped.
+const DefaultQueueBuffer = 1024
+
+var ErrQueueFull = errors.New("Queue Full")
+
 type InMemQueue struct {
-	receivec tunnel.ReceiveC
+	size int
+
+	mtx       sync.RWMutex
+	listeners []tunnel.ReceiveC
+
+	// delayed hook calls, by key
+	delayMtx sync.Mutex
+	delayed  map[uint64]*delayedMessage
+	delayKey uint64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// delayedMessage is handed out once due passed. Handing it out moves
+// due by the lease.
+type delayedMessage struct {
+	due time.Time
+	msg *tunnel.QueueMessage
 }
 
 func NewInMemQueue() tunnel.HookQueue {
-	return InMemQueue{
-		receivec: make(tunnel.ReceiveC),
+	return NewInMemQueueSize(DefaultQueueBuffer)
+}
+
+// NewInMemQueueSize creates a queue whose listeners buffer size
+// messages each.
+func NewInMemQueueSize(size int) tunnel.HookQueue {
+	return &InMemQueue{
+		size:    size,
+		delayed: map[uint64]*delayedMessage{},
+		closed:  make(chan struct{}),
+	}
+}
+
+// Broadcast sends the message to every listener without waiting. When
+// the buffer of a listener is full the message is dropped for it and
+// ErrQueueFull is returned once the others have it.
+func (i *InMemQueue) Broadcast(m *tunnel.QueueMessage) error {
+	return i.broadcast(false, nil, m)
+}
+
+// BroadcastContext waits for room in the buffers of the listeners
+// until ctx is done. A context that is never done waits as long as it
+// takes.
+func (i *InMemQueue) BroadcastContext(ctx context.Context, m *tunnel.QueueMessage) error {
+	return i.broadcast(true, ctx.Done(), m)
+}
+
+// Listeners share the message, none of them may modify it.
+func (i *InMemQueue) broadcast(wait bool, done <-chan struct{}, m *tunnel.QueueMessage) error {
+	i.mtx.RLock()
+	defer i.mtx.RUnlock()
+	if i.isClosed() {
+		return tunnel.ErrQueueClosed
+	}
+
+	var err error
+	for _, c := range i.listeners {
+		select {
+		case c <- m:
+			continue
+		default:
+		}
+		if !wait {
+			err = ErrQueueFull
+			continue
+		}
+		select {
+		case c <- m:
+		case <-done:
+			err = ErrQueueFull
+		case <-i.closed:
+			return tunnel.ErrQueueClosed
+		}
+	}
+	return err
+}
+
+// Delay keeps the message until its call is due. Delayed calls are
+// lost with the process, like every other message of the queue.
+func (i *InMemQueue) Delay(m *tunnel.QueueMessage) error {
+	if i.isClosed() {
+		return tunnel.ErrQueueClosed
+	}
+	i.delayMtx.Lock()
+	defer i.delayMtx.Unlock()
+	i.delayKey++
+	i.delayed[i.delayKey] = &delayedMessage{due: m.Hook.NotBefore, msg: m}
+	return nil
+}
+
+// Due hands out the delayed messages due by now, the earliest first.
+func (i *InMemQueue) Due(now time.Time, max int) ([]*tunnel.QueueMessage, error) {
+	if i.isClosed() {
+		return nil, tunnel.ErrQueueClosed
+	}
+	i.delayMtx.Lock()
+	defer i.delayMtx.Unlock()
+
+	keys := []uint64{}
+	for key, d := range i.delayed {
+		if !d.due.After(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		return i.delayed[keys[a]].due.Before(i.delayed[keys[b]].due)
+	})
+	if len(keys) > max {
+		keys = keys[:max]
+	}
+
+	msgs := []*tunnel.QueueMessage{}
+	for _, key := range keys {
+		d := i.delayed[key]
+		d.due = now.Add(tunnel.DelayLease)
+		msg := *d.msg
+		key := key
+		msg.Ack = func() error {
+			i.delayMtx.Lock()
+			defer i.delayMtx.Unlock()
+			delete(i.delayed, key)
+			return nil
+		}
+		msgs = append(msgs, &msg)
+	}
+	return msgs, nil
+}
+
+func (i *InMemQueue) Listen() (tunnel.ReceiveC, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if i.isClosed() {
+		return nil, tunnel.ErrQueueClosed
+	}
+	c := make(tunnel.ReceiveC, i.size)
+	i.listeners = append(i.listeners, c)
+	return c, nil
+}
+
+// Health reports ErrQueueClosed once the queue has been closed.
+func (i *InMemQueue) Health() error {
+	if i.isClosed() {
+		return tunnel.ErrQueueClosed
 	}
+	return nil
 }
 
-func (i InMemQueue) Broadcast(m *tunnel.QueueMessage) error {
-	i.receivec <- m
+// Close closes the channel of every listener once they read the
+// messages left in it. Broadcasting afterwards fails.
+func (i *InMemQueue) Close() error {
+	i.closeOnce.Do(func() {
+		// wakes up waiting broadcasts before taking the lock
+		close(i.closed)
+
+		i.mtx.Lock()
+		defer i.mtx.Unlock()
+		for _, c := range i.listeners {
+			close(c)
+		}
+		i.listeners = nil
+	})
 	return nil
 }
 
-func (i InMemQueue) Listen() (tunnel.ReceiveC, error) {
-	return i.receivec, nil
+func (i *InMemQueue) isClosed() bool {
+	select {
+	case <-i.closed:
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,173 @@
+package inmem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+func hookMessage(id string) *tunnel.QueueMessage {
+	return &tunnel.QueueMessage{
+		Type:      tunnel.MessageHook,
+		AccountId: user.AccountId("account"),
+		Hook:      tunnel.HookCall{Id: id},
+	}
+}
+
+func TestInMemQueueFanOut(t *testing.T) {
+	q := NewInMemQueue()
+	listeners := make([]tunnel.ReceiveC, 3)
+	for n := range listeners {
+		c, err := q.Listen()
+		if err != nil {
+			t.Fatal(err)
+		}
+		listeners[n] = c
+	}
+
+	if err := q.Broadcast(hookMessage("hook")); err != nil {
+		t.Fatal(err)
+	}
+	for n, c := range listeners {
+		select {
+		case m := <-c:
+			if m.Hook.Id != "hook" {
+				t.Fatalf("listener %d: expected the broadcast call, got %q", n, m.Hook.Id)
+			}
+		default:
+			t.Fatalf("listener %d: expected the message to be buffered", n)
+		}
+	}
+}
+
+func TestInMemQueueFull(t *testing.T) {
+	q := NewInMemQueueSize(1)
+	full, _ := q.Listen()
+	if err := q.Broadcast(hookMessage("first")); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := q.Listen()
+
+	if err := q.Broadcast(hookMessage("second")); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if m := <-full; m.Hook.Id != "first" {
+		t.Fatalf("expected the full listener to keep the first call, got %q", m.Hook.Id)
+	}
+	select {
+	case m := <-full:
+		t.Fatalf("expected the second call to be dropped, got %q", m.Hook.Id)
+	default:
+	}
+	if m := <-other; m.Hook.Id != "second" {
+		t.Fatalf("expected the other listener to get the second call, got %q", m.Hook.Id)
+	}
+}
+
+func TestInMemQueueBroadcastContext(t *testing.T) {
+	q := NewInMemQueueSize(1)
+	c, _ := q.Listen()
+	if err := q.Broadcast(hookMessage("first")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tunnel.BroadcastContext(ctx, q, hookMessage("second")); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull once the context is done, got %v", err)
+	}
+
+	// waits for room when the listener reads in time
+	done := make(chan error, 1)
+	go func() {
+		done <- tunnel.BroadcastContext(context.Background(), q, hookMessage("third"))
+	}()
+	if m := <-c; m.Hook.Id != "first" {
+		t.Fatalf("expected the first call, got %q", m.Hook.Id)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if m := <-c; m.Hook.Id != "third" {
+		t.Fatalf("expected the third call, got %q", m.Hook.Id)
+	}
+}
+
+func TestInMemQueueClose(t *testing.T) {
+	q := NewInMemQueueSize(1).(*InMemQueue)
+	c, _ := q.Listen()
+	if err := q.Broadcast(hookMessage("hook")); err != nil {
+		t.Fatal(err)
+	}
+
+	// a broadcast waiting for room is woken up by Close
+	done := make(chan error, 1)
+	go func() {
+		done <- tunnel.BroadcastContext(context.Background(), q, hookMessage("waiting"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != tunnel.ErrQueueClosed {
+			t.Fatalf("expected ErrQueueClosed for the waiting broadcast, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Close to end the waiting broadcast")
+	}
+
+	if m, ok := <-c; !ok || m.Hook.Id != "hook" {
+		t.Fatal("expected the buffered call to be read before the channel closes")
+	}
+	if _, ok := <-c; ok {
+		t.Fatal("expected the listener channel to be closed")
+	}
+	if err := q.Broadcast(hookMessage("late")); err != tunnel.ErrQueueClosed {
+		t.Fatalf("expected ErrQueueClosed, got %v", err)
+	}
+	if _, err := q.Listen(); err != tunnel.ErrQueueClosed {
+		t.Fatalf("expected Listen to fail with ErrQueueClosed, got %v", err)
+	}
+}
+
+func TestInMemQueueDelay(t *testing.T) {
+	q := NewInMemQueue().(*InMemQueue)
+	now := time.Now()
+	m := &tunnel.QueueMessage{
+		Type:      tunnel.MessageHook,
+		AccountId: user.AccountId("account"),
+		Hook:      tunnel.HookCall{Id: "hook", NotBefore: now.Add(time.Minute)},
+	}
+	if err := q.Delay(m); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := q.Due(now, 10)
+	if err != nil || len(due) != 0 {
+		t.Fatalf("expected no call due yet, got %d, %v", len(due), err)
+	}
+
+	later := now.Add(time.Minute)
+	due, err = q.Due(later, 10)
+	if err != nil || len(due) != 1 || due[0].Hook.Id != "hook" {
+		t.Fatalf("expected the call to be due, got %d, %v", len(due), err)
+	}
+	if again, _ := q.Due(later, 10); len(again) != 0 {
+		t.Fatal("expected the call to be leased")
+	}
+	if again, _ := q.Due(later.Add(tunnel.DelayLease), 10); len(again) != 1 {
+		t.Fatal("expected the call to be handed out again after its lease")
+	}
+
+	if err := due[0].Ack(); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := q.Due(later.Add(time.Hour), 10); len(again) != 0 {
+		t.Fatal("expected the acked call to be gone")
+	}
+}
@@ -0,0 +1,72 @@
+package inmem
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/user"
+)
+
+type bucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+// InMemRateLimiter keeps token buckets in process memory. The limits
+// are only enforced per process, so only use it for single node setups.
+type InMemRateLimiter struct {
+	mtx     sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewInMemRateLimiter() user.RateLimiter {
+	return &InMemRateLimiter{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (i *InMemRateLimiter) Take(key string, rate, burst int) (bool, time.Duration, error) {
+	if rate <= 0 {
+		return true, 0, nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+
+	now := time.Now()
+	b, ok := i.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), ts: now}
+		i.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.ts).Seconds()*float64(rate))
+	b.ts = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	wait := time.Duration((1 - b.tokens) / float64(rate) * float64(time.Second))
+	return false, wait, nil
+}
+
+func (i *InMemRateLimiter) Refund(key string, rate, burst int) error {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if b, ok := i.buckets[key]; ok {
+		b.tokens = math.Min(float64(burst), b.tokens+1)
+	}
+	return nil
+}
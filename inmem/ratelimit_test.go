@@ -0,0 +1,52 @@
+package inmem
+
+import "testing"
+
+func TestInMemRateLimiterBurst(t *testing.T) {
+	limiter := NewInMemRateLimiter()
+	for i := 0; i < 3; i++ {
+		ok, _, err := limiter.Take("hook", 1, 3)
+		if err != nil || !ok {
+			t.Fatalf("take %d: ok=%v err=%v", i, ok, err)
+		}
+	}
+	ok, wait, err := limiter.Take("hook", 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("expected the empty bucket to reject")
+	}
+	if wait <= 0 {
+		t.Fatalf("expected a retry time, got %s", wait)
+	}
+}
+
+func TestInMemRateLimiterRefund(t *testing.T) {
+	limiter := NewInMemRateLimiter()
+	if ok, _, _ := limiter.Take("hook", 1, 1); !ok {
+		t.Fatal("expected the first take to pass")
+	}
+	if err := limiter.Refund("hook", 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, _ := limiter.Take("hook", 1, 1); !ok {
+		t.Fatal("expected the refunded token to be taken")
+	}
+	// refunds never fill a bucket past its burst
+	limiter.Refund("hook", 1, 1)
+	limiter.Refund("hook", 1, 1)
+	limiter.Take("hook", 1, 1)
+	if ok, _, _ := limiter.Take("hook", 1, 1); ok {
+		t.Fatal("expected the bucket to hold a single token")
+	}
+}
+
+func TestInMemRateLimiterUnlimited(t *testing.T) {
+	limiter := NewInMemRateLimiter()
+	for i := 0; i < 100; i++ {
+		if ok, _, _ := limiter.Take("hook", 0, 0); !ok {
+			t.Fatal("expected a zero rate to be unlimited")
+		}
+	}
+}
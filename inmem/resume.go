@@ -0,0 +1,153 @@
+package inmem
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+)
+
+type backlogEntry struct {
+	time time.Time
+	hook tunnel.HookCall
+}
+
+type backlog struct {
+	seq     int64
+	acked   int64
+	entries []backlogEntry
+}
+
+type resumeToken struct {
+	state   tunnel.ResumeState
+	expires time.Time
+}
+
+// InMemResumeStore keeps backlogs and resume tokens in process memory.
+// Sessions can only be resumed on the same process, so only use it for
+// single node setups.
+type InMemResumeStore struct {
+	mtx      sync.Mutex
+	backlogs map[user.AccountId]*backlog
+	tokens   map[tunnel.ResumeToken]*resumeToken
+}
+
+func NewInMemResumeStore() tunnel.ResumeStore {
+	return &InMemResumeStore{
+		backlogs: make(map[user.AccountId]*backlog),
+		tokens:   make(map[tunnel.ResumeToken]*resumeToken),
+	}
+}
+
+func (i *InMemResumeStore) Append(accountId user.AccountId, call tunnel.HookCall) (int64, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	b, ok := i.backlogs[accountId]
+	if !ok {
+		b = &backlog{}
+		i.backlogs[accountId] = b
+	}
+	b.seq++
+	call.Seq = b.seq
+	b.entries = append(b.entries, backlogEntry{time: time.Now(), hook: call})
+	if len(b.entries) > tunnel.ResumeBacklog {
+		b.entries = append([]backlogEntry{}, b.entries[len(b.entries)-tunnel.ResumeBacklog:]...)
+	}
+	return b.seq, nil
+}
+
+func (i *InMemResumeStore) Since(accountId user.AccountId, seq int64) ([]tunnel.HookCall, bool, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	calls := []tunnel.HookCall{}
+	b, ok := i.backlogs[accountId]
+	if !ok {
+		return calls, true, nil
+	}
+	for _, entry := range b.entries {
+		if entry.hook.Seq <= seq || time.Since(entry.time) > tunnel.ResumeRetention {
+			continue
+		}
+		calls = append(calls, entry.hook)
+	}
+	if len(calls) > 0 {
+		return calls, calls[0].Seq == seq+1, nil
+	}
+	return calls, b.seq <= seq, nil
+}
+
+func (i *InMemResumeStore) NewToken(state tunnel.ResumeState) (tunnel.ResumeToken, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	i.expireTokens()
+	token := tunnel.ResumeToken(uuid.NewV4().String())
+	i.tokens[token] = &resumeToken{
+		state:   state,
+		expires: time.Now().Add(tunnel.ResumeRetention),
+	}
+	return token, nil
+}
+
+func (i *InMemResumeStore) FindToken(token tunnel.ResumeToken) (*tunnel.ResumeState, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	t, ok := i.tokens[token]
+	if !ok || time.Now().After(t.expires) {
+		return nil, tunnel.ErrResumeExpired
+	}
+	state := t.state
+	return &state, nil
+}
+
+func (i *InMemResumeStore) Touch(token tunnel.ResumeToken) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if t, ok := i.tokens[token]; ok {
+		t.expires = time.Now().Add(tunnel.ResumeRetention)
+	}
+	return nil
+}
+
+func (i *InMemResumeStore) Ack(accountId user.AccountId, seq int64) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	b, ok := i.backlogs[accountId]
+	if !ok {
+		b = &backlog{}
+		i.backlogs[accountId] = b
+	}
+	if seq > b.acked {
+		b.acked = seq
+	}
+	return nil
+}
+
+func (i *InMemResumeStore) Current(accountId user.AccountId) (int64, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if b, ok := i.backlogs[accountId]; ok {
+		return b.seq, nil
+	}
+	return 0, nil
+}
+
+func (i *InMemResumeStore) Acked(accountId user.AccountId) (int64, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if b, ok := i.backlogs[accountId]; ok {
+		return b.acked, nil
+	}
+	return 0, nil
+}
+
+// expireTokens drops expired tokens. Callers hold the lock.
+func (i *InMemResumeStore) expireTokens() {
+	now := time.Now()
+	for token, t := range i.tokens {
+		if now.After(t.expires) {
+			delete(i.tokens, token)
+		}
+	}
+}
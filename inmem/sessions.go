@@ -0,0 +1,66 @@
+package inmem
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+)
+
+type sessionKey struct {
+	instance tunnel.InstanceId
+	id       tunnel.SessionId
+}
+
+type InMemSessionRegistry struct {
+	mtx      sync.Mutex
+	sessions map[user.AccountId]map[sessionKey]*tunnel.SessionInfo
+}
+
+func NewInMemSessionRegistry() tunnel.SessionRegistry {
+	return &InMemSessionRegistry{
+		sessions: make(map[user.AccountId]map[sessionKey]*tunnel.SessionInfo),
+	}
+}
+
+func (i *InMemSessionRegistry) Put(info *tunnel.SessionInfo) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	sessions, ok := i.sessions[info.AccountId]
+	if !ok {
+		sessions = make(map[sessionKey]*tunnel.SessionInfo)
+		i.sessions[info.AccountId] = sessions
+	}
+	stored := *info
+	sessions[sessionKey{info.Instance, info.Id}] = &stored
+	return nil
+}
+
+func (i *InMemSessionRegistry) Remove(info *tunnel.SessionInfo) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	if sessions, ok := i.sessions[info.AccountId]; ok {
+		delete(sessions, sessionKey{info.Instance, info.Id})
+		if len(sessions) == 0 {
+			delete(i.sessions, info.AccountId)
+		}
+	}
+	return nil
+}
+
+func (i *InMemSessionRegistry) FindByAccountId(accountId user.AccountId) (tunnel.SessionInfoList, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	list := tunnel.SessionInfoList{}
+	now := time.Now()
+	for key, info := range i.sessions[accountId] {
+		if now.After(info.Expires) {
+			delete(i.sessions[accountId], key)
+			continue
+		}
+		found := *info
+		list = append(list, &found)
+	}
+	return list, nil
+}
@@ -0,0 +1,49 @@
+package inmem
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/user"
+)
+
+type usageCount struct {
+	slot  int64
+	count int
+}
+
+type InMemUsage struct {
+	mtx    sync.Mutex
+	counts map[string]*usageCount
+}
+
+func NewInMemUsage() user.UsageCounter {
+	return &InMemUsage{
+		counts: make(map[string]*usageCount),
+	}
+}
+
+func (i *InMemUsage) Incr(accountId user.AccountId, key string, window time.Duration) (int, error) {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	slot := time.Now().UnixNano() / int64(window)
+	k := string(accountId) + ":" + key
+	c, ok := i.counts[k]
+	if !ok || c.slot != slot {
+		c = &usageCount{slot: slot}
+		i.counts[k] = c
+	}
+	c.count++
+	return c.count, nil
+}
+
+func (i *InMemUsage) Decr(accountId user.AccountId, key string, window time.Duration) error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+	slot := time.Now().UnixNano() / int64(window)
+	c, ok := i.counts[string(accountId)+":"+key]
+	if ok && c.slot == slot && c.count > 0 {
+		c.count--
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package inmem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gohook/gohook-server/user"
+)
+
+func TestInMemUsageDecr(t *testing.T) {
+	u := NewInMemUsage()
+	account := user.AccountId("account")
+	u.Incr(account, "triggers", time.Hour)
+	u.Incr(account, "triggers", time.Hour)
+	if err := u.Decr(account, "triggers", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := u.Incr(account, "triggers", time.Hour); count != 2 {
+		t.Fatalf("expected the refunded count to be reused, got %d", count)
+	}
+
+	u.Decr(account, "other", time.Hour)
+	if count, _ := u.Incr(account, "other", time.Hour); count != 1 {
+		t.Fatalf("expected a refund without a count to be ignored, got %d", count)
+	}
+}
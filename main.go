@@ -1,25 +1,38 @@
 package main
 
 import (
+	"expvar"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 	"gopkg.in/mgo.v2"
 
+	"github.com/gohook/gohook-server/admin"
+	"github.com/gohook/gohook-server/audit"
 	"github.com/gohook/gohook-server/auth"
+	"github.com/gohook/gohook-server/boltdb"
+	"github.com/gohook/gohook-server/deadletter"
+	"github.com/gohook/gohook-server/delivery"
 	"github.com/gohook/gohook-server/gohookd"
+	"github.com/gohook/gohook-server/inmem"
 	"github.com/gohook/gohook-server/mongo"
 	"github.com/gohook/gohook-server/pb"
 	"github.com/gohook/gohook-server/redis"
 	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
 	"github.com/gohook/gohook-server/webhook"
 )
 
@@ -29,11 +42,26 @@ const (
 	httpServerOrigin = "HTTP_ORIGIN"
 	mongoAddr        = "MONGO_URL"
 	redisAddr        = "REDIS_ADDR"
+	adminToken       = "ADMIN_TOKEN"
+	bufferSize       = "TUNNEL_BUFFER_SIZE"
+	bufferPolicy     = "TUNNEL_BUFFER_POLICY"
+	allowedOrigins   = "TUNNEL_ALLOWED_ORIGINS"
+	ticketSecret     = "TUNNEL_TICKET_SECRET"
+	shutdownTimeout  = "SHUTDOWN_TIMEOUT"
+	queueBackend     = "QUEUE_BACKEND"
+	queueGroup       = "QUEUE_GROUP"
+	queueMaxLen      = "QUEUE_STREAM_MAXLEN"
+	storage          = "STORAGE"
+	dataDir          = "DATA_DIR"
+	accountToken     = "ACCOUNT_TOKEN"
 )
 
 type GohookGRPCServer struct {
 	*gohookd.GohookdServer
 	*tunnel.GohookTunnelServer
+	*audit.AuditServer
+	*tunnel.SessionServer
+	*deadletter.DeadLetterServer
 }
 
 func main() {
@@ -67,35 +95,86 @@ func main() {
 		redisAddr = ":6379"
 	}
 
-	// Setup Stores
-	// Setup Mongo DB connection
-	session, err := mgo.Dial(mongoAddr)
-	if err != nil {
-		panic(err)
-	}
-	defer session.Close()
+	// The admin api is only mounted when a token is configured
+	adminToken := os.Getenv(adminToken)
 
-	hookStore, err := mongo.NewMongoHookStore("gohook", session)
-	if err != nil {
-		panic(err)
+	// Time given to clients and in flight hooks on shutdown
+	timeout := os.Getenv(shutdownTimeout)
+	shutdownTimeout := 30 * time.Second
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			panic(err)
+		}
+		shutdownTimeout = d
 	}
 
-	accountStore := mongo.NewMongoAccountStore("gohook", session)
-
-	// Setup AuthService
-	authService := auth.NewAuthService(accountStore)
+	// Send buffer of every tunnel session
+	buffers := tunnel.DefaultBufferOptions
+	if size := os.Getenv(bufferSize); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			panic(err)
+		}
+		buffers.Size = n
+	}
+	if policy := os.Getenv(bufferPolicy); policy != "" {
+		p, err := tunnel.ParseBufferPolicy(policy)
+		if err != nil {
+			panic(err)
+		}
+		buffers.Policy = p
+	}
 
-	// Setup Queue
-	queue, err := redis.NewRedisQueue(redisAddr)
-	if err != nil {
-		panic(err)
+	// Browser tunnels, the origins of pages besides the server's own
+	// separated by commas, and the secret shared by every process
+	httpTunnel := tunnel.HTTPOptions{
+		TicketSecret: []byte(os.Getenv(ticketSecret)),
+	}
+	if origins := os.Getenv(allowedOrigins); origins != "" {
+		httpTunnel.AllowedOrigins = strings.Split(origins, ",")
 	}
 
-	// Context
-	ctx := context.Background()
+	// Storage, either "mongo" for Mongo and Redis, or "embedded" to
+	// keep everything in a file of the data directory. Embedded
+	// storage is for a single process and ignores the queue backend.
+	storage := os.Getenv(storage)
+	if storage == "" {
+		storage = "mongo"
+	}
+	dataDir := os.Getenv(dataDir)
+	if dataDir == "" {
+		dataDir = "data"
+	}
 
-	// Error chan
-	errc := make(chan error)
+	// Queue backend, either "pubsub", "streams" or "memory". Streams
+	// keep the messages sent while a process is disconnected. The
+	// memory queue only delivers within the process, for single
+	// process setups.
+	queueBackend := os.Getenv(queueBackend)
+	if queueBackend == "" {
+		queueBackend = "pubsub"
+	}
+	// Every process needs a group of its own, under a name that stays
+	// the same when it is replaced so the new process claims the calls
+	// the old one left pending. Groups idle for a day are destroyed.
+	streamOpts := redis.StreamOptions{
+		Group:  os.Getenv(queueGroup),
+		MaxLen: redis.DefaultStreamMaxLen,
+	}
+	if streamOpts.Consumer, _ = os.Hostname(); streamOpts.Consumer == "" {
+		streamOpts.Consumer = string(tunnel.NewInstanceId())
+	}
+	if queueBackend == "streams" && streamOpts.Group == "" {
+		panic(fmt.Errorf("%s is required for the streams queue", queueGroup))
+	}
+	if maxLen := os.Getenv(queueMaxLen); maxLen != "" {
+		n, err := strconv.ParseInt(maxLen, 10, 64)
+		if err != nil {
+			panic(err)
+		}
+		streamOpts.MaxLen = n
+	}
 
 	// Logging domain.
 	var logger log.Logger
@@ -105,19 +184,203 @@ func main() {
 		logger = log.NewContext(logger).With("caller", log.DefaultCaller)
 	}
 
+	// Setup Stores
+	var (
+		hookStore       gohookd.HookStore
+		historyStore    gohookd.HistoryStore
+		auditStore      audit.Store
+		deadLetterStore tunnel.DeadLetterStore
+		accounts        user.AccountStore
+		resumeStore     tunnel.ResumeStore
+		queue           tunnel.HookQueue
+		sessionRegistry tunnel.SessionRegistry
+		presence        tunnel.Presence
+		usageCounter    user.UsageCounter
+		rateLimiter     user.RateLimiter
+		err             error
+	)
+	switch storage {
+	case "mongo":
+		// Setup Mongo DB connection
+		session, err := mgo.Dial(mongoAddr)
+		if err != nil {
+			panic(err)
+		}
+		defer session.Close()
+
+		hookStore, err = mongo.NewMongoHookStore("gohook", session)
+		if err != nil {
+			panic(err)
+		}
+
+		historyStore, err = mongo.NewMongoHistoryStore("gohook", session)
+		if err != nil {
+			panic(err)
+		}
+
+		auditStore, err = mongo.NewMongoAuditStore("gohook", session)
+		if err != nil {
+			panic(err)
+		}
+
+		deadLetterStore, err = mongo.NewMongoDeadLetterStore("gohook", session)
+		if err != nil {
+			panic(err)
+		}
+
+		accounts = mongo.NewMongoAccountStore("gohook", session)
+
+		// Setup Queue
+		resumeStore, err = redis.NewRedisResumeStore(redisAddr)
+		if err != nil {
+			panic(err)
+		}
+
+		switch queueBackend {
+		case "pubsub":
+			queue, err = redis.NewRedisQueue(redisAddr)
+		case "streams":
+			queue, err = redis.NewRedisStreamQueue(redisAddr, streamOpts)
+		case "memory":
+			queue = inmem.NewInMemQueue()
+		default:
+			err = fmt.Errorf("Invalid Queue Backend: %s", queueBackend)
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		sessionRegistry, err = redis.NewRedisSessionRegistry(redisAddr)
+		if err != nil {
+			panic(err)
+		}
+
+		presence, err = redis.NewRedisPresence(redisAddr)
+		if err != nil {
+			panic(err)
+		}
+
+		usageCounter, err = redis.NewRedisUsageCounter(redisAddr)
+		if err != nil {
+			panic(err)
+		}
+
+		rateLimiter, err = redis.NewRedisRateLimiter(redisAddr)
+		if err != nil {
+			panic(err)
+		}
+
+	case "embedded":
+		// Everything that has to survive a restart is kept in the
+		// database file, the rest only matters to this process.
+		db, err := boltdb.Open(dataDir)
+		if err != nil {
+			panic(err)
+		}
+		defer db.Close()
+
+		hookStore, err = boltdb.NewBoltHookStore(db)
+		if err != nil {
+			panic(err)
+		}
+
+		historyStore, err = boltdb.NewBoltHistoryStore(db)
+		if err != nil {
+			panic(err)
+		}
+
+		auditStore, err = boltdb.NewBoltAuditStore(db)
+		if err != nil {
+			panic(err)
+		}
+
+		deadLetterStore, err = boltdb.NewBoltDeadLetterStore(db)
+		if err != nil {
+			panic(err)
+		}
+
+		accounts, err = boltdb.NewBoltAccountStore(db)
+		if err != nil {
+			panic(err)
+		}
+		// there is no api to create accounts, so the first one is
+		// created from the config
+		if token := user.AccountToken(os.Getenv(accountToken)); token != "" {
+			if _, err := accounts.FindByToken(token); err != nil {
+				if err := accounts.Add(&user.Account{Token: token}); err != nil {
+					panic(err)
+				}
+			}
+		}
+
+		resumeStore, err = boltdb.NewBoltResumeStore(db)
+		if err != nil {
+			panic(err)
+		}
+
+		queue, err = boltdb.NewBoltQueue(db)
+		if err != nil {
+			panic(err)
+		}
+
+		sessionRegistry = inmem.NewInMemSessionRegistry()
+		presence = inmem.NewInMemPresence()
+		usageCounter = inmem.NewInMemUsage()
+		rateLimiter = inmem.NewInMemRateLimiter()
+
+	default:
+		panic(fmt.Errorf("Invalid Storage: %s", storage))
+	}
+	queue = tunnel.NewResumableQueue(queue, resumeStore)
+	accountStore := audit.NewAccountStore(accounts, auditStore, logger)
+
+	// Setup AuthService
+	authService := auth.NewAuthService(accountStore)
+
+	// Context
+	ctx := context.Background()
+
+	// Error chan
+	errc := make(chan error)
+
 	// Business domain.
 	var gohookdService gohookd.Service
 	{
-		gohookdService = gohookd.NewBasicService(hookStore, authService, gohookd.WithOrigin(httpServerOrigin))
+		gohookdService = gohookd.NewBasicService(hookStore, authService, queue, gohookd.WithOrigin(httpServerOrigin))
+		gohookdService = gohookd.ServiceAuditMiddleware(auditStore, logger)(gohookdService)
 		gohookdService = gohookd.ServiceLoggingMiddleware(logger)(gohookdService)
 	}
 
+	var auditService audit.Service
+	{
+		auditService = audit.NewBasicService(auditStore)
+		auditService = audit.ServiceLoggingMiddleware(logger)(auditService)
+	}
+
+	var sessionService tunnel.Service
+	{
+		sessionService = tunnel.NewBasicService(sessionRegistry, presence, queue)
+		sessionService = tunnel.ServiceLoggingMiddleware(logger)(sessionService)
+	}
+
+	var deadLetterService deadletter.Service
+	{
+		deadLetterService = deadletter.NewBasicService(deadLetterStore, queue)
+		deadLetterService = deadletter.ServiceLoggingMiddleware(logger)(deadLetterService)
+	}
+
 	var webhookService webhook.Service
 	{
-		webhookService = webhook.NewBasicService(hookStore, queue)
+		webhookService = webhook.NewBasicService(hookStore, queue, historyStore, accountStore, usageCounter, presence)
 		webhookService = webhook.ServiceLoggingMiddleware(logger)(webhookService)
 	}
 
+	var adminService admin.Service
+	{
+		adminService = admin.NewBasicService(accountStore, queue)
+		adminService = admin.ServiceLoggingMiddleware(logger)(adminService)
+	}
+
 	// Endpoint domain.
 	var listEndpoint endpoint.Endpoint
 	{
@@ -143,45 +406,211 @@ func main() {
 		deleteEndpoint = gohookd.EndpointLoggingMiddleware(deleteLogger)(deleteEndpoint)
 	}
 
+	var auditLogEndpoint endpoint.Endpoint
+	{
+		auditLogLogger := log.NewContext(logger).With("method", "AuditLog")
+		auditLogEndpoint = audit.MakeAuditLogEndpoint(auditService)
+		auditLogEndpoint = gohookd.EndpointAuthMiddleware(auditLogLogger, authService)(auditLogEndpoint)
+		auditLogEndpoint = gohookd.EndpointLoggingMiddleware(auditLogLogger)(auditLogEndpoint)
+	}
+
+	var listSessionsEndpoint endpoint.Endpoint
+	{
+		listSessionsLogger := log.NewContext(logger).With("method", "ListSessions")
+		listSessionsEndpoint = tunnel.MakeListSessionsEndpoint(sessionService)
+		listSessionsEndpoint = gohookd.EndpointAuthMiddleware(listSessionsLogger, authService)(listSessionsEndpoint)
+		listSessionsEndpoint = gohookd.EndpointLoggingMiddleware(listSessionsLogger)(listSessionsEndpoint)
+	}
+
+	var kickSessionEndpoint endpoint.Endpoint
+	{
+		kickSessionLogger := log.NewContext(logger).With("method", "KickSession")
+		kickSessionEndpoint = tunnel.MakeKickSessionEndpoint(sessionService)
+		kickSessionEndpoint = gohookd.EndpointAuthMiddleware(kickSessionLogger, authService)(kickSessionEndpoint)
+		kickSessionEndpoint = gohookd.EndpointLoggingMiddleware(kickSessionLogger)(kickSessionEndpoint)
+	}
+
+	var presenceEndpoint endpoint.Endpoint
+	{
+		presenceLogger := log.NewContext(logger).With("method", "Presence")
+		presenceEndpoint = tunnel.MakePresenceEndpoint(sessionService)
+		presenceEndpoint = gohookd.EndpointAuthMiddleware(presenceLogger, authService)(presenceEndpoint)
+		presenceEndpoint = gohookd.EndpointLoggingMiddleware(presenceLogger)(presenceEndpoint)
+	}
+
+	var listDeadLettersEndpoint endpoint.Endpoint
+	{
+		listDeadLettersLogger := log.NewContext(logger).With("method", "ListDeadLetters")
+		listDeadLettersEndpoint = deadletter.MakeListDeadLettersEndpoint(deadLetterService)
+		listDeadLettersEndpoint = gohookd.EndpointAuthMiddleware(listDeadLettersLogger, authService)(listDeadLettersEndpoint)
+		listDeadLettersEndpoint = gohookd.EndpointLoggingMiddleware(listDeadLettersLogger)(listDeadLettersEndpoint)
+	}
+
+	var getDeadLetterEndpoint endpoint.Endpoint
+	{
+		getDeadLetterLogger := log.NewContext(logger).With("method", "GetDeadLetter")
+		getDeadLetterEndpoint = deadletter.MakeGetDeadLetterEndpoint(deadLetterService)
+		getDeadLetterEndpoint = gohookd.EndpointAuthMiddleware(getDeadLetterLogger, authService)(getDeadLetterEndpoint)
+		getDeadLetterEndpoint = gohookd.EndpointLoggingMiddleware(getDeadLetterLogger)(getDeadLetterEndpoint)
+	}
+
+	var requeueDeadLetterEndpoint endpoint.Endpoint
+	{
+		requeueDeadLetterLogger := log.NewContext(logger).With("method", "RequeueDeadLetter")
+		requeueDeadLetterEndpoint = deadletter.MakeRequeueDeadLetterEndpoint(deadLetterService)
+		requeueDeadLetterEndpoint = gohookd.EndpointAuthMiddleware(requeueDeadLetterLogger, authService)(requeueDeadLetterEndpoint)
+		requeueDeadLetterEndpoint = gohookd.EndpointLoggingMiddleware(requeueDeadLetterLogger)(requeueDeadLetterEndpoint)
+	}
+
+	var purgeDeadLettersEndpoint endpoint.Endpoint
+	{
+		purgeDeadLettersLogger := log.NewContext(logger).With("method", "PurgeDeadLetters")
+		purgeDeadLettersEndpoint = deadletter.MakePurgeDeadLettersEndpoint(deadLetterService)
+		purgeDeadLettersEndpoint = gohookd.EndpointAuthMiddleware(purgeDeadLettersLogger, authService)(purgeDeadLettersEndpoint)
+		purgeDeadLettersEndpoint = gohookd.EndpointLoggingMiddleware(purgeDeadLettersLogger)(purgeDeadLettersEndpoint)
+	}
+
 	var triggerEndpoint endpoint.Endpoint
 	{
 		triggerLogger := log.NewContext(logger).With("method", "Trigger")
 		triggerEndpoint = webhook.MakeTriggerEndpoint(webhookService)
+		triggerEndpoint = webhook.EndpointRateLimitMiddleware(accountStore, rateLimiter, historyStore)(triggerEndpoint)
+		triggerEndpoint = webhook.EndpointAccessMiddleware(hookStore, accountStore, historyStore)(triggerEndpoint)
 		triggerEndpoint = webhook.EndpointLoggingMiddleware(triggerLogger)(triggerEndpoint)
 	}
 
-	// Interrupt handler
-	go func() {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		errc <- fmt.Errorf("%s", <-c)
-	}()
+	var suspendEndpoint endpoint.Endpoint
+	{
+		suspendLogger := log.NewContext(logger).With("method", "Suspend")
+		suspendEndpoint = admin.MakeSuspendEndpoint(adminService)
+		suspendEndpoint = admin.EndpointAuthMiddleware(suspendLogger, adminToken)(suspendEndpoint)
+		suspendEndpoint = admin.EndpointLoggingMiddleware(suspendLogger)(suspendEndpoint)
+	}
+
+	var resumeEndpoint endpoint.Endpoint
+	{
+		resumeLogger := log.NewContext(logger).With("method", "Resume")
+		resumeEndpoint = admin.MakeResumeEndpoint(adminService)
+		resumeEndpoint = admin.EndpointAuthMiddleware(resumeLogger, adminToken)(resumeEndpoint)
+		resumeEndpoint = admin.EndpointLoggingMiddleware(resumeLogger)(resumeEndpoint)
+	}
+
+	var revokeTokenEndpoint endpoint.Endpoint
+	{
+		revokeTokenLogger := log.NewContext(logger).With("method", "RevokeToken")
+		revokeTokenEndpoint = admin.MakeRevokeTokenEndpoint(adminService)
+		revokeTokenEndpoint = admin.EndpointAuthMiddleware(revokeTokenLogger, adminToken)(revokeTokenEndpoint)
+		revokeTokenEndpoint = admin.EndpointLoggingMiddleware(revokeTokenLogger)(revokeTokenEndpoint)
+	}
+
+	var announceEndpoint endpoint.Endpoint
+	{
+		announceLogger := log.NewContext(logger).With("method", "Announce")
+		announceEndpoint = admin.MakeAnnounceEndpoint(adminService)
+		announceEndpoint = admin.EndpointAuthMiddleware(announceLogger, adminToken)(announceEndpoint)
+		announceEndpoint = admin.EndpointLoggingMiddleware(announceLogger)(announceEndpoint)
+	}
+
+	// Calls dropped because they expired go to the hook history
+	expiryRecorder := webhook.NewExpiryRecorder(historyStore)
+
+	// The tunnel is served over gRPC, WebSocket and SSE
+	var tunnelServer *tunnel.GohookTunnelServer
+	{
+		tunnelServer, err = tunnel.MakeTunnelServer(authService, queue, resumeStore, sessionRegistry, presence, deadLetterStore, expiryRecorder, buffers, logger)
+		if err != nil {
+			panic(err)
+		}
+		expvar.Publish("tunnel_buffer_depths", expvar.Func(tunnelServer.BufferDepths))
+	}
+
+	// Polling clients are woken up by the tunnel server
+	var deliveryService delivery.Service
+	{
+		deliveryService = delivery.NewBasicService(resumeStore, tunnelServer, expiryRecorder, logger)
+		deliveryService = delivery.ServiceLoggingMiddleware(logger)(deliveryService)
+	}
+
+	var pollEndpoint endpoint.Endpoint
+	{
+		pollLogger := log.NewContext(logger).With("method", "Poll")
+		pollEndpoint = delivery.MakePollEndpoint(deliveryService)
+		pollEndpoint = delivery.EndpointAuthMiddleware(pollLogger, authService)(pollEndpoint)
+		pollEndpoint = delivery.EndpointLoggingMiddleware(pollLogger)(pollEndpoint)
+	}
+
+	var ackEndpoint endpoint.Endpoint
+	{
+		ackLogger := log.NewContext(logger).With("method", "Ack")
+		ackEndpoint = delivery.MakeAckEndpoint(deliveryService)
+		ackEndpoint = delivery.EndpointAuthMiddleware(ackLogger, authService)(ackEndpoint)
+		ackEndpoint = delivery.EndpointLoggingMiddleware(ackLogger)(ackEndpoint)
+	}
 
 	// HTTP transport
-	go func() {
+	var httpServer *http.Server
+	{
 		var webhooks http.Handler
 		{
 			endpoints := webhook.Endpoints{
 				TriggerEndpoint: triggerEndpoint,
 			}
 			logger := log.NewContext(logger).With("transport", "HTTP")
-			webhooks = webhook.MakeWebhookHTTPServer(ctx, endpoints, logger, httpServerOrigin)
+			webhooks = webhook.MakeWebhookHTTPServer(ctx, endpoints, accountStore, logger, httpServerOrigin)
 		}
 
-		logger.Log("msg", "HTTP Server Started", "port", port)
-		errc <- http.ListenAndServe(":"+port, webhooks)
-	}()
+		handler := http.NewServeMux()
+		if adminToken != "" {
+			endpoints := admin.Endpoints{
+				SuspendEndpoint:     suspendEndpoint,
+				ResumeEndpoint:      resumeEndpoint,
+				RevokeTokenEndpoint: revokeTokenEndpoint,
+				AnnounceEndpoint:    announceEndpoint,
+			}
+			logger := log.NewContext(logger).With("transport", "HTTP")
+			handler.Handle("/admin/", admin.MakeAdminHTTPServer(ctx, endpoints, logger))
+			handler.Handle("/admin/debug/vars", admin.RequireToken(adminToken, expvar.Handler()))
+		}
+		{
+			logger := log.NewContext(logger).With("transport", "HTTP")
+			tunnelHandler, err := tunnel.MakeTunnelHTTPHandler(tunnelServer, httpTunnel, logger)
+			if err != nil {
+				panic(err)
+			}
+			handler.Handle("/tunnel/", tunnelHandler)
+			handler.Handle("/healthz", tunnel.MakeHealthHandler(tunnelServer))
+		}
+		{
+			endpoints := delivery.Endpoints{
+				PollEndpoint: pollEndpoint,
+				AckEndpoint:  ackEndpoint,
+			}
+			logger := log.NewContext(logger).With("transport", "HTTP")
+			handler.Handle("/v1/", delivery.MakeDeliveryHTTPServer(ctx, endpoints, logger))
+		}
+		handler.Handle("/", webhooks)
 
-	// gRPC transport
-	go func() {
-		lis, err := net.Listen("tcp", ":"+gRPCPort)
-		if err != nil {
-			errc <- err
-			return
+		httpServer = &http.Server{
+			Addr:    ":" + port,
+			Handler: handler,
 		}
-		defer lis.Close()
+	}
 
-		s := grpc.NewServer()
+	// gRPC transport
+	var grpcServer *grpc.Server
+	{
+		grpcServer = grpc.NewServer(
+			// Ping idle connections so dead peers are dropped even when
+			// the tunnel heartbeat is not used.
+			grpc.KeepaliveParams(keepalive.ServerParameters{
+				Time:    2 * time.Minute,
+				Timeout: 20 * time.Second,
+			}),
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             30 * time.Second,
+				PermitWithoutStream: true,
+			}),
+		)
 
 		// Mechanical domain.
 		var gohook pb.GohookServer
@@ -193,23 +622,97 @@ func main() {
 			}
 			logger := log.NewContext(logger).With("transport", "gRPC")
 			g := gohookd.MakeGohookdServer(ctx, endpoints, logger)
-			t, err := tunnel.MakeTunnelServer(authService, queue, logger)
-			if err != nil {
-				errc <- err
-				return
-			}
+			a := audit.MakeAuditServer(ctx, audit.Endpoints{
+				AuditLogEndpoint: auditLogEndpoint,
+			}, logger)
+			ss := tunnel.MakeSessionServer(ctx, tunnel.Endpoints{
+				ListSessionsEndpoint: listSessionsEndpoint,
+				KickSessionEndpoint:  kickSessionEndpoint,
+				PresenceEndpoint:     presenceEndpoint,
+			}, logger)
+			d := deadletter.MakeDeadLetterServer(ctx, deadletter.Endpoints{
+				ListDeadLettersEndpoint:   listDeadLettersEndpoint,
+				GetDeadLetterEndpoint:     getDeadLetterEndpoint,
+				RequeueDeadLetterEndpoint: requeueDeadLetterEndpoint,
+				PurgeDeadLettersEndpoint:  purgeDeadLettersEndpoint,
+			}, logger)
 
 			gohook = &GohookGRPCServer{
-				GohookTunnelServer: t,
+				GohookTunnelServer: tunnelServer,
 				GohookdServer:      g,
+				AuditServer:        a,
+				SessionServer:      ss,
+				DeadLetterServer:   d,
 			}
 		}
 
-		pb.RegisterGohookServer(s, gohook)
+		pb.RegisterGohookServer(grpcServer, gohook)
+	}
+
+	// Interrupt handler
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		errc <- fmt.Errorf("%s", <-c)
+	}()
+
+	go func() {
+		logger.Log("msg", "HTTP Server Started", "port", port)
+		errc <- httpServer.ListenAndServe()
+	}()
+
+	go func() {
+		lis, err := net.Listen("tcp", ":"+gRPCPort)
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer lis.Close()
 
 		logger.Log("msg", "GRPC Server Started", "port", gRPCPort)
-		errc <- s.Serve(lis)
+		errc <- grpcServer.Serve(lis)
 	}()
 
-	logger.Log("exit", <-errc)
+	logger.Log("msg", "Shutting down", "reason", <-errc, "timeout", shutdownTimeout)
+
+	// Stop taking hooks and tunnels, and move the connected clients
+	// to other instances before the deadline. The HTTP server does not
+	// wait for WebSocket tunnels, the tunnel drain does.
+	shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Log("msg", "HTTP Server shutdown", "err", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := tunnelServer.Drain(shutdownCtx); err != nil {
+			logger.Log("msg", "Tunnel drain", "err", err)
+		}
+		stopGRPC(shutdownCtx, grpcServer)
+	}()
+	wg.Wait()
+
+	logger.Log("exit", "Shutdown complete")
+}
+
+// stopGRPC waits for the running calls to finish, and stops the
+// server when the deadline is hit first.
+func stopGRPC(ctx context.Context, s *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.Stop()
+	}
 }
@@ -28,18 +28,31 @@ func (d *MongoAccountStore) Add(u *user.Account) error {
 	c := sess.DB(d.db).C(AccountDoc)
 
 	id := bson.NewObjectId()
-	u.Id = user.AccountId(id.String())
+	u.Id = user.AccountId(id.Hex())
 	_, err := c.UpsertId(id, bson.M{"$set": u})
 	return err
 }
 
+func (d *MongoAccountStore) Update(u *user.Account) error {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(AccountDoc)
+
+	err := c.Update(bson.M{"id": u.Id}, bson.M{"$set": u})
+	if err == mgo.ErrNotFound {
+		return errors.New("Not Found")
+	}
+	return err
+}
+
 func (d *MongoAccountStore) Remove(id user.AccountId) (*user.Account, error) {
 	sess := d.session.Copy()
 	defer sess.Close()
 
 	c := sess.DB(d.db).C(AccountDoc)
 
-	err := c.Remove(bson.M{"_id": id})
+	err := c.Remove(bson.M{"id": id})
 	if err == mgo.ErrNotFound {
 		return nil, errors.New("Not Found")
 	}
@@ -54,7 +67,7 @@ func (d *MongoAccountStore) Find(id user.AccountId) (*user.Account, error) {
 	c := sess.DB(d.db).C(AccountDoc)
 
 	var result user.Account
-	err := c.Find(bson.M{"_id": id}).One(&result)
+	err := c.Find(bson.M{"id": id}).One(&result)
 	if err != nil {
 		if err == mgo.ErrNotFound {
 			return nil, errors.New("Not Found")
@@ -0,0 +1,64 @@
+package mongo
+
+import (
+	"github.com/gohook/gohook-server/audit"
+	"github.com/gohook/gohook-server/user"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const AuditDoc = "audit"
+
+type MongoAuditStore struct {
+	db      string
+	session *mgo.Session
+}
+
+func NewMongoAuditStore(db string, session *mgo.Session) (audit.Store, error) {
+	d := &MongoAuditStore{
+		db:      db,
+		session: session,
+	}
+
+	index := mgo.Index{
+		Key:        []string{"accountid", "-time"},
+		Background: true,
+	}
+
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(AuditDoc)
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Add inserts a new entry. Entries are never updated or removed.
+func (d *MongoAuditStore) Add(e *audit.Entry) error {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(AuditDoc)
+
+	e.Id = audit.EntryID(bson.NewObjectId().Hex())
+	return c.Insert(e)
+}
+
+func (d *MongoAuditStore) FindByAccount(accountId user.AccountId) (audit.EntryList, error) {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(AuditDoc)
+
+	var result audit.EntryList
+	err := c.Find(bson.M{"accountid": accountId}).Sort("-time").All(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
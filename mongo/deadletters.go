@@ -0,0 +1,123 @@
+package mongo
+
+import (
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const DeadLetterDoc = "deadletter"
+
+type MongoDeadLetterStore struct {
+	db      string
+	session *mgo.Session
+}
+
+func NewMongoDeadLetterStore(db string, session *mgo.Session) (tunnel.DeadLetterStore, error) {
+	d := &MongoDeadLetterStore{
+		db:      db,
+		session: session,
+	}
+
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(DeadLetterDoc)
+
+	if err := c.EnsureIndex(mgo.Index{
+		Key:        []string{"accountid", "-time"},
+		Background: true,
+	}); err != nil {
+		return nil, err
+	}
+	// mongo removes the letters once they are past retention
+	if err := c.EnsureIndex(mgo.Index{
+		Key:         []string{"time"},
+		Background:  true,
+		ExpireAfter: tunnel.DeadLetterRetention,
+	}); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *MongoDeadLetterStore) Add(l *tunnel.DeadLetter) error {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(DeadLetterDoc)
+
+	l.Id = tunnel.DeadLetterId(bson.NewObjectId().Hex())
+	return c.Insert(l)
+}
+
+func (d *MongoDeadLetterStore) FindByAccount(accountId user.AccountId) (tunnel.DeadLetterList, error) {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(DeadLetterDoc)
+
+	var result tunnel.DeadLetterList
+	err := c.Find(bson.M{"accountid": accountId}).Sort("-time").All(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (d *MongoDeadLetterStore) Find(accountId user.AccountId, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(DeadLetterDoc)
+
+	var result tunnel.DeadLetter
+	err := c.Find(bson.M{"id": id, "accountid": accountId}).One(&result)
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, tunnel.ErrDeadLetterNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (d *MongoDeadLetterStore) Remove(accountId user.AccountId, id tunnel.DeadLetterId) (*tunnel.DeadLetter, error) {
+	letter, err := d.Find(accountId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(DeadLetterDoc)
+
+	err = c.Remove(bson.M{"id": id, "accountid": accountId})
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, tunnel.ErrDeadLetterNotFound
+		}
+		return nil, err
+	}
+
+	return letter, nil
+}
+
+func (d *MongoDeadLetterStore) Purge(accountId user.AccountId) (int, error) {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(DeadLetterDoc)
+
+	info, err := c.RemoveAll(bson.M{"accountid": accountId})
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Removed, nil
+}
@@ -0,0 +1,90 @@
+package mongo
+
+import (
+	"github.com/gohook/gohook-server/gohookd"
+	"github.com/gohook/gohook-server/user"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const HistoryDoc = "history"
+
+type MongoHistoryStore struct {
+	db        string
+	session   *mgo.Session
+	accountId user.AccountId
+	scoped    bool
+}
+
+func NewMongoHistoryStore(db string, session *mgo.Session) (gohookd.HistoryStore, error) {
+	d := &MongoHistoryStore{
+		db:      db,
+		session: session,
+		scoped:  false,
+	}
+
+	index := mgo.Index{
+		Key:        []string{"accountid", "hookid", "-time"},
+		Background: true,
+	}
+
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(HistoryDoc)
+
+	if err := c.EnsureIndex(index); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d MongoHistoryStore) Scope(accountId user.AccountId) gohookd.HistoryStore {
+	d.accountId = accountId
+	d.scoped = true
+	return &d
+}
+
+func (d *MongoHistoryStore) Add(m *gohookd.Delivery) error {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	if d.scoped {
+		m.AccountId = d.accountId
+	}
+
+	c := sess.DB(d.db).C(HistoryDoc)
+
+	id := bson.NewObjectId()
+	m.Id = gohookd.DeliveryID(id.Hex())
+	_, err := c.UpsertId(id, bson.M{"$set": m})
+	return err
+}
+
+func (d *MongoHistoryStore) FindAll() (gohookd.DeliveryList, error) {
+	return d.find(bson.M{})
+}
+
+func (d *MongoHistoryStore) FindByHook(hookId gohookd.HookID) (gohookd.DeliveryList, error) {
+	return d.find(bson.M{"hookid": hookId})
+}
+
+func (d *MongoHistoryStore) find(q bson.M) (gohookd.DeliveryList, error) {
+	sess := d.session.Copy()
+	defer sess.Close()
+
+	c := sess.DB(d.db).C(HistoryDoc)
+
+	if d.scoped {
+		q["accountid"] = d.accountId
+	}
+
+	var result gohookd.DeliveryList
+	err := c.Find(q).Sort("-time").All(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
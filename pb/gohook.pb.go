@@ -9,9 +9,23 @@ It is generated from these files:
 	gohook.proto
 
 It has these top-level messages:
+	HookAuth
+	AccessPolicy
 	Hook
 	HookRequest
 	HookCall
+	HookBatch
+	Subscription
+	TunnelOpen
+	TunnelReady
+	GoingAway
+	HookEvent
+	SessionKicked
+	QuotaWarning
+	TokenRevoked
+	Announcement
+	Ping
+	Pong
 	TunnelRequest
 	TunnelResponse
 	ListRequest
@@ -20,6 +34,28 @@ It has these top-level messages:
 	CreateResponse
 	DeleteRequest
 	DeleteResponse
+	AuditEntry
+	AuditLogRequest
+	AuditLogResponse
+	Session
+	ListSessionsRequest
+	ListSessionsResponse
+	KickSessionRequest
+	KickSessionResponse
+	PresenceRequest
+	PresenceResponse
+	DeadLetter
+	ListDeadLettersRequest
+	ListDeadLettersResponse
+	GetDeadLetterRequest
+	GetDeadLetterResponse
+	RequeueDeadLetterRequest
+	RequeueDeadLetterResponse
+	PurgeDeadLettersRequest
+	PurgeDeadLettersResponse
+	QueueHookCall
+	QueueEvent
+	QueueEnvelope
 */
 package pb
 
@@ -77,60 +113,701 @@ func (x Method) String() string {
 }
 func (Method) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+// AuthType defines the credentials a caller has to present when calling a webhook.
+type AuthType int32
+
+const (
+	AuthType_AUTH_NONE   AuthType = 0
+	AuthType_AUTH_BASIC  AuthType = 1
+	AuthType_AUTH_BEARER AuthType = 2
+)
+
+var AuthType_name = map[int32]string{
+	0: "AUTH_NONE",
+	1: "AUTH_BASIC",
+	2: "AUTH_BEARER",
+}
+var AuthType_value = map[string]int32{
+	"AUTH_NONE":   0,
+	"AUTH_BASIC":  1,
+	"AUTH_BEARER": 2,
+}
+
+func (x AuthType) String() string {
+	return proto.EnumName(AuthType_name, int32(x))
+}
+func (AuthType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+// Encoding of a hook call body.
+type Encoding int32
+
+const (
+	Encoding_ENCODING_IDENTITY Encoding = 0
+	Encoding_ENCODING_GZIP     Encoding = 1
+)
+
+var Encoding_name = map[int32]string{
+	0: "ENCODING_IDENTITY",
+	1: "ENCODING_GZIP",
+}
+var Encoding_value = map[string]int32{
+	"ENCODING_IDENTITY": 0,
+	"ENCODING_GZIP":     1,
+}
+
+func (x Encoding) String() string {
+	return proto.EnumName(Encoding_name, int32(x))
+}
+func (Encoding) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+// OfflinePolicy defines how a call to a webhook is answered when no
+// client of the account is connected.
+type OfflinePolicy int32
+
+const (
+	// Accept the call like any other.
+	OfflinePolicy_OFFLINE_ACCEPT OfflinePolicy = 0
+	// Accept the call with 202 Accepted and keep it for a client to resume.
+	OfflinePolicy_OFFLINE_QUEUE OfflinePolicy = 1
+	// Answer 503 Service Unavailable with a Retry-After header.
+	OfflinePolicy_OFFLINE_REJECT OfflinePolicy = 2
+)
+
+var OfflinePolicy_name = map[int32]string{
+	0: "OFFLINE_ACCEPT",
+	1: "OFFLINE_QUEUE",
+	2: "OFFLINE_REJECT",
+}
+var OfflinePolicy_value = map[string]int32{
+	"OFFLINE_ACCEPT": 0,
+	"OFFLINE_QUEUE":  1,
+	"OFFLINE_REJECT": 2,
+}
+
+func (x OfflinePolicy) String() string {
+	return proto.EnumName(OfflinePolicy_name, int32(x))
+}
+func (OfflinePolicy) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+// HookEventType is the kind of change a HookEvent reports.
+type HookEventType int32
+
+const (
+	HookEventType_HOOK_EVENT_UNKNOWN HookEventType = 0
+	HookEventType_HOOK_CREATED       HookEventType = 1
+	HookEventType_HOOK_UPDATED       HookEventType = 2
+	HookEventType_HOOK_DELETED       HookEventType = 3
+	HookEventType_HOOK_EXPIRED       HookEventType = 4
+)
+
+var HookEventType_name = map[int32]string{
+	0: "HOOK_EVENT_UNKNOWN",
+	1: "HOOK_CREATED",
+	2: "HOOK_UPDATED",
+	3: "HOOK_DELETED",
+	4: "HOOK_EXPIRED",
+}
+var HookEventType_value = map[string]int32{
+	"HOOK_EVENT_UNKNOWN": 0,
+	"HOOK_CREATED":       1,
+	"HOOK_UPDATED":       2,
+	"HOOK_DELETED":       3,
+	"HOOK_EXPIRED":       4,
+}
+
+func (x HookEventType) String() string {
+	return proto.EnumName(HookEventType_name, int32(x))
+}
+func (HookEventType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+// QueueMessageType is the kind of a QueueEnvelope.
+type QueueMessageType int32
+
+const (
+	QueueMessageType_QUEUE_UNKNOWN QueueMessageType = 0
+	// Carries a hook call to the sessions of an account.
+	QueueMessageType_QUEUE_HOOK QueueMessageType = 1
+	// Closes every session of an account.
+	QueueMessageType_QUEUE_DISCONNECT QueueMessageType = 2
+	// Closes a single session of an account.
+	QueueMessageType_QUEUE_KICK QueueMessageType = 3
+	// Sends an event to the sessions of an account, or to every session
+	// when the account id is empty.
+	QueueMessageType_QUEUE_EVENT QueueMessageType = 4
+)
+
+var QueueMessageType_name = map[int32]string{
+	0: "QUEUE_UNKNOWN",
+	1: "QUEUE_HOOK",
+	2: "QUEUE_DISCONNECT",
+	3: "QUEUE_KICK",
+	4: "QUEUE_EVENT",
+}
+var QueueMessageType_value = map[string]int32{
+	"QUEUE_UNKNOWN":    0,
+	"QUEUE_HOOK":       1,
+	"QUEUE_DISCONNECT": 2,
+	"QUEUE_KICK":       3,
+	"QUEUE_EVENT":      4,
+}
+
+func (x QueueMessageType) String() string {
+	return proto.EnumName(QueueMessageType_name, int32(x))
+}
+func (QueueMessageType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+// QueueEventType is the kind of a QueueEvent.
+type QueueEventType int32
+
+const (
+	QueueEventType_QUEUE_EVENT_UNKNOWN        QueueEventType = 0
+	QueueEventType_QUEUE_EVENT_HOOK_CREATED   QueueEventType = 1
+	QueueEventType_QUEUE_EVENT_HOOK_UPDATED   QueueEventType = 2
+	QueueEventType_QUEUE_EVENT_HOOK_DELETED   QueueEventType = 3
+	QueueEventType_QUEUE_EVENT_HOOK_EXPIRED   QueueEventType = 4
+	QueueEventType_QUEUE_EVENT_SESSION_KICKED QueueEventType = 5
+	QueueEventType_QUEUE_EVENT_QUOTA_WARNING  QueueEventType = 6
+	QueueEventType_QUEUE_EVENT_TOKEN_REVOKED  QueueEventType = 7
+	QueueEventType_QUEUE_EVENT_ANNOUNCEMENT   QueueEventType = 8
+)
+
+var QueueEventType_name = map[int32]string{
+	0: "QUEUE_EVENT_UNKNOWN",
+	1: "QUEUE_EVENT_HOOK_CREATED",
+	2: "QUEUE_EVENT_HOOK_UPDATED",
+	3: "QUEUE_EVENT_HOOK_DELETED",
+	4: "QUEUE_EVENT_HOOK_EXPIRED",
+	5: "QUEUE_EVENT_SESSION_KICKED",
+	6: "QUEUE_EVENT_QUOTA_WARNING",
+	7: "QUEUE_EVENT_TOKEN_REVOKED",
+	8: "QUEUE_EVENT_ANNOUNCEMENT",
+}
+var QueueEventType_value = map[string]int32{
+	"QUEUE_EVENT_UNKNOWN":        0,
+	"QUEUE_EVENT_HOOK_CREATED":   1,
+	"QUEUE_EVENT_HOOK_UPDATED":   2,
+	"QUEUE_EVENT_HOOK_DELETED":   3,
+	"QUEUE_EVENT_HOOK_EXPIRED":   4,
+	"QUEUE_EVENT_SESSION_KICKED": 5,
+	"QUEUE_EVENT_QUOTA_WARNING":  6,
+	"QUEUE_EVENT_TOKEN_REVOKED":  7,
+	"QUEUE_EVENT_ANNOUNCEMENT":   8,
+}
+
+func (x QueueEventType) String() string {
+	return proto.EnumName(QueueEventType_name, int32(x))
+}
+func (QueueEventType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+// HookAuth defines the credentials required to call a webhook. Secrets
+// are only set in requests and are never returned by the server.
+type HookAuth struct {
+	Type     AuthType `protobuf:"varint,1,opt,name=type,enum=pb.AuthType" json:"type,omitempty"`
+	Username string   `protobuf:"bytes,2,opt,name=username" json:"username,omitempty"`
+	Password string   `protobuf:"bytes,3,opt,name=password" json:"password,omitempty"`
+	Token    string   `protobuf:"bytes,4,opt,name=token" json:"token,omitempty"`
+}
+
+func (m *HookAuth) Reset()                    { *m = HookAuth{} }
+func (m *HookAuth) String() string            { return proto.CompactTextString(m) }
+func (*HookAuth) ProtoMessage()               {}
+func (*HookAuth) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+// AccessPolicy restricts which callers are allowed to trigger a webhook.
+type AccessPolicy struct {
+	// Networks in CIDR notation that are allowed to call the webhook. An
+	// empty list allows every address.
+	AllowedCidrs []string `protobuf:"bytes,1,rep,name=allowed_cidrs,json=allowedCidrs" json:"allowed_cidrs,omitempty"`
+	// Number of proxies in front of the server whose X-Forwarded-For
+	// entries are trusted when resolving the caller address.
+	TrustedProxies int32     `protobuf:"varint,2,opt,name=trusted_proxies,json=trustedProxies" json:"trusted_proxies,omitempty"`
+	Auth           *HookAuth `protobuf:"bytes,3,opt,name=auth" json:"auth,omitempty"`
+}
+
+func (m *AccessPolicy) Reset()                    { *m = AccessPolicy{} }
+func (m *AccessPolicy) String() string            { return proto.CompactTextString(m) }
+func (*AccessPolicy) ProtoMessage()               {}
+func (*AccessPolicy) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *AccessPolicy) GetAuth() *HookAuth {
+	if m != nil {
+		return m.Auth
+	}
+	return nil
+}
+
 // Hook defines the response of a webhook when received from the server.
 type Hook struct {
-	Id     string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
-	Url    string `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
-	Method Method `protobuf:"varint,3,opt,name=method,enum=pb.Method" json:"method,omitempty"`
+	Id       string            `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Url      string            `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
+	Method   Method            `protobuf:"varint,3,opt,name=method,enum=pb.Method" json:"method,omitempty"`
+	Access   *AccessPolicy     `protobuf:"bytes,4,opt,name=access" json:"access,omitempty"`
+	Labels   map[string]string `protobuf:"bytes,5,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Offline  OfflinePolicy     `protobuf:"varint,6,opt,name=offline,enum=pb.OfflinePolicy" json:"offline,omitempty"`
+	TtlMs    int64             `protobuf:"varint,7,opt,name=ttl_ms,json=ttlMs" json:"ttl_ms,omitempty"`
+	DelayMs  int64             `protobuf:"varint,8,opt,name=delay_ms,json=delayMs" json:"delay_ms,omitempty"`
+	Priority int32             `protobuf:"varint,9,opt,name=priority" json:"priority,omitempty"`
 }
 
 func (m *Hook) Reset()                    { *m = Hook{} }
 func (m *Hook) String() string            { return proto.CompactTextString(m) }
 func (*Hook) ProtoMessage()               {}
-func (*Hook) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+func (*Hook) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *Hook) GetAccess() *AccessPolicy {
+	if m != nil {
+		return m.Access
+	}
+	return nil
+}
+
+func (m *Hook) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
 
 // HookRequest defines the request format when setting up a new webhook on the server.
 type HookRequest struct {
 	// Only a method is required when setting up a new webhook. The server
 	// will set the id and return that in the response.
 	Method Method `protobuf:"varint,1,opt,name=method,enum=pb.Method" json:"method,omitempty"`
+	// Optional access restrictions for the webhook.
+	Access *AccessPolicy `protobuf:"bytes,2,opt,name=access" json:"access,omitempty"`
+	// Optional labels tunnel sessions can subscribe to.
+	Labels  map[string]string `protobuf:"bytes,3,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Offline OfflinePolicy     `protobuf:"varint,4,opt,name=offline,enum=pb.OfflinePolicy" json:"offline,omitempty"`
+	// Optional time after which undelivered calls are dropped.
+	TtlMs int64 `protobuf:"varint,5,opt,name=ttl_ms,json=ttlMs" json:"ttl_ms,omitempty"`
+	// Optional time calls are held back before they are delivered.
+	DelayMs int64 `protobuf:"varint,6,opt,name=delay_ms,json=delayMs" json:"delay_ms,omitempty"`
+	// Calls of a higher priority are sent ahead of waiting calls.
+	Priority int32 `protobuf:"varint,7,opt,name=priority" json:"priority,omitempty"`
 }
 
 func (m *HookRequest) Reset()                    { *m = HookRequest{} }
 func (m *HookRequest) String() string            { return proto.CompactTextString(m) }
 func (*HookRequest) ProtoMessage()               {}
-func (*HookRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*HookRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *HookRequest) GetAccess() *AccessPolicy {
+	if m != nil {
+		return m.Access
+	}
+	return nil
+}
+
+func (m *HookRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
 
 // HookCall defines the message format when receiving a hook from the tunnel.
 type HookCall struct {
 	Id     string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	Method Method `protobuf:"varint,2,opt,name=method,enum=pb.Method" json:"method,omitempty"`
 	Body   []byte `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	// Sequence number of the call within the account. The numbers are
+	// shared by every session of the account, so a session sees gaps for
+	// calls outside its subscription. Calls can arrive out of order, when
+	// they were published by different servers or have a higher priority.
+	// Clients keep the number up to which they handled every call they
+	// were sent to resume the tunnel.
+	Seq int64 `protobuf:"varint,4,opt,name=seq" json:"seq,omitempty"`
+	// Labels of the webhook that was called.
+	Labels map[string]string `protobuf:"bytes,5,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Encoding of the body. Bodies are only compressed for clients that
+	// negotiated the compression capability.
+	Encoding Encoding `protobuf:"varint,6,opt,name=encoding,enum=pb.Encoding" json:"encoding,omitempty"`
 }
 
 func (m *HookCall) Reset()                    { *m = HookCall{} }
 func (m *HookCall) String() string            { return proto.CompactTextString(m) }
 func (*HookCall) ProtoMessage()               {}
-func (*HookCall) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*HookCall) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *HookCall) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+// HookBatch carries several hook calls in a single frame. It is only
+// sent to clients that negotiated the batching capability.
+type HookBatch struct {
+	Calls []*HookCall `protobuf:"bytes,1,rep,name=calls" json:"calls,omitempty"`
+}
+
+func (m *HookBatch) Reset()                    { *m = HookBatch{} }
+func (m *HookBatch) String() string            { return proto.CompactTextString(m) }
+func (*HookBatch) ProtoMessage()               {}
+func (*HookBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *HookBatch) GetCalls() []*HookCall {
+	if m != nil {
+		return m.Calls
+	}
+	return nil
+}
+
+// Subscription selects the webhooks a tunnel session receives calls
+// for. A call is sent when its webhook id is listed or when the webhook
+// has every label of the selector. An empty subscription receives the
+// calls of every webhook.
+type Subscription struct {
+	HookIds  []string          `protobuf:"bytes,1,rep,name=hook_ids,json=hookIds" json:"hook_ids,omitempty"`
+	Selector map[string]string `protobuf:"bytes,2,rep,name=selector" json:"selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *Subscription) Reset()                    { *m = Subscription{} }
+func (m *Subscription) String() string            { return proto.CompactTextString(m) }
+func (*Subscription) ProtoMessage()               {}
+func (*Subscription) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *Subscription) GetSelector() map[string]string {
+	if m != nil {
+		return m.Selector
+	}
+	return nil
+}
+
+// TunnelOpen is the first message a client sends on the tunnel.
+type TunnelOpen struct {
+	// Heartbeat interval the client would like to use in milliseconds. The
+	// server picks its default when unset and clamps the value to its limits.
+	HeartbeatIntervalMs int64 `protobuf:"varint,1,opt,name=heartbeat_interval_ms,json=heartbeatIntervalMs" json:"heartbeat_interval_ms,omitempty"`
+	// Resume token of a previous session. The calls after last_seq are
+	// sent again when the token is still valid. Without a token, the calls
+	// after last_seq are sent to clients switching over from polling.
+	// Calls after last_seq the client already has can be sent again.
+	ResumeToken string `protobuf:"bytes,2,opt,name=resume_token,json=resumeToken" json:"resume_token,omitempty"`
+	LastSeq     int64  `protobuf:"varint,3,opt,name=last_seq,json=lastSeq" json:"last_seq,omitempty"`
+	// Version of the client, shown when listing sessions.
+	ClientVersion string `protobuf:"bytes,4,opt,name=client_version,json=clientVersion" json:"client_version,omitempty"`
+	// Free form labels to tell sessions apart, such as the host name.
+	Labels map[string]string `protobuf:"bytes,5,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Webhooks the session wants calls for.
+	Subscription *Subscription `protobuf:"bytes,6,opt,name=subscription" json:"subscription,omitempty"`
+	// Tunnel protocol version spoken by the client. Unset means version 1.
+	ProtocolVersion int32 `protobuf:"varint,7,opt,name=protocol_version,json=protocolVersion" json:"protocol_version,omitempty"`
+	// Name of the client, such as "gohook-cli".
+	ClientName string `protobuf:"bytes,8,opt,name=client_name,json=clientName" json:"client_name,omitempty"`
+	// Optional features the client supports, such as "resume" and
+	// "going-away". Features are only used when both sides support them.
+	Capabilities []string `protobuf:"bytes,9,rep,name=capabilities" json:"capabilities,omitempty"`
+}
+
+func (m *TunnelOpen) Reset()                    { *m = TunnelOpen{} }
+func (m *TunnelOpen) String() string            { return proto.CompactTextString(m) }
+func (*TunnelOpen) ProtoMessage()               {}
+func (*TunnelOpen) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *TunnelOpen) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *TunnelOpen) GetSubscription() *Subscription {
+	if m != nil {
+		return m.Subscription
+	}
+	return nil
+}
+
+// TunnelReady is the first message the server sends on the tunnel.
+type TunnelReady struct {
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	// Heartbeat interval chosen by the server in milliseconds.
+	HeartbeatIntervalMs int64 `protobuf:"varint,2,opt,name=heartbeat_interval_ms,json=heartbeatIntervalMs" json:"heartbeat_interval_ms,omitempty"`
+	// Token to present when reconnecting.
+	ResumeToken string `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken" json:"resume_token,omitempty"`
+	// True when the previous session was resumed without losing any calls.
+	Resumed bool `protobuf:"varint,4,opt,name=resumed" json:"resumed,omitempty"`
+	// Tunnel protocol version spoken by the server.
+	ProtocolVersion int32  `protobuf:"varint,5,opt,name=protocol_version,json=protocolVersion" json:"protocol_version,omitempty"`
+	ServerVersion   string `protobuf:"bytes,6,opt,name=server_version,json=serverVersion" json:"server_version,omitempty"`
+	// Features supported by both sides, which the server will use.
+	Capabilities []string `protobuf:"bytes,7,rep,name=capabilities" json:"capabilities,omitempty"`
+}
+
+func (m *TunnelReady) Reset()                    { *m = TunnelReady{} }
+func (m *TunnelReady) String() string            { return proto.CompactTextString(m) }
+func (*TunnelReady) ProtoMessage()               {}
+func (*TunnelReady) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+// GoingAway is sent before the server closes the tunnel on shutdown. The
+// client should reconnect with its resume token after the delay.
+type GoingAway struct {
+	ReconnectDelayMs int64  `protobuf:"varint,1,opt,name=reconnect_delay_ms,json=reconnectDelayMs" json:"reconnect_delay_ms,omitempty"`
+	Reason           string `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *GoingAway) Reset()                    { *m = GoingAway{} }
+func (m *GoingAway) String() string            { return proto.CompactTextString(m) }
+func (*GoingAway) ProtoMessage()               {}
+func (*GoingAway) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+// HookEvent is sent when a webhook of the account changes. The access
+// policy of the hook is left out.
+type HookEvent struct {
+	Type HookEventType `protobuf:"varint,1,opt,name=type,enum=pb.HookEventType" json:"type,omitempty"`
+	Hook *Hook         `protobuf:"bytes,2,opt,name=hook" json:"hook,omitempty"`
+}
+
+func (m *HookEvent) Reset()                    { *m = HookEvent{} }
+func (m *HookEvent) String() string            { return proto.CompactTextString(m) }
+func (*HookEvent) ProtoMessage()               {}
+func (*HookEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *HookEvent) GetHook() *Hook {
+	if m != nil {
+		return m.Hook
+	}
+	return nil
+}
+
+// SessionKicked is sent to the sessions of an account when one of them
+// has been kicked.
+type SessionKicked struct {
+	SessionId string `protobuf:"bytes,1,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	Reason    string `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *SessionKicked) Reset()                    { *m = SessionKicked{} }
+func (m *SessionKicked) String() string            { return proto.CompactTextString(m) }
+func (*SessionKicked) ProtoMessage()               {}
+func (*SessionKicked) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+// QuotaWarning is sent when the account is close to one of its limits.
+type QuotaWarning struct {
+	Limit string `protobuf:"bytes,1,opt,name=limit" json:"limit,omitempty"`
+	Used  int64  `protobuf:"varint,2,opt,name=used" json:"used,omitempty"`
+	Max   int64  `protobuf:"varint,3,opt,name=max" json:"max,omitempty"`
+}
+
+func (m *QuotaWarning) Reset()                    { *m = QuotaWarning{} }
+func (m *QuotaWarning) String() string            { return proto.CompactTextString(m) }
+func (*QuotaWarning) ProtoMessage()               {}
+func (*QuotaWarning) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+// TokenRevoked is sent before the sessions of an account are closed
+// because its token is no longer valid.
+type TokenRevoked struct {
+	Reason string `protobuf:"bytes,1,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *TokenRevoked) Reset()                    { *m = TokenRevoked{} }
+func (m *TokenRevoked) String() string            { return proto.CompactTextString(m) }
+func (*TokenRevoked) ProtoMessage()               {}
+func (*TokenRevoked) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+// Announcement is a message from the operators of the server.
+type Announcement struct {
+	Message string `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *Announcement) Reset()                    { *m = Announcement{} }
+func (m *Announcement) String() string            { return proto.CompactTextString(m) }
+func (*Announcement) ProtoMessage()               {}
+func (*Announcement) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+// Ping is sent by the server at every heartbeat interval.
+type Ping struct {
+	Seq int64 `protobuf:"varint,1,opt,name=seq" json:"seq,omitempty"`
+}
+
+func (m *Ping) Reset()                    { *m = Ping{} }
+func (m *Ping) String() string            { return proto.CompactTextString(m) }
+func (*Ping) ProtoMessage()               {}
+func (*Ping) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+// Pong answers the Ping with the same sequence number.
+type Pong struct {
+	Seq int64 `protobuf:"varint,1,opt,name=seq" json:"seq,omitempty"`
+}
+
+func (m *Pong) Reset()                    { *m = Pong{} }
+func (m *Pong) String() string            { return proto.CompactTextString(m) }
+func (*Pong) ProtoMessage()               {}
+func (*Pong) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
 type TunnelRequest struct {
+	// Types that are valid to be assigned to Event:
+	//	*TunnelRequest_Open
+	//	*TunnelRequest_Pong
+	//	*TunnelRequest_Subscribe
+	Event isTunnelRequest_Event `protobuf_oneof:"event"`
 }
 
 func (m *TunnelRequest) Reset()                    { *m = TunnelRequest{} }
 func (m *TunnelRequest) String() string            { return proto.CompactTextString(m) }
 func (*TunnelRequest) ProtoMessage()               {}
-func (*TunnelRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*TunnelRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+type isTunnelRequest_Event interface {
+	isTunnelRequest_Event()
+}
+
+type TunnelRequest_Open struct {
+	Open *TunnelOpen `protobuf:"bytes,1,opt,name=open,oneof"`
+}
+type TunnelRequest_Pong struct {
+	Pong *Pong `protobuf:"bytes,2,opt,name=pong,oneof"`
+}
+type TunnelRequest_Subscribe struct {
+	Subscribe *Subscription `protobuf:"bytes,3,opt,name=subscribe,oneof"`
+}
+
+func (*TunnelRequest_Open) isTunnelRequest_Event()      {}
+func (*TunnelRequest_Pong) isTunnelRequest_Event()      {}
+func (*TunnelRequest_Subscribe) isTunnelRequest_Event() {}
+
+func (m *TunnelRequest) GetEvent() isTunnelRequest_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *TunnelRequest) GetOpen() *TunnelOpen {
+	if x, ok := m.GetEvent().(*TunnelRequest_Open); ok {
+		return x.Open
+	}
+	return nil
+}
+
+func (m *TunnelRequest) GetPong() *Pong {
+	if x, ok := m.GetEvent().(*TunnelRequest_Pong); ok {
+		return x.Pong
+	}
+	return nil
+}
+
+func (m *TunnelRequest) GetSubscribe() *Subscription {
+	if x, ok := m.GetEvent().(*TunnelRequest_Subscribe); ok {
+		return x.Subscribe
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*TunnelRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _TunnelRequest_OneofMarshaler, _TunnelRequest_OneofUnmarshaler, _TunnelRequest_OneofSizer, []interface{}{
+		(*TunnelRequest_Open)(nil),
+		(*TunnelRequest_Pong)(nil),
+		(*TunnelRequest_Subscribe)(nil),
+	}
+}
+
+func _TunnelRequest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*TunnelRequest)
+	// event
+	switch x := m.Event.(type) {
+	case *TunnelRequest_Open:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Open); err != nil {
+			return err
+		}
+	case *TunnelRequest_Pong:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Pong); err != nil {
+			return err
+		}
+	case *TunnelRequest_Subscribe:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Subscribe); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("TunnelRequest.Event has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _TunnelRequest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*TunnelRequest)
+	switch tag {
+	case 1: // event.open
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TunnelOpen)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelRequest_Open{msg}
+		return true, err
+	case 2: // event.pong
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Pong)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelRequest_Pong{msg}
+		return true, err
+	case 3: // event.subscribe
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Subscription)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelRequest_Subscribe{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _TunnelRequest_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*TunnelRequest)
+	// event
+	switch x := m.Event.(type) {
+	case *TunnelRequest_Open:
+		s := proto.Size(x.Open)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelRequest_Pong:
+		s := proto.Size(x.Pong)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelRequest_Subscribe:
+		s := proto.Size(x.Subscribe)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
 
 type TunnelResponse struct {
 	// Types that are valid to be assigned to Event:
 	//	*TunnelResponse_Hook
+	//	*TunnelResponse_Ready
+	//	*TunnelResponse_Ping
+	//	*TunnelResponse_GoingAway
+	//	*TunnelResponse_Batch
+	//	*TunnelResponse_HookEvent
+	//	*TunnelResponse_SessionKicked
+	//	*TunnelResponse_QuotaWarning
+	//	*TunnelResponse_TokenRevoked
+	//	*TunnelResponse_Announcement
 	Event isTunnelResponse_Event `protobuf_oneof:"event"`
 }
 
 func (m *TunnelResponse) Reset()                    { *m = TunnelResponse{} }
 func (m *TunnelResponse) String() string            { return proto.CompactTextString(m) }
 func (*TunnelResponse) ProtoMessage()               {}
-func (*TunnelResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*TunnelResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 type isTunnelResponse_Event interface {
 	isTunnelResponse_Event()
@@ -139,8 +816,44 @@ type isTunnelResponse_Event interface {
 type TunnelResponse_Hook struct {
 	Hook *HookCall `protobuf:"bytes,1,opt,name=hook,oneof"`
 }
+type TunnelResponse_Ready struct {
+	Ready *TunnelReady `protobuf:"bytes,2,opt,name=ready,oneof"`
+}
+type TunnelResponse_Ping struct {
+	Ping *Ping `protobuf:"bytes,3,opt,name=ping,oneof"`
+}
+type TunnelResponse_GoingAway struct {
+	GoingAway *GoingAway `protobuf:"bytes,4,opt,name=going_away,json=goingAway,oneof"`
+}
+type TunnelResponse_Batch struct {
+	Batch *HookBatch `protobuf:"bytes,5,opt,name=batch,oneof"`
+}
+type TunnelResponse_HookEvent struct {
+	HookEvent *HookEvent `protobuf:"bytes,6,opt,name=hook_event,json=hookEvent,oneof"`
+}
+type TunnelResponse_SessionKicked struct {
+	SessionKicked *SessionKicked `protobuf:"bytes,7,opt,name=session_kicked,json=sessionKicked,oneof"`
+}
+type TunnelResponse_QuotaWarning struct {
+	QuotaWarning *QuotaWarning `protobuf:"bytes,8,opt,name=quota_warning,json=quotaWarning,oneof"`
+}
+type TunnelResponse_TokenRevoked struct {
+	TokenRevoked *TokenRevoked `protobuf:"bytes,9,opt,name=token_revoked,json=tokenRevoked,oneof"`
+}
+type TunnelResponse_Announcement struct {
+	Announcement *Announcement `protobuf:"bytes,10,opt,name=announcement,oneof"`
+}
 
-func (*TunnelResponse_Hook) isTunnelResponse_Event() {}
+func (*TunnelResponse_Hook) isTunnelResponse_Event()          {}
+func (*TunnelResponse_Ready) isTunnelResponse_Event()         {}
+func (*TunnelResponse_Ping) isTunnelResponse_Event()          {}
+func (*TunnelResponse_GoingAway) isTunnelResponse_Event()     {}
+func (*TunnelResponse_Batch) isTunnelResponse_Event()         {}
+func (*TunnelResponse_HookEvent) isTunnelResponse_Event()     {}
+func (*TunnelResponse_SessionKicked) isTunnelResponse_Event() {}
+func (*TunnelResponse_QuotaWarning) isTunnelResponse_Event()  {}
+func (*TunnelResponse_TokenRevoked) isTunnelResponse_Event()  {}
+func (*TunnelResponse_Announcement) isTunnelResponse_Event()  {}
 
 func (m *TunnelResponse) GetEvent() isTunnelResponse_Event {
 	if m != nil {
@@ -156,10 +869,82 @@ func (m *TunnelResponse) GetHook() *HookCall {
 	return nil
 }
 
+func (m *TunnelResponse) GetReady() *TunnelReady {
+	if x, ok := m.GetEvent().(*TunnelResponse_Ready); ok {
+		return x.Ready
+	}
+	return nil
+}
+
+func (m *TunnelResponse) GetPing() *Ping {
+	if x, ok := m.GetEvent().(*TunnelResponse_Ping); ok {
+		return x.Ping
+	}
+	return nil
+}
+
+func (m *TunnelResponse) GetGoingAway() *GoingAway {
+	if x, ok := m.GetEvent().(*TunnelResponse_GoingAway); ok {
+		return x.GoingAway
+	}
+	return nil
+}
+
+func (m *TunnelResponse) GetBatch() *HookBatch {
+	if x, ok := m.GetEvent().(*TunnelResponse_Batch); ok {
+		return x.Batch
+	}
+	return nil
+}
+
+func (m *TunnelResponse) GetHookEvent() *HookEvent {
+	if x, ok := m.GetEvent().(*TunnelResponse_HookEvent); ok {
+		return x.HookEvent
+	}
+	return nil
+}
+
+func (m *TunnelResponse) GetSessionKicked() *SessionKicked {
+	if x, ok := m.GetEvent().(*TunnelResponse_SessionKicked); ok {
+		return x.SessionKicked
+	}
+	return nil
+}
+
+func (m *TunnelResponse) GetQuotaWarning() *QuotaWarning {
+	if x, ok := m.GetEvent().(*TunnelResponse_QuotaWarning); ok {
+		return x.QuotaWarning
+	}
+	return nil
+}
+
+func (m *TunnelResponse) GetTokenRevoked() *TokenRevoked {
+	if x, ok := m.GetEvent().(*TunnelResponse_TokenRevoked); ok {
+		return x.TokenRevoked
+	}
+	return nil
+}
+
+func (m *TunnelResponse) GetAnnouncement() *Announcement {
+	if x, ok := m.GetEvent().(*TunnelResponse_Announcement); ok {
+		return x.Announcement
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*TunnelResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _TunnelResponse_OneofMarshaler, _TunnelResponse_OneofUnmarshaler, _TunnelResponse_OneofSizer, []interface{}{
 		(*TunnelResponse_Hook)(nil),
+		(*TunnelResponse_Ready)(nil),
+		(*TunnelResponse_Ping)(nil),
+		(*TunnelResponse_GoingAway)(nil),
+		(*TunnelResponse_Batch)(nil),
+		(*TunnelResponse_HookEvent)(nil),
+		(*TunnelResponse_SessionKicked)(nil),
+		(*TunnelResponse_QuotaWarning)(nil),
+		(*TunnelResponse_TokenRevoked)(nil),
+		(*TunnelResponse_Announcement)(nil),
 	}
 }
 
@@ -172,6 +957,51 @@ func _TunnelResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Hook); err != nil {
 			return err
 		}
+	case *TunnelResponse_Ready:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Ready); err != nil {
+			return err
+		}
+	case *TunnelResponse_Ping:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Ping); err != nil {
+			return err
+		}
+	case *TunnelResponse_GoingAway:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.GoingAway); err != nil {
+			return err
+		}
+	case *TunnelResponse_Batch:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Batch); err != nil {
+			return err
+		}
+	case *TunnelResponse_HookEvent:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.HookEvent); err != nil {
+			return err
+		}
+	case *TunnelResponse_SessionKicked:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SessionKicked); err != nil {
+			return err
+		}
+	case *TunnelResponse_QuotaWarning:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.QuotaWarning); err != nil {
+			return err
+		}
+	case *TunnelResponse_TokenRevoked:
+		b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.TokenRevoked); err != nil {
+			return err
+		}
+	case *TunnelResponse_Announcement:
+		b.EncodeVarint(10<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Announcement); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("TunnelResponse.Event has unexpected type %T", x)
@@ -190,112 +1020,579 @@ func _TunnelResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto
 		err := b.DecodeMessage(msg)
 		m.Event = &TunnelResponse_Hook{msg}
 		return true, err
+	case 2: // event.ready
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TunnelReady)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_Ready{msg}
+		return true, err
+	case 3: // event.ping
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Ping)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_Ping{msg}
+		return true, err
+	case 4: // event.going_away
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(GoingAway)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_GoingAway{msg}
+		return true, err
+	case 5: // event.batch
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HookBatch)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_Batch{msg}
+		return true, err
+	case 6: // event.hook_event
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(HookEvent)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_HookEvent{msg}
+		return true, err
+	case 7: // event.session_kicked
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SessionKicked)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_SessionKicked{msg}
+		return true, err
+	case 8: // event.quota_warning
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(QuotaWarning)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_QuotaWarning{msg}
+		return true, err
+	case 9: // event.token_revoked
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(TokenRevoked)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_TokenRevoked{msg}
+		return true, err
+	case 10: // event.announcement
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Announcement)
+		err := b.DecodeMessage(msg)
+		m.Event = &TunnelResponse_Announcement{msg}
+		return true, err
 	default:
 		return false, nil
 	}
 }
 
-func _TunnelResponse_OneofSizer(msg proto.Message) (n int) {
-	m := msg.(*TunnelResponse)
-	// event
-	switch x := m.Event.(type) {
-	case *TunnelResponse_Hook:
-		s := proto.Size(x.Hook)
-		n += proto.SizeVarint(1<<3 | proto.WireBytes)
-		n += proto.SizeVarint(uint64(s))
-		n += s
-	case nil:
-	default:
-		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+func _TunnelResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*TunnelResponse)
+	// event
+	switch x := m.Event.(type) {
+	case *TunnelResponse_Hook:
+		s := proto.Size(x.Hook)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_Ready:
+		s := proto.Size(x.Ready)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_Ping:
+		s := proto.Size(x.Ping)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_GoingAway:
+		s := proto.Size(x.GoingAway)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_Batch:
+		s := proto.Size(x.Batch)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_HookEvent:
+		s := proto.Size(x.HookEvent)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_SessionKicked:
+		s := proto.Size(x.SessionKicked)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_QuotaWarning:
+		s := proto.Size(x.QuotaWarning)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_TokenRevoked:
+		s := proto.Size(x.TokenRevoked)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TunnelResponse_Announcement:
+		s := proto.Size(x.Announcement)
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type ListRequest struct {
+}
+
+func (m *ListRequest) Reset()                    { *m = ListRequest{} }
+func (m *ListRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListRequest) ProtoMessage()               {}
+func (*ListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+type ListResponse struct {
+	Hooks []*Hook `protobuf:"bytes,1,rep,name=hooks" json:"hooks,omitempty"`
+}
+
+func (m *ListResponse) Reset()                    { *m = ListResponse{} }
+func (m *ListResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListResponse) ProtoMessage()               {}
+func (*ListResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *ListResponse) GetHooks() []*Hook {
+	if m != nil {
+		return m.Hooks
+	}
+	return nil
+}
+
+type CreateRequest struct {
+	Hook *HookRequest `protobuf:"bytes,1,opt,name=hook" json:"hook,omitempty"`
+}
+
+func (m *CreateRequest) Reset()                    { *m = CreateRequest{} }
+func (m *CreateRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateRequest) ProtoMessage()               {}
+func (*CreateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *CreateRequest) GetHook() *HookRequest {
+	if m != nil {
+		return m.Hook
+	}
+	return nil
+}
+
+type CreateResponse struct {
+	Hook *Hook `protobuf:"bytes,1,opt,name=hook" json:"hook,omitempty"`
+}
+
+func (m *CreateResponse) Reset()                    { *m = CreateResponse{} }
+func (m *CreateResponse) String() string            { return proto.CompactTextString(m) }
+func (*CreateResponse) ProtoMessage()               {}
+func (*CreateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *CreateResponse) GetHook() *Hook {
+	if m != nil {
+		return m.Hook
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *DeleteRequest) Reset()                    { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()               {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+type DeleteResponse struct {
+	Hook *Hook `protobuf:"bytes,1,opt,name=hook" json:"hook,omitempty"`
+}
+
+func (m *DeleteResponse) Reset()                    { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()               {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *DeleteResponse) GetHook() *Hook {
+	if m != nil {
+		return m.Hook
+	}
+	return nil
+}
+
+// AuditEntry defines a single change made to an account or one of its webhooks.
+type AuditEntry struct {
+	Id        string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	AccountId string `protobuf:"bytes,2,opt,name=account_id,json=accountId" json:"account_id,omitempty"`
+	// Account id of the caller that made the change, or "system".
+	Actor string `protobuf:"bytes,3,opt,name=actor" json:"actor,omitempty"`
+	// Identifier derived from the token used to make the change.
+	TokenId   string `protobuf:"bytes,4,opt,name=token_id,json=tokenId" json:"token_id,omitempty"`
+	ClientIp  string `protobuf:"bytes,5,opt,name=client_ip,json=clientIp" json:"client_ip,omitempty"`
+	Operation string `protobuf:"bytes,6,opt,name=operation" json:"operation,omitempty"`
+	// JSON snapshots of the resource before and after the change.
+	Before []byte `protobuf:"bytes,7,opt,name=before,proto3" json:"before,omitempty"`
+	After  []byte `protobuf:"bytes,8,opt,name=after,proto3" json:"after,omitempty"`
+	// Time of the change in nanoseconds since the unix epoch.
+	Time int64 `protobuf:"varint,9,opt,name=time" json:"time,omitempty"`
+}
+
+func (m *AuditEntry) Reset()                    { *m = AuditEntry{} }
+func (m *AuditEntry) String() string            { return proto.CompactTextString(m) }
+func (*AuditEntry) ProtoMessage()               {}
+func (*AuditEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+type AuditLogRequest struct {
+}
+
+func (m *AuditLogRequest) Reset()                    { *m = AuditLogRequest{} }
+func (m *AuditLogRequest) String() string            { return proto.CompactTextString(m) }
+func (*AuditLogRequest) ProtoMessage()               {}
+func (*AuditLogRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+type AuditLogResponse struct {
+	Entries []*AuditEntry `protobuf:"bytes,1,rep,name=entries" json:"entries,omitempty"`
+}
+
+func (m *AuditLogResponse) Reset()                    { *m = AuditLogResponse{} }
+func (m *AuditLogResponse) String() string            { return proto.CompactTextString(m) }
+func (*AuditLogResponse) ProtoMessage()               {}
+func (*AuditLogResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *AuditLogResponse) GetEntries() []*AuditEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+// Session defines a connected tunnel session.
+type Session struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	// Server instance holding the session.
+	Instance string `protobuf:"bytes,2,opt,name=instance" json:"instance,omitempty"`
+	// Time the session started in nanoseconds since the unix epoch.
+	Start         int64             `protobuf:"varint,3,opt,name=start" json:"start,omitempty"`
+	ClientIp      string            `protobuf:"bytes,4,opt,name=client_ip,json=clientIp" json:"client_ip,omitempty"`
+	ClientVersion string            `protobuf:"bytes,5,opt,name=client_version,json=clientVersion" json:"client_version,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,6,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Number of hook calls sent on the session. Refreshed about once a minute.
+	Delivered       int64         `protobuf:"varint,7,opt,name=delivered" json:"delivered,omitempty"`
+	Subscription    *Subscription `protobuf:"bytes,8,opt,name=subscription" json:"subscription,omitempty"`
+	ClientName      string        `protobuf:"bytes,9,opt,name=client_name,json=clientName" json:"client_name,omitempty"`
+	ProtocolVersion int32         `protobuf:"varint,10,opt,name=protocol_version,json=protocolVersion" json:"protocol_version,omitempty"`
+	Capabilities    []string      `protobuf:"bytes,11,rep,name=capabilities" json:"capabilities,omitempty"`
+}
+
+func (m *Session) Reset()                    { *m = Session{} }
+func (m *Session) String() string            { return proto.CompactTextString(m) }
+func (*Session) ProtoMessage()               {}
+func (*Session) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *Session) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Session) GetSubscription() *Subscription {
+	if m != nil {
+		return m.Subscription
+	}
+	return nil
+}
+
+type ListSessionsRequest struct {
+}
+
+func (m *ListSessionsRequest) Reset()                    { *m = ListSessionsRequest{} }
+func (m *ListSessionsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListSessionsRequest) ProtoMessage()               {}
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+type ListSessionsResponse struct {
+	Sessions []*Session `protobuf:"bytes,1,rep,name=sessions" json:"sessions,omitempty"`
+}
+
+func (m *ListSessionsResponse) Reset()                    { *m = ListSessionsResponse{} }
+func (m *ListSessionsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListSessionsResponse) ProtoMessage()               {}
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *ListSessionsResponse) GetSessions() []*Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+type KickSessionRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+}
+
+func (m *KickSessionRequest) Reset()                    { *m = KickSessionRequest{} }
+func (m *KickSessionRequest) String() string            { return proto.CompactTextString(m) }
+func (*KickSessionRequest) ProtoMessage()               {}
+func (*KickSessionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+type KickSessionResponse struct {
+}
+
+func (m *KickSessionResponse) Reset()                    { *m = KickSessionResponse{} }
+func (m *KickSessionResponse) String() string            { return proto.CompactTextString(m) }
+func (*KickSessionResponse) ProtoMessage()               {}
+func (*KickSessionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+type PresenceRequest struct {
+}
+
+func (m *PresenceRequest) Reset()                    { *m = PresenceRequest{} }
+func (m *PresenceRequest) String() string            { return proto.CompactTextString(m) }
+func (*PresenceRequest) ProtoMessage()               {}
+func (*PresenceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+type PresenceResponse struct {
+	Online bool `protobuf:"varint,1,opt,name=online" json:"online,omitempty"`
+	// Server instances holding sessions of the account.
+	Instances []string `protobuf:"bytes,2,rep,name=instances" json:"instances,omitempty"`
+}
+
+func (m *PresenceResponse) Reset()                    { *m = PresenceResponse{} }
+func (m *PresenceResponse) String() string            { return proto.CompactTextString(m) }
+func (*PresenceResponse) ProtoMessage()               {}
+func (*PresenceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+// DeadLetter is a hook call that could not be delivered.
+type DeadLetter struct {
+	Id     string            `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	HookId string            `protobuf:"bytes,2,opt,name=hook_id,json=hookId" json:"hook_id,omitempty"`
+	Method string            `protobuf:"bytes,3,opt,name=method" json:"method,omitempty"`
+	Body   []byte            `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	Labels map[string]string `protobuf:"bytes,5,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Sequence number the call was delivered under.
+	Seq      int64  `protobuf:"varint,6,opt,name=seq" json:"seq,omitempty"`
+	Reason   string `protobuf:"bytes,7,opt,name=reason" json:"reason,omitempty"`
+	Attempts int32  `protobuf:"varint,8,opt,name=attempts" json:"attempts,omitempty"`
+	// Time the call was given up, in nanoseconds since the epoch.
+	Time int64 `protobuf:"varint,9,opt,name=time" json:"time,omitempty"`
+}
+
+func (m *DeadLetter) Reset()                    { *m = DeadLetter{} }
+func (m *DeadLetter) String() string            { return proto.CompactTextString(m) }
+func (*DeadLetter) ProtoMessage()               {}
+func (*DeadLetter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *DeadLetter) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type ListDeadLettersRequest struct {
+}
+
+func (m *ListDeadLettersRequest) Reset()                    { *m = ListDeadLettersRequest{} }
+func (m *ListDeadLettersRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListDeadLettersRequest) ProtoMessage()               {}
+func (*ListDeadLettersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+type ListDeadLettersResponse struct {
+	DeadLetters []*DeadLetter `protobuf:"bytes,1,rep,name=dead_letters,json=deadLetters" json:"dead_letters,omitempty"`
+}
+
+func (m *ListDeadLettersResponse) Reset()                    { *m = ListDeadLettersResponse{} }
+func (m *ListDeadLettersResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListDeadLettersResponse) ProtoMessage()               {}
+func (*ListDeadLettersResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *ListDeadLettersResponse) GetDeadLetters() []*DeadLetter {
+	if m != nil {
+		return m.DeadLetters
 	}
-	return n
+	return nil
 }
 
-type ListRequest struct {
+type GetDeadLetterRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 }
 
-func (m *ListRequest) Reset()                    { *m = ListRequest{} }
-func (m *ListRequest) String() string            { return proto.CompactTextString(m) }
-func (*ListRequest) ProtoMessage()               {}
-func (*ListRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (m *GetDeadLetterRequest) Reset()                    { *m = GetDeadLetterRequest{} }
+func (m *GetDeadLetterRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetDeadLetterRequest) ProtoMessage()               {}
+func (*GetDeadLetterRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
 
-type ListResponse struct {
-	Hooks []*Hook `protobuf:"bytes,1,rep,name=hooks" json:"hooks,omitempty"`
+type GetDeadLetterResponse struct {
+	DeadLetter *DeadLetter `protobuf:"bytes,1,opt,name=dead_letter,json=deadLetter" json:"dead_letter,omitempty"`
 }
 
-func (m *ListResponse) Reset()                    { *m = ListResponse{} }
-func (m *ListResponse) String() string            { return proto.CompactTextString(m) }
-func (*ListResponse) ProtoMessage()               {}
-func (*ListResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (m *GetDeadLetterResponse) Reset()                    { *m = GetDeadLetterResponse{} }
+func (m *GetDeadLetterResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetDeadLetterResponse) ProtoMessage()               {}
+func (*GetDeadLetterResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
 
-func (m *ListResponse) GetHooks() []*Hook {
+func (m *GetDeadLetterResponse) GetDeadLetter() *DeadLetter {
 	if m != nil {
-		return m.Hooks
+		return m.DeadLetter
 	}
 	return nil
 }
 
-type CreateRequest struct {
-	Hook *HookRequest `protobuf:"bytes,1,opt,name=hook" json:"hook,omitempty"`
+type RequeueDeadLetterRequest struct {
+	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 }
 
-func (m *CreateRequest) Reset()                    { *m = CreateRequest{} }
-func (m *CreateRequest) String() string            { return proto.CompactTextString(m) }
-func (*CreateRequest) ProtoMessage()               {}
-func (*CreateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (m *RequeueDeadLetterRequest) Reset()                    { *m = RequeueDeadLetterRequest{} }
+func (m *RequeueDeadLetterRequest) String() string            { return proto.CompactTextString(m) }
+func (*RequeueDeadLetterRequest) ProtoMessage()               {}
+func (*RequeueDeadLetterRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
 
-func (m *CreateRequest) GetHook() *HookRequest {
+type RequeueDeadLetterResponse struct {
+}
+
+func (m *RequeueDeadLetterResponse) Reset()                    { *m = RequeueDeadLetterResponse{} }
+func (m *RequeueDeadLetterResponse) String() string            { return proto.CompactTextString(m) }
+func (*RequeueDeadLetterResponse) ProtoMessage()               {}
+func (*RequeueDeadLetterResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+type PurgeDeadLettersRequest struct {
+	Ids []string `protobuf:"bytes,1,rep,name=ids" json:"ids,omitempty"`
+}
+
+func (m *PurgeDeadLettersRequest) Reset()                    { *m = PurgeDeadLettersRequest{} }
+func (m *PurgeDeadLettersRequest) String() string            { return proto.CompactTextString(m) }
+func (*PurgeDeadLettersRequest) ProtoMessage()               {}
+func (*PurgeDeadLettersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+type PurgeDeadLettersResponse struct {
+	Purged int32 `protobuf:"varint,1,opt,name=purged" json:"purged,omitempty"`
+}
+
+func (m *PurgeDeadLettersResponse) Reset()                    { *m = PurgeDeadLettersResponse{} }
+func (m *PurgeDeadLettersResponse) String() string            { return proto.CompactTextString(m) }
+func (*PurgeDeadLettersResponse) ProtoMessage()               {}
+func (*PurgeDeadLettersResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+// QueueHookCall is a call to a webhook. The method is kept as sent by
+// the caller.
+type QueueHookCall struct {
+	Seq    int64             `protobuf:"varint,1,opt,name=seq" json:"seq,omitempty"`
+	Id     string            `protobuf:"bytes,2,opt,name=id" json:"id,omitempty"`
+	Method string            `protobuf:"bytes,3,opt,name=method" json:"method,omitempty"`
+	Body   []byte            `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	Labels map[string]string `protobuf:"bytes,5,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Unix times in nanoseconds, zero when not set.
+	Expires   int64 `protobuf:"varint,6,opt,name=expires" json:"expires,omitempty"`
+	NotBefore int64 `protobuf:"varint,7,opt,name=not_before,json=notBefore" json:"not_before,omitempty"`
+	Priority  int32 `protobuf:"varint,8,opt,name=priority" json:"priority,omitempty"`
+}
+
+func (m *QueueHookCall) Reset()                    { *m = QueueHookCall{} }
+func (m *QueueHookCall) String() string            { return proto.CompactTextString(m) }
+func (*QueueHookCall) ProtoMessage()               {}
+func (*QueueHookCall) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+func (m *QueueHookCall) GetLabels() map[string]string {
 	if m != nil {
-		return m.Hook
+		return m.Labels
 	}
 	return nil
 }
 
-type CreateResponse struct {
-	Hook *Hook `protobuf:"bytes,1,opt,name=hook" json:"hook,omitempty"`
+// QueueEvent is the event of a queue message. Only the fields of its
+// type are set.
+type QueueEvent struct {
+	Type      QueueEventType `protobuf:"varint,1,opt,name=type,enum=pb.QueueEventType" json:"type,omitempty"`
+	Hook      *Hook          `protobuf:"bytes,2,opt,name=hook" json:"hook,omitempty"`
+	SessionId string         `protobuf:"bytes,3,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	Limit     string         `protobuf:"bytes,4,opt,name=limit" json:"limit,omitempty"`
+	Used      int64          `protobuf:"varint,5,opt,name=used" json:"used,omitempty"`
+	Max       int64          `protobuf:"varint,6,opt,name=max" json:"max,omitempty"`
+	Message   string         `protobuf:"bytes,7,opt,name=message" json:"message,omitempty"`
 }
 
-func (m *CreateResponse) Reset()                    { *m = CreateResponse{} }
-func (m *CreateResponse) String() string            { return proto.CompactTextString(m) }
-func (*CreateResponse) ProtoMessage()               {}
-func (*CreateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (m *QueueEvent) Reset()                    { *m = QueueEvent{} }
+func (m *QueueEvent) String() string            { return proto.CompactTextString(m) }
+func (*QueueEvent) ProtoMessage()               {}
+func (*QueueEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
 
-func (m *CreateResponse) GetHook() *Hook {
+func (m *QueueEvent) GetHook() *Hook {
 	if m != nil {
 		return m.Hook
 	}
 	return nil
 }
 
-type DeleteRequest struct {
-	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+// QueueEnvelope wraps every queue message. Version is the first field
+// so readers can tell envelopes from legacy payloads, and is only
+// incremented on changes older processes can not read.
+type QueueEnvelope struct {
+	Version   uint32           `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Type      QueueMessageType `protobuf:"varint,2,opt,name=type,enum=pb.QueueMessageType" json:"type,omitempty"`
+	AccountId string           `protobuf:"bytes,3,opt,name=account_id,json=accountId" json:"account_id,omitempty"`
+	SessionId string           `protobuf:"bytes,4,opt,name=session_id,json=sessionId" json:"session_id,omitempty"`
+	Hook      *QueueHookCall   `protobuf:"bytes,5,opt,name=hook" json:"hook,omitempty"`
+	Reason    string           `protobuf:"bytes,6,opt,name=reason" json:"reason,omitempty"`
+	Event     *QueueEvent      `protobuf:"bytes,7,opt,name=event" json:"event,omitempty"`
 }
 
-func (m *DeleteRequest) Reset()                    { *m = DeleteRequest{} }
-func (m *DeleteRequest) String() string            { return proto.CompactTextString(m) }
-func (*DeleteRequest) ProtoMessage()               {}
-func (*DeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (m *QueueEnvelope) Reset()                    { *m = QueueEnvelope{} }
+func (m *QueueEnvelope) String() string            { return proto.CompactTextString(m) }
+func (*QueueEnvelope) ProtoMessage()               {}
+func (*QueueEnvelope) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
 
-type DeleteResponse struct {
-	Hook *Hook `protobuf:"bytes,1,opt,name=hook" json:"hook,omitempty"`
+func (m *QueueEnvelope) GetHook() *QueueHookCall {
+	if m != nil {
+		return m.Hook
+	}
+	return nil
 }
 
-func (m *DeleteResponse) Reset()                    { *m = DeleteResponse{} }
-func (m *DeleteResponse) String() string            { return proto.CompactTextString(m) }
-func (*DeleteResponse) ProtoMessage()               {}
-func (*DeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
-
-func (m *DeleteResponse) GetHook() *Hook {
+func (m *QueueEnvelope) GetEvent() *QueueEvent {
 	if m != nil {
-		return m.Hook
+		return m.Event
 	}
 	return nil
 }
 
 func init() {
+	proto.RegisterType((*HookAuth)(nil), "pb.HookAuth")
+	proto.RegisterType((*AccessPolicy)(nil), "pb.AccessPolicy")
 	proto.RegisterType((*Hook)(nil), "pb.Hook")
 	proto.RegisterType((*HookRequest)(nil), "pb.HookRequest")
 	proto.RegisterType((*HookCall)(nil), "pb.HookCall")
+	proto.RegisterType((*HookBatch)(nil), "pb.HookBatch")
+	proto.RegisterType((*Subscription)(nil), "pb.Subscription")
+	proto.RegisterType((*TunnelOpen)(nil), "pb.TunnelOpen")
+	proto.RegisterType((*TunnelReady)(nil), "pb.TunnelReady")
+	proto.RegisterType((*GoingAway)(nil), "pb.GoingAway")
+	proto.RegisterType((*HookEvent)(nil), "pb.HookEvent")
+	proto.RegisterType((*SessionKicked)(nil), "pb.SessionKicked")
+	proto.RegisterType((*QuotaWarning)(nil), "pb.QuotaWarning")
+	proto.RegisterType((*TokenRevoked)(nil), "pb.TokenRevoked")
+	proto.RegisterType((*Announcement)(nil), "pb.Announcement")
+	proto.RegisterType((*Ping)(nil), "pb.Ping")
+	proto.RegisterType((*Pong)(nil), "pb.Pong")
 	proto.RegisterType((*TunnelRequest)(nil), "pb.TunnelRequest")
 	proto.RegisterType((*TunnelResponse)(nil), "pb.TunnelResponse")
 	proto.RegisterType((*ListRequest)(nil), "pb.ListRequest")
@@ -304,7 +1601,35 @@ func init() {
 	proto.RegisterType((*CreateResponse)(nil), "pb.CreateResponse")
 	proto.RegisterType((*DeleteRequest)(nil), "pb.DeleteRequest")
 	proto.RegisterType((*DeleteResponse)(nil), "pb.DeleteResponse")
+	proto.RegisterType((*AuditEntry)(nil), "pb.AuditEntry")
+	proto.RegisterType((*AuditLogRequest)(nil), "pb.AuditLogRequest")
+	proto.RegisterType((*AuditLogResponse)(nil), "pb.AuditLogResponse")
+	proto.RegisterType((*Session)(nil), "pb.Session")
+	proto.RegisterType((*ListSessionsRequest)(nil), "pb.ListSessionsRequest")
+	proto.RegisterType((*ListSessionsResponse)(nil), "pb.ListSessionsResponse")
+	proto.RegisterType((*KickSessionRequest)(nil), "pb.KickSessionRequest")
+	proto.RegisterType((*KickSessionResponse)(nil), "pb.KickSessionResponse")
+	proto.RegisterType((*PresenceRequest)(nil), "pb.PresenceRequest")
+	proto.RegisterType((*PresenceResponse)(nil), "pb.PresenceResponse")
+	proto.RegisterType((*DeadLetter)(nil), "pb.DeadLetter")
+	proto.RegisterType((*ListDeadLettersRequest)(nil), "pb.ListDeadLettersRequest")
+	proto.RegisterType((*ListDeadLettersResponse)(nil), "pb.ListDeadLettersResponse")
+	proto.RegisterType((*GetDeadLetterRequest)(nil), "pb.GetDeadLetterRequest")
+	proto.RegisterType((*GetDeadLetterResponse)(nil), "pb.GetDeadLetterResponse")
+	proto.RegisterType((*RequeueDeadLetterRequest)(nil), "pb.RequeueDeadLetterRequest")
+	proto.RegisterType((*RequeueDeadLetterResponse)(nil), "pb.RequeueDeadLetterResponse")
+	proto.RegisterType((*PurgeDeadLettersRequest)(nil), "pb.PurgeDeadLettersRequest")
+	proto.RegisterType((*PurgeDeadLettersResponse)(nil), "pb.PurgeDeadLettersResponse")
+	proto.RegisterType((*QueueHookCall)(nil), "pb.QueueHookCall")
+	proto.RegisterType((*QueueEvent)(nil), "pb.QueueEvent")
+	proto.RegisterType((*QueueEnvelope)(nil), "pb.QueueEnvelope")
 	proto.RegisterEnum("pb.Method", Method_name, Method_value)
+	proto.RegisterEnum("pb.AuthType", AuthType_name, AuthType_value)
+	proto.RegisterEnum("pb.Encoding", Encoding_name, Encoding_value)
+	proto.RegisterEnum("pb.OfflinePolicy", OfflinePolicy_name, OfflinePolicy_value)
+	proto.RegisterEnum("pb.HookEventType", HookEventType_name, HookEventType_value)
+	proto.RegisterEnum("pb.QueueMessageType", QueueMessageType_name, QueueMessageType_value)
+	proto.RegisterEnum("pb.QueueEventType", QueueEventType_name, QueueEventType_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -323,7 +1648,13 @@ type GohookClient interface {
 	// specific events happen that the client needs to know about. This
 	// includes when one of the webhook ids is hit so the client can
 	// execute the script paired with that hook id.
-	Tunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (Gohook_TunnelClient, error)
+	//
+	// The client opens the tunnel with a TunnelOpen message. The server
+	// answers with TunnelReady and then pings the client at the agreed
+	// heartbeat interval. Sessions that miss too many pongs are closed.
+	// Clients built when the tunnel was a server stream send a single
+	// empty TunnelRequest instead, and are only sent hook calls.
+	Tunnel(ctx context.Context, opts ...grpc.CallOption) (Gohook_TunnelClient, error)
 	// List returns all of the webhooks that are tied to this client.
 	// This allows the client to stay synced with the webhooks that are
 	// enabled and ones that have been removed.
@@ -336,6 +1667,29 @@ type GohookClient interface {
 	// This allows the client to unsubscribe when it no longer cares about
 	// the restults of a webhook getting hit.
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// AuditLog returns the audit trail for this client's account, newest
+	// first. Every change to the account and its webhooks is recorded.
+	AuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error)
+	// ListSessions returns the tunnel sessions of this client's account
+	// across every server instance.
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	// KickSession closes a tunnel session of this client's account, no
+	// matter which server instance holds it.
+	KickSession(ctx context.Context, in *KickSessionRequest, opts ...grpc.CallOption) (*KickSessionResponse, error)
+	// Presence returns whether any server instance holds a tunnel session
+	// of this client's account.
+	Presence(ctx context.Context, in *PresenceRequest, opts ...grpc.CallOption) (*PresenceResponse, error)
+	// ListDeadLetters returns the hook calls of this client's account
+	// that could not be delivered, newest first. Bodies are left out.
+	ListDeadLetters(ctx context.Context, in *ListDeadLettersRequest, opts ...grpc.CallOption) (*ListDeadLettersResponse, error)
+	// GetDeadLetter returns a dead letter with the body of its call.
+	GetDeadLetter(ctx context.Context, in *GetDeadLetterRequest, opts ...grpc.CallOption) (*GetDeadLetterResponse, error)
+	// RequeueDeadLetter delivers the call of a dead letter again under a
+	// new sequence number and removes the dead letter.
+	RequeueDeadLetter(ctx context.Context, in *RequeueDeadLetterRequest, opts ...grpc.CallOption) (*RequeueDeadLetterResponse, error)
+	// PurgeDeadLetters removes the given dead letters, or all of them
+	// when no ids are given.
+	PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*PurgeDeadLettersResponse, error)
 }
 
 type gohookClient struct {
@@ -346,22 +1700,17 @@ func NewGohookClient(cc *grpc.ClientConn) GohookClient {
 	return &gohookClient{cc}
 }
 
-func (c *gohookClient) Tunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (Gohook_TunnelClient, error) {
+func (c *gohookClient) Tunnel(ctx context.Context, opts ...grpc.CallOption) (Gohook_TunnelClient, error) {
 	stream, err := grpc.NewClientStream(ctx, &_Gohook_serviceDesc.Streams[0], c.cc, "/pb.Gohook/Tunnel", opts...)
 	if err != nil {
 		return nil, err
 	}
 	x := &gohookTunnelClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
 	return x, nil
 }
 
 type Gohook_TunnelClient interface {
+	Send(*TunnelRequest) error
 	Recv() (*TunnelResponse, error)
 	grpc.ClientStream
 }
@@ -370,6 +1719,10 @@ type gohookTunnelClient struct {
 	grpc.ClientStream
 }
 
+func (x *gohookTunnelClient) Send(m *TunnelRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
 func (x *gohookTunnelClient) Recv() (*TunnelResponse, error) {
 	m := new(TunnelResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
@@ -405,6 +1758,78 @@ func (c *gohookClient) Delete(ctx context.Context, in *DeleteRequest, opts ...gr
 	return out, nil
 }
 
+func (c *gohookClient) AuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error) {
+	out := new(AuditLogResponse)
+	err := grpc.Invoke(ctx, "/pb.Gohook/AuditLog", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gohookClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	err := grpc.Invoke(ctx, "/pb.Gohook/ListSessions", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gohookClient) KickSession(ctx context.Context, in *KickSessionRequest, opts ...grpc.CallOption) (*KickSessionResponse, error) {
+	out := new(KickSessionResponse)
+	err := grpc.Invoke(ctx, "/pb.Gohook/KickSession", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gohookClient) Presence(ctx context.Context, in *PresenceRequest, opts ...grpc.CallOption) (*PresenceResponse, error) {
+	out := new(PresenceResponse)
+	err := grpc.Invoke(ctx, "/pb.Gohook/Presence", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gohookClient) ListDeadLetters(ctx context.Context, in *ListDeadLettersRequest, opts ...grpc.CallOption) (*ListDeadLettersResponse, error) {
+	out := new(ListDeadLettersResponse)
+	err := grpc.Invoke(ctx, "/pb.Gohook/ListDeadLetters", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gohookClient) GetDeadLetter(ctx context.Context, in *GetDeadLetterRequest, opts ...grpc.CallOption) (*GetDeadLetterResponse, error) {
+	out := new(GetDeadLetterResponse)
+	err := grpc.Invoke(ctx, "/pb.Gohook/GetDeadLetter", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gohookClient) RequeueDeadLetter(ctx context.Context, in *RequeueDeadLetterRequest, opts ...grpc.CallOption) (*RequeueDeadLetterResponse, error) {
+	out := new(RequeueDeadLetterResponse)
+	err := grpc.Invoke(ctx, "/pb.Gohook/RequeueDeadLetter", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gohookClient) PurgeDeadLetters(ctx context.Context, in *PurgeDeadLettersRequest, opts ...grpc.CallOption) (*PurgeDeadLettersResponse, error) {
+	out := new(PurgeDeadLettersResponse)
+	err := grpc.Invoke(ctx, "/pb.Gohook/PurgeDeadLetters", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Gohook service
 
 type GohookServer interface {
@@ -413,7 +1838,13 @@ type GohookServer interface {
 	// specific events happen that the client needs to know about. This
 	// includes when one of the webhook ids is hit so the client can
 	// execute the script paired with that hook id.
-	Tunnel(*TunnelRequest, Gohook_TunnelServer) error
+	//
+	// The client opens the tunnel with a TunnelOpen message. The server
+	// answers with TunnelReady and then pings the client at the agreed
+	// heartbeat interval. Sessions that miss too many pongs are closed.
+	// Clients built when the tunnel was a server stream send a single
+	// empty TunnelRequest instead, and are only sent hook calls.
+	Tunnel(Gohook_TunnelServer) error
 	// List returns all of the webhooks that are tied to this client.
 	// This allows the client to stay synced with the webhooks that are
 	// enabled and ones that have been removed.
@@ -426,6 +1857,29 @@ type GohookServer interface {
 	// This allows the client to unsubscribe when it no longer cares about
 	// the restults of a webhook getting hit.
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// AuditLog returns the audit trail for this client's account, newest
+	// first. Every change to the account and its webhooks is recorded.
+	AuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error)
+	// ListSessions returns the tunnel sessions of this client's account
+	// across every server instance.
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	// KickSession closes a tunnel session of this client's account, no
+	// matter which server instance holds it.
+	KickSession(context.Context, *KickSessionRequest) (*KickSessionResponse, error)
+	// Presence returns whether any server instance holds a tunnel session
+	// of this client's account.
+	Presence(context.Context, *PresenceRequest) (*PresenceResponse, error)
+	// ListDeadLetters returns the hook calls of this client's account
+	// that could not be delivered, newest first. Bodies are left out.
+	ListDeadLetters(context.Context, *ListDeadLettersRequest) (*ListDeadLettersResponse, error)
+	// GetDeadLetter returns a dead letter with the body of its call.
+	GetDeadLetter(context.Context, *GetDeadLetterRequest) (*GetDeadLetterResponse, error)
+	// RequeueDeadLetter delivers the call of a dead letter again under a
+	// new sequence number and removes the dead letter.
+	RequeueDeadLetter(context.Context, *RequeueDeadLetterRequest) (*RequeueDeadLetterResponse, error)
+	// PurgeDeadLetters removes the given dead letters, or all of them
+	// when no ids are given.
+	PurgeDeadLetters(context.Context, *PurgeDeadLettersRequest) (*PurgeDeadLettersResponse, error)
 }
 
 func RegisterGohookServer(s *grpc.Server, srv GohookServer) {
@@ -433,15 +1887,12 @@ func RegisterGohookServer(s *grpc.Server, srv GohookServer) {
 }
 
 func _Gohook_Tunnel_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(TunnelRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(GohookServer).Tunnel(m, &gohookTunnelServer{stream})
+	return srv.(GohookServer).Tunnel(&gohookTunnelServer{stream})
 }
 
 type Gohook_TunnelServer interface {
 	Send(*TunnelResponse) error
+	Recv() (*TunnelRequest, error)
 	grpc.ServerStream
 }
 
@@ -453,6 +1904,14 @@ func (x *gohookTunnelServer) Send(m *TunnelResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func (x *gohookTunnelServer) Recv() (*TunnelRequest, error) {
+	m := new(TunnelRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Gohook_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListRequest)
 	if err := dec(in); err != nil {
@@ -507,6 +1966,150 @@ func _Gohook_Delete_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Gohook_AuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GohookServer).AuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Gohook/AuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GohookServer).AuditLog(ctx, req.(*AuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gohook_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GohookServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Gohook/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GohookServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gohook_KickSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KickSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GohookServer).KickSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Gohook/KickSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GohookServer).KickSession(ctx, req.(*KickSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gohook_Presence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PresenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GohookServer).Presence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Gohook/Presence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GohookServer).Presence(ctx, req.(*PresenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gohook_ListDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GohookServer).ListDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Gohook/ListDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GohookServer).ListDeadLetters(ctx, req.(*ListDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gohook_GetDeadLetter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeadLetterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GohookServer).GetDeadLetter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Gohook/GetDeadLetter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GohookServer).GetDeadLetter(ctx, req.(*GetDeadLetterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gohook_RequeueDeadLetter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequeueDeadLetterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GohookServer).RequeueDeadLetter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Gohook/RequeueDeadLetter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GohookServer).RequeueDeadLetter(ctx, req.(*RequeueDeadLetterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Gohook_PurgeDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GohookServer).PurgeDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Gohook/PurgeDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GohookServer).PurgeDeadLetters(ctx, req.(*PurgeDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Gohook_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Gohook",
 	HandlerType: (*GohookServer)(nil),
@@ -523,12 +2126,45 @@ var _Gohook_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _Gohook_Delete_Handler,
 		},
+		{
+			MethodName: "AuditLog",
+			Handler:    _Gohook_AuditLog_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _Gohook_ListSessions_Handler,
+		},
+		{
+			MethodName: "KickSession",
+			Handler:    _Gohook_KickSession_Handler,
+		},
+		{
+			MethodName: "Presence",
+			Handler:    _Gohook_Presence_Handler,
+		},
+		{
+			MethodName: "ListDeadLetters",
+			Handler:    _Gohook_ListDeadLetters_Handler,
+		},
+		{
+			MethodName: "GetDeadLetter",
+			Handler:    _Gohook_GetDeadLetter_Handler,
+		},
+		{
+			MethodName: "RequeueDeadLetter",
+			Handler:    _Gohook_RequeueDeadLetter_Handler,
+		},
+		{
+			MethodName: "PurgeDeadLetters",
+			Handler:    _Gohook_PurgeDeadLetters_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Tunnel",
 			Handler:       _Gohook_Tunnel_Handler,
 			ServerStreams: true,
+			ClientStreams: true,
 		},
 	},
 	Metadata: fileDescriptor0,
@@ -537,32 +2173,170 @@ var _Gohook_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("gohook.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 425 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x93, 0x51, 0x6f, 0xd3, 0x30,
-	0x14, 0x85, 0xeb, 0x24, 0x4d, 0xbb, 0x9b, 0x36, 0x33, 0xf7, 0x29, 0x9a, 0x10, 0x54, 0xe6, 0xa5,
-	0x02, 0x29, 0x62, 0x19, 0xaf, 0x3c, 0x40, 0x17, 0x2d, 0x12, 0xa5, 0xab, 0x42, 0x26, 0x9e, 0x17,
-	0xc5, 0x62, 0xd5, 0x42, 0x1c, 0x9a, 0x14, 0x89, 0x7f, 0xc9, 0x4f, 0x42, 0xb6, 0x63, 0xd2, 0x80,
-	0x2a, 0xed, 0xcd, 0x3d, 0xbe, 0xe7, 0x9c, 0xab, 0xcf, 0x0d, 0xcc, 0xbe, 0x89, 0x07, 0x21, 0x1e,
-	0xc3, 0x7a, 0x2f, 0x5a, 0x81, 0x56, 0x9d, 0xb3, 0x35, 0x38, 0x89, 0x10, 0x8f, 0xe8, 0x83, 0xb5,
-	0x2b, 0x02, 0xb2, 0x20, 0xcb, 0xb3, 0xd4, 0xda, 0x15, 0x48, 0xc1, 0x3e, 0xec, 0xcb, 0xc0, 0x52,
-	0x82, 0x3c, 0x22, 0x03, 0xf7, 0x3b, 0x6f, 0x1f, 0x44, 0x11, 0xd8, 0x0b, 0xb2, 0xf4, 0x23, 0x08,
-	0xeb, 0x3c, 0xfc, 0xac, 0x94, 0xb4, 0xbb, 0x61, 0x97, 0xe0, 0xc9, 0xb4, 0x94, 0xff, 0x38, 0xf0,
-	0xa6, 0x3d, 0xb2, 0x90, 0x93, 0x96, 0x14, 0xa6, 0xd2, 0xb2, 0xba, 0x2f, 0xcb, 0xff, 0x96, 0xe8,
-	0xfd, 0xd6, 0x29, 0x3f, 0x22, 0x38, 0xb9, 0x28, 0x7e, 0xa9, 0xa5, 0x66, 0xa9, 0x3a, 0xb3, 0x73,
-	0x98, 0x67, 0x87, 0xaa, 0xe2, 0x65, 0xb7, 0x08, 0x7b, 0x0f, 0xbe, 0x11, 0x9a, 0x5a, 0x54, 0x0d,
-	0x47, 0x06, 0x8e, 0x24, 0xa1, 0xca, 0xbc, 0x68, 0x26, 0x83, 0xcd, 0x1a, 0xc9, 0x28, 0x55, 0x77,
-	0x1f, 0x27, 0x30, 0xe6, 0x3f, 0x79, 0xd5, 0xb2, 0x39, 0x78, 0xeb, 0x5d, 0xd3, 0x9a, 0xb4, 0x10,
-	0x66, 0xfa, 0x67, 0x97, 0xf5, 0x02, 0xc6, 0x72, 0xbe, 0x09, 0xc8, 0xc2, 0x5e, 0x7a, 0xd1, 0xd4,
-	0x84, 0xa5, 0x5a, 0x66, 0xef, 0x60, 0xbe, 0xda, 0xf3, 0xfb, 0x96, 0x1b, 0x2e, 0xaf, 0x06, 0xe5,
-	0xe7, 0x7f, 0xe7, 0xf5, 0xb5, 0x6e, 0x67, 0x21, 0xf8, 0xc6, 0xd5, 0xf5, 0x3c, 0x1f, 0xd8, 0xfa,
-	0x1a, 0x3d, 0xff, 0x12, 0xe6, 0xd7, 0xbc, 0xe4, 0x7d, 0xcb, 0x3f, 0x34, 0x65, 0xa0, 0x19, 0x78,
-	0x4a, 0xe0, 0xeb, 0x04, 0x5c, 0xcd, 0x1a, 0x3d, 0x98, 0xdc, 0x6d, 0x3e, 0x6d, 0x6e, 0xbf, 0x6e,
-	0xe8, 0x08, 0x27, 0x60, 0xdf, 0xc4, 0x19, 0x25, 0x38, 0x05, 0x67, 0x7b, 0xfb, 0x25, 0xa3, 0x96,
-	0x94, 0xb6, 0x77, 0x19, 0xb5, 0xf1, 0x0c, 0xc6, 0xdb, 0x0f, 0xd9, 0x2a, 0xa1, 0x0e, 0x02, 0xb8,
-	0xd7, 0xf1, 0x3a, 0xce, 0x62, 0x3a, 0x8e, 0x7e, 0x13, 0x70, 0x6f, 0xd4, 0x3f, 0x0f, 0xaf, 0xc0,
-	0xd5, 0x2f, 0x81, 0xcf, 0x64, 0xdd, 0xe0, 0x99, 0x2e, 0xf0, 0x58, 0xd2, 0x3b, 0xb2, 0xd1, 0x5b,
-	0x82, 0x6f, 0xc0, 0x91, 0xc0, 0x51, 0x91, 0x3a, 0x7a, 0x89, 0x0b, 0xda, 0x0b, 0x66, 0x1c, 0x2f,
-	0xc1, 0xd5, 0xdc, 0x74, 0xc3, 0x80, 0xbc, 0x6e, 0x18, 0x62, 0xd5, 0x16, 0x4d, 0x46, 0x5b, 0x06,
-	0x18, 0xb5, 0x65, 0x08, 0x8e, 0x8d, 0x72, 0x57, 0x7d, 0x42, 0x57, 0x7f, 0x02, 0x00, 0x00, 0xff,
-	0xff, 0x15, 0x6e, 0x77, 0x75, 0x52, 0x03, 0x00, 0x00,
+	// 2633 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xad, 0x59, 0x4b, 0x73, 0xdb, 0xc8,
+	0x11, 0x36, 0xdf, 0x64, 0xf3, 0x21, 0x0a, 0x96, 0x2d, 0x9a, 0x7e, 0xec, 0x06, 0x6b, 0xef, 0x6a,
+	0xe5, 0x94, 0xbc, 0xd1, 0x3a, 0xd9, 0xc4, 0x49, 0x55, 0x42, 0x53, 0xb0, 0x44, 0x4b, 0x26, 0x69,
+	0x88, 0xb2, 0x37, 0xb9, 0xb0, 0x20, 0x12, 0x96, 0x50, 0xa6, 0x08, 0x1a, 0x00, 0x6d, 0x2b, 0x87,
+	0x1c, 0x73, 0x4e, 0x55, 0xce, 0xb9, 0xa4, 0x72, 0xca, 0x4f, 0xc8, 0x25, 0xb7, 0xe4, 0x3f, 0xe4,
+	0x1f, 0xe4, 0x9c, 0xaa, 0x5c, 0x72, 0x49, 0xf7, 0x3c, 0x80, 0x01, 0x48, 0x95, 0xed, 0xb2, 0x6f,
+	0x9c, 0xee, 0x9e, 0x99, 0xee, 0x9e, 0xaf, 0x5f, 0x20, 0x54, 0x4e, 0xdc, 0x53, 0xd7, 0x7d, 0xb9,
+	0x35, 0xf3, 0xdc, 0xc0, 0xd5, 0xd2, 0xb3, 0x63, 0xfd, 0xb7, 0x50, 0xdc, 0x43, 0x4a, 0x6b, 0x1e,
+	0x9c, 0x6a, 0x9f, 0x43, 0x36, 0x38, 0x9f, 0xd9, 0x8d, 0xd4, 0xe7, 0xa9, 0x8d, 0xda, 0x76, 0x65,
+	0x6b, 0x76, 0xbc, 0x45, 0xf4, 0x01, 0xd2, 0x4c, 0xc6, 0xd1, 0x9a, 0x50, 0x9c, 0xfb, 0xb6, 0x37,
+	0xb5, 0xce, 0xec, 0x46, 0x1a, 0xa5, 0x4a, 0x66, 0xb8, 0x26, 0xde, 0xcc, 0xf2, 0xfd, 0x37, 0xae,
+	0x37, 0x6e, 0x64, 0x38, 0x4f, 0xae, 0xb5, 0x35, 0xc8, 0x05, 0xee, 0x4b, 0x7b, 0xda, 0xc8, 0x32,
+	0x06, 0x5f, 0xe8, 0xbf, 0x83, 0x4a, 0x6b, 0x34, 0xb2, 0x7d, 0xbf, 0xef, 0x4e, 0x9c, 0xd1, 0xb9,
+	0xf6, 0x05, 0x54, 0xad, 0xc9, 0xc4, 0x7d, 0x63, 0x8f, 0x87, 0x23, 0x67, 0xec, 0xf9, 0xa8, 0x48,
+	0x06, 0xa5, 0x2b, 0x82, 0xd8, 0x26, 0x9a, 0xf6, 0x15, 0xac, 0x04, 0xde, 0xdc, 0x0f, 0x50, 0x08,
+	0xad, 0x78, 0xeb, 0xd8, 0x3e, 0xd3, 0x24, 0x67, 0xd6, 0x04, 0xb9, 0xcf, 0xa9, 0x64, 0x8d, 0x85,
+	0xda, 0x33, 0x5d, 0xca, 0xdc, 0x1a, 0x69, 0xa9, 0xc9, 0x38, 0xfa, 0xbf, 0xd2, 0x90, 0x25, 0x92,
+	0x56, 0x83, 0xb4, 0x33, 0x66, 0x66, 0x97, 0x4c, 0xfc, 0xa5, 0xd5, 0x21, 0x33, 0xf7, 0x26, 0xc2,
+	0x42, 0xfa, 0xa9, 0xe9, 0x90, 0x3f, 0xb3, 0x83, 0x53, 0x97, 0x9b, 0x56, 0xdb, 0x06, 0x3a, 0xee,
+	0x09, 0xa3, 0x98, 0x82, 0xa3, 0x6d, 0x40, 0xde, 0x62, 0xe6, 0x30, 0x2b, 0xcb, 0xdb, 0x75, 0xe6,
+	0x40, 0xc5, 0x40, 0x53, 0xf0, 0xb5, 0x1f, 0x42, 0x7e, 0x62, 0x1d, 0xdb, 0x13, 0xbf, 0x91, 0x43,
+	0x0b, 0xcb, 0xdb, 0x6b, 0x52, 0xb9, 0xad, 0x03, 0x46, 0x36, 0xa6, 0x81, 0x87, 0xd2, 0x5c, 0x46,
+	0xbb, 0x0b, 0x05, 0xf7, 0xc5, 0x8b, 0x89, 0x33, 0xb5, 0x1b, 0x79, 0x76, 0xf9, 0x2a, 0x89, 0xf7,
+	0x38, 0x49, 0x9c, 0x2c, 0x25, 0xb4, 0x2b, 0x90, 0x0f, 0x82, 0xc9, 0xf0, 0xcc, 0x6f, 0x14, 0x50,
+	0x36, 0x83, 0xae, 0x0e, 0x26, 0x4f, 0x7c, 0xed, 0x1a, 0x14, 0xc7, 0xf6, 0xc4, 0x3a, 0x27, 0x46,
+	0x91, 0x31, 0x0a, 0x6c, 0x8d, 0x2c, 0x7a, 0x37, 0xcf, 0x71, 0x3d, 0x27, 0x38, 0x6f, 0x94, 0x98,
+	0x27, 0xc3, 0x75, 0xf3, 0x67, 0x50, 0x56, 0x34, 0x22, 0xbf, 0xbc, 0xb4, 0xcf, 0x85, 0xa3, 0xe8,
+	0x27, 0x3d, 0xec, 0x6b, 0x6b, 0x32, 0x97, 0x68, 0xe0, 0x8b, 0x07, 0xe9, 0x9f, 0xa6, 0xf4, 0x7f,
+	0xa4, 0xa1, 0x4c, 0x26, 0x99, 0xf6, 0xab, 0xb9, 0xed, 0x07, 0x8a, 0x07, 0x53, 0xef, 0xe1, 0xc1,
+	0xf4, 0x3b, 0x3c, 0xf8, 0x6d, 0xe8, 0xc1, 0x0c, 0xf3, 0xe0, 0x75, 0xe9, 0x41, 0x71, 0xdd, 0xbb,
+	0x1c, 0x99, 0xfd, 0x00, 0x47, 0xe6, 0x2e, 0x72, 0x64, 0xfe, 0x62, 0x47, 0x16, 0x3e, 0x9d, 0x23,
+	0xff, 0x97, 0xe2, 0x21, 0xda, 0xc6, 0x30, 0x58, 0x40, 0x6a, 0xe4, 0xd5, 0xf4, 0x85, 0x5e, 0xd5,
+	0x20, 0x7b, 0xec, 0x8e, 0xcf, 0x19, 0x72, 0x2b, 0x26, 0xfb, 0x4d, 0x0a, 0xf8, 0xf6, 0x2b, 0xe6,
+	0x86, 0x8c, 0x49, 0x3f, 0xb5, 0x6f, 0x12, 0x98, 0x6c, 0x48, 0x8f, 0xd2, 0xbd, 0x4b, 0xdd, 0xb9,
+	0x01, 0x45, 0x7b, 0x3a, 0x72, 0xc7, 0xce, 0xf4, 0x44, 0x00, 0x93, 0x05, 0x99, 0x21, 0x68, 0x66,
+	0xc8, 0xfd, 0x18, 0xeb, 0xef, 0x41, 0x89, 0x94, 0x78, 0x68, 0x05, 0xa3, 0x53, 0xb4, 0x36, 0x37,
+	0x42, 0x6d, 0x78, 0x62, 0x50, 0x62, 0x9a, 0x54, 0x34, 0x39, 0x4b, 0xff, 0x73, 0x0a, 0x2a, 0x87,
+	0xf3, 0x63, 0x7f, 0xe4, 0x39, 0xb3, 0xc0, 0x71, 0xa7, 0xf4, 0x62, 0x94, 0xf3, 0x86, 0xce, 0x58,
+	0x26, 0x94, 0x02, 0xad, 0x3b, 0x63, 0x5f, 0x7b, 0x00, 0x45, 0xdf, 0x9e, 0xd8, 0xa3, 0xc0, 0xf5,
+	0xf0, 0x66, 0x3a, 0xf2, 0x16, 0x1d, 0xa9, 0x6e, 0xdf, 0x3a, 0x14, 0x02, 0xdc, 0xf6, 0x50, 0xbe,
+	0xf9, 0x73, 0xa8, 0xc6, 0x58, 0x1f, 0x64, 0xd5, 0xdf, 0x32, 0x00, 0x83, 0xf9, 0x74, 0x6a, 0x4f,
+	0x7a, 0x33, 0x7b, 0xaa, 0x6d, 0xc3, 0x95, 0x53, 0xdb, 0xf2, 0x82, 0x63, 0xdb, 0x0a, 0x86, 0xce,
+	0x34, 0xb0, 0x3d, 0x14, 0x25, 0x84, 0xa5, 0xd8, 0xfb, 0x5c, 0x0e, 0x99, 0x1d, 0xc1, 0x43, 0xb4,
+	0xfd, 0x00, 0x2a, 0x9e, 0xed, 0xcf, 0xcf, 0xec, 0x21, 0xcf, 0xac, 0xfc, 0x8e, 0x32, 0xa7, 0x0d,
+	0x88, 0x44, 0x96, 0x4f, 0x2c, 0x3f, 0x18, 0xd2, 0x4b, 0x67, 0x38, 0x56, 0x69, 0x7d, 0x88, 0xaf,
+	0x7d, 0x07, 0x6a, 0xa3, 0x89, 0x63, 0x4f, 0x83, 0xe1, 0x6b, 0xdb, 0xf3, 0xd1, 0x4e, 0x91, 0x99,
+	0xab, 0x9c, 0xfa, 0x8c, 0x13, 0x51, 0xb1, 0x38, 0x28, 0x9a, 0xe4, 0x9e, 0x48, 0xf1, 0xa5, 0xb0,
+	0xb8, 0x0f, 0x15, 0x5f, 0x71, 0x20, 0x83, 0x86, 0x08, 0x65, 0xd5, 0xb1, 0x66, 0x4c, 0x4a, 0xfb,
+	0x1a, 0xea, 0xac, 0x28, 0x8d, 0xdc, 0x49, 0xa8, 0x12, 0x0f, 0xa2, 0x15, 0x49, 0x97, 0x4a, 0x7d,
+	0x06, 0x65, 0xa1, 0x3b, 0xab, 0x43, 0x45, 0xa6, 0x38, 0x70, 0x52, 0x97, 0x2a, 0x91, 0x0e, 0x95,
+	0x91, 0x35, 0xb3, 0x8e, 0x9d, 0x89, 0x13, 0x50, 0x7d, 0x28, 0xf1, 0x32, 0xa2, 0xd2, 0x3e, 0x06,
+	0x92, 0x7f, 0xc4, 0xcc, 0xc6, 0x7d, 0x60, 0xda, 0x16, 0xc6, 0xd2, 0x4d, 0x00, 0x1f, 0x73, 0x12,
+	0xaa, 0x36, 0x0c, 0x63, 0xb3, 0x24, 0x28, 0x9d, 0xf1, 0xc5, 0x8f, 0x9b, 0x7e, 0xff, 0xc7, 0xcd,
+	0x2c, 0x3e, 0x6e, 0x03, 0x0a, 0x7c, 0x39, 0x66, 0x4f, 0x57, 0x34, 0xe5, 0x72, 0xa9, 0x2b, 0x73,
+	0xcb, 0x5d, 0x89, 0x30, 0xc0, 0xf2, 0x8d, 0x42, 0xa1, 0x60, 0x9e, 0xc3, 0x80, 0x53, 0xa5, 0x58,
+	0xd2, 0xa1, 0x85, 0x45, 0x87, 0xea, 0x4f, 0xa1, 0xb4, 0xeb, 0x62, 0xb0, 0xb7, 0xde, 0x58, 0xe7,
+	0x58, 0xe0, 0x34, 0xcf, 0x1e, 0xb9, 0xe8, 0xa3, 0x51, 0x30, 0x0c, 0xf3, 0x25, 0x47, 0x73, 0x3d,
+	0xe4, 0xec, 0x88, 0xc4, 0x79, 0x15, 0xf2, 0x9e, 0x6d, 0xf9, 0xae, 0x04, 0xb1, 0x58, 0xe9, 0x7d,
+	0x1e, 0xfb, 0xc6, 0x6b, 0x7c, 0x58, 0x54, 0x55, 0x6d, 0x4e, 0x56, 0x65, 0xe8, 0x33, 0xa6, 0xd2,
+	0xa1, 0xdc, 0x80, 0x2c, 0x45, 0xb7, 0x28, 0x20, 0xc5, 0xb0, 0x2c, 0x30, 0xaa, 0xfe, 0x88, 0x82,
+	0x96, 0x3d, 0xcc, 0xbe, 0x33, 0x7a, 0x89, 0xbe, 0x7a, 0xc7, 0xdb, 0x5d, 0xa4, 0xd9, 0x63, 0xa8,
+	0x3c, 0x9d, 0xbb, 0x81, 0xf5, 0xdc, 0xf2, 0xa6, 0x68, 0x33, 0x81, 0x65, 0xe2, 0x9c, 0x39, 0x81,
+	0x38, 0x81, 0x2f, 0x28, 0xf1, 0x62, 0x77, 0x34, 0x16, 0x0f, 0xcd, 0x7e, 0x13, 0xd0, 0xce, 0xac,
+	0xb7, 0x22, 0x1c, 0xe9, 0xa7, 0xfe, 0x25, 0x54, 0xd8, 0x8b, 0x9a, 0xf6, 0x6b, 0x97, 0x54, 0x8a,
+	0xee, 0x4c, 0xc5, 0xee, 0xdc, 0xc0, 0x6e, 0x69, 0x3a, 0x75, 0xe7, 0xd3, 0x91, 0x7d, 0x46, 0x0e,
+	0x41, 0x00, 0x9c, 0xa1, 0xa2, 0xd6, 0x89, 0x2d, 0x04, 0xe5, 0x52, 0x6f, 0x40, 0xb6, 0x4f, 0x5a,
+	0x89, 0x24, 0x9f, 0x0a, 0x93, 0x3c, 0xe3, 0xb8, 0x4b, 0x39, 0x7f, 0x48, 0x41, 0x55, 0x82, 0x9a,
+	0x17, 0xec, 0xdb, 0x90, 0x75, 0x31, 0xc6, 0x99, 0x50, 0x79, 0xbb, 0x16, 0x8f, 0xfc, 0xbd, 0x4b,
+	0x26, 0xe3, 0x6a, 0xb7, 0x20, 0x3b, 0xc3, 0x13, 0x55, 0x7f, 0xd3, 0x0d, 0xc4, 0x27, 0x3a, 0x96,
+	0x95, 0x92, 0x88, 0xf3, 0x63, 0x5b, 0xb4, 0x62, 0x0b, 0xa9, 0x00, 0x85, 0x23, 0xa1, 0x87, 0x05,
+	0xc8, 0xd9, 0xf4, 0xa8, 0xfa, 0x7f, 0x32, 0x50, 0x93, 0x2a, 0xf9, 0x78, 0x98, 0x4f, 0x91, 0xcd,
+	0x5f, 0x37, 0x15, 0xef, 0xe9, 0x28, 0xff, 0xd3, 0x8d, 0xc4, 0xc3, 0x06, 0x31, 0xe7, 0x51, 0x5c,
+	0x0a, 0x95, 0x56, 0x22, 0xc5, 0x59, 0xb8, 0xa2, 0x1c, 0xe7, 0x33, 0xd5, 0xa9, 0x76, 0x65, 0x14,
+	0xd5, 0x1d, 0xa1, 0x3a, 0xb9, 0x6f, 0x0b, 0xe0, 0x84, 0x10, 0x3d, 0xb4, 0x10, 0xd2, 0xa2, 0xa7,
+	0xab, 0x92, 0x54, 0x88, 0x73, 0x52, 0xfc, 0x24, 0x04, 0xfd, 0x1d, 0xc8, 0x1d, 0x53, 0x99, 0x62,
+	0xc1, 0x26, 0x44, 0xc3, 0xda, 0x45, 0xd7, 0x32, 0x2e, 0x1d, 0xcb, 0xea, 0x11, 0x33, 0x52, 0x64,
+	0xc7, 0x6a, 0x0c, 0xce, 0x74, 0xec, 0x69, 0x08, 0xfc, 0x07, 0x14, 0xa3, 0x1c, 0xa2, 0x2f, 0x19,
+	0x68, 0x59, 0x5e, 0x2c, 0xf3, 0x10, 0x88, 0xa1, 0x19, 0xf7, 0x55, 0xfd, 0x18, 0xbc, 0xbf, 0x83,
+	0xea, 0x2b, 0xc2, 0xe9, 0xf0, 0x0d, 0x07, 0x2a, 0x4b, 0x96, 0xe2, 0x05, 0x54, 0x00, 0xe3, 0xce,
+	0xca, 0x2b, 0x15, 0xd0, 0xb8, 0x91, 0x65, 0x9e, 0xa1, 0xc7, 0x51, 0xc9, 0x3a, 0x43, 0xb1, 0x51,
+	0x45, 0x2b, 0x6d, 0x0c, 0x54, 0xf4, 0xfe, 0x04, 0x2a, 0x96, 0x82, 0xd2, 0x06, 0x28, 0x8d, 0x9c,
+	0x42, 0xa7, 0x7d, 0xaa, 0x5c, 0xf4, 0xea, 0x55, 0x4c, 0xcc, 0x8e, 0x1f, 0x08, 0x14, 0xea, 0x5b,
+	0x50, 0xe1, 0x4b, 0x81, 0x80, 0x5b, 0x90, 0x23, 0xd7, 0xc8, 0x16, 0x20, 0x0a, 0x70, 0x4e, 0xd6,
+	0xef, 0x43, 0xb5, 0x8d, 0xcf, 0x1b, 0xd8, 0x12, 0xc6, 0x5f, 0xc4, 0x20, 0xb3, 0x92, 0xe8, 0x13,
+	0x45, 0x5e, 0xd8, 0x82, 0x9a, 0xdc, 0x25, 0xee, 0xb9, 0x11, 0xdb, 0x96, 0xcc, 0x23, 0x9f, 0x41,
+	0x15, 0x93, 0x97, 0x1d, 0xdd, 0x92, 0xe8, 0xcb, 0xe8, 0x40, 0x29, 0xf0, 0x5e, 0x07, 0xfe, 0x3b,
+	0x05, 0xd0, 0x9a, 0x8f, 0x9d, 0x80, 0x97, 0xa3, 0x64, 0x9b, 0x87, 0x69, 0x0a, 0x1b, 0x5f, 0xf4,
+	0x56, 0x40, 0x69, 0x8a, 0xe7, 0xa2, 0x92, 0xa0, 0x74, 0xd8, 0x78, 0x65, 0xb1, 0x26, 0x86, 0xd7,
+	0x09, 0xbe, 0xa0, 0xf2, 0xcf, 0xdf, 0xd0, 0x19, 0x8b, 0xea, 0x5e, 0x60, 0x6b, 0xdc, 0x70, 0x1d,
+	0x4a, 0xa2, 0x84, 0x3a, 0x33, 0x06, 0x57, 0x1c, 0xd6, 0x38, 0xa1, 0x33, 0x43, 0x4d, 0x4b, 0x18,
+	0xda, 0x9e, 0x15, 0x44, 0xf5, 0x20, 0x22, 0x50, 0x7a, 0x3a, 0xb6, 0x5f, 0xb8, 0x9e, 0xcd, 0x60,
+	0x58, 0x31, 0xc5, 0x8a, 0xe9, 0xf0, 0x02, 0x0b, 0x18, 0x83, 0x58, 0xc5, 0xe4, 0x0b, 0x4a, 0x81,
+	0x81, 0x83, 0x45, 0xba, 0xc4, 0x53, 0x20, 0xfd, 0xd6, 0x57, 0x61, 0x85, 0x99, 0x7a, 0xe0, 0x9e,
+	0xc8, 0x57, 0xfe, 0x05, 0xd4, 0x23, 0x92, 0x70, 0xd8, 0x06, 0x14, 0x50, 0x1f, 0x8f, 0xea, 0x0d,
+	0x7f, 0xeb, 0x1a, 0x1f, 0x48, 0xa5, 0x93, 0x4c, 0xc9, 0xd6, 0xff, 0x9e, 0x81, 0x82, 0x08, 0x84,
+	0x05, 0xcf, 0x61, 0x53, 0xee, 0x4c, 0xfd, 0xc0, 0x42, 0x9c, 0xc9, 0x89, 0x55, 0xae, 0x49, 0x65,
+	0xfc, 0xe5, 0x05, 0x22, 0x1b, 0xf3, 0x45, 0xdc, 0x37, 0xd9, 0x84, 0x6f, 0x16, 0xfb, 0xa6, 0xdc,
+	0xb2, 0xbe, 0xe9, 0x5e, 0xd8, 0x37, 0xe5, 0x99, 0xea, 0xeb, 0x4a, 0xac, 0x2e, 0x6d, 0x9a, 0xd0,
+	0xe7, 0x58, 0x26, 0x1d, 0x3c, 0x54, 0xc4, 0x77, 0xc6, 0x8c, 0x08, 0x0b, 0x2d, 0x55, 0xf1, 0xbd,
+	0x5a, 0xaa, 0x44, 0x9f, 0x54, 0x5a, 0xe8, 0x93, 0x96, 0x35, 0x0a, 0xb0, 0xbc, 0x51, 0x48, 0x76,
+	0x00, 0xe5, 0x4f, 0xdb, 0x52, 0x5d, 0x81, 0xcb, 0x14, 0xe5, 0xc2, 0x43, 0xbe, 0x84, 0xc5, 0x2f,
+	0x61, 0x2d, 0x4e, 0x16, 0xd0, 0xf8, 0x8a, 0xfa, 0x76, 0x4e, 0x13, 0xd8, 0x28, 0x2b, 0x0e, 0x36,
+	0x43, 0xa6, 0x7e, 0x1b, 0x34, 0xca, 0x84, 0x92, 0x71, 0x41, 0xb0, 0xe2, 0xed, 0x31, 0x29, 0x7e,
+	0x0b, 0xe1, 0xb4, 0x8f, 0x3d, 0x15, 0x4e, 0x31, 0x32, 0xcc, 0xf5, 0x3d, 0xa8, 0x47, 0x24, 0xa1,
+	0x0c, 0x06, 0x84, 0x3b, 0x65, 0x43, 0x65, 0x8a, 0xf5, 0x61, 0x62, 0x45, 0x4f, 0x2a, 0x91, 0xe6,
+	0xb3, 0xe9, 0x02, 0xc3, 0x28, 0x24, 0xe8, 0x7f, 0x4d, 0x03, 0xec, 0x60, 0x19, 0x3a, 0xb0, 0x03,
+	0x8a, 0x93, 0x24, 0x6c, 0xd7, 0xa1, 0x20, 0x86, 0x16, 0xd9, 0x79, 0xf0, 0x99, 0x85, 0x6e, 0x53,
+	0x3e, 0x44, 0x94, 0x16, 0x86, 0xbc, 0xac, 0x32, 0xe4, 0x2d, 0xed, 0xde, 0xa3, 0x4b, 0x97, 0x02,
+	0x51, 0x74, 0x06, 0xf9, 0x68, 0x30, 0x8c, 0xfa, 0x91, 0x82, 0xda, 0x8f, 0x50, 0x64, 0x59, 0x78,
+	0xcc, 0xd9, 0x2c, 0xe0, 0x9f, 0x14, 0x70, 0xdc, 0x95, 0xeb, 0x65, 0x61, 0xff, 0x31, 0xf0, 0x68,
+	0xc0, 0x55, 0xc2, 0x41, 0xa4, 0x7a, 0x88, 0x90, 0x03, 0x58, 0x5f, 0xe0, 0x88, 0x77, 0xf9, 0x11,
+	0x54, 0xc6, 0x48, 0x1e, 0x4e, 0x38, 0x5d, 0x4d, 0x22, 0x91, 0xb8, 0x59, 0x1e, 0x47, 0x5b, 0xb1,
+	0x15, 0x5b, 0xdb, 0xb5, 0x95, 0xc3, 0x2e, 0x02, 0xcc, 0x1e, 0x5c, 0x49, 0xc8, 0x89, 0x3b, 0xef,
+	0x41, 0x59, 0xb9, 0x53, 0x6d, 0x9d, 0x14, 0x61, 0x88, 0xae, 0xd4, 0x37, 0xa1, 0xc1, 0x2e, 0x99,
+	0xdb, 0xef, 0xbe, 0xf5, 0x3a, 0x5c, 0x5b, 0x22, 0x2b, 0xc0, 0x7a, 0x17, 0xd6, 0xfb, 0x73, 0xef,
+	0xc4, 0x5e, 0xf4, 0x11, 0x79, 0x3a, 0x9a, 0x7d, 0xe9, 0xa7, 0xbe, 0x0d, 0x8d, 0x45, 0xe1, 0x08,
+	0xce, 0x33, 0xe2, 0xf1, 0x9b, 0x73, 0xa6, 0x58, 0xe9, 0x7f, 0x49, 0x43, 0xf5, 0x29, 0x5d, 0x1e,
+	0x7e, 0x8b, 0x58, 0x68, 0x22, 0x85, 0xc6, 0xe9, 0x10, 0xc5, 0x1f, 0x02, 0xd6, 0x1f, 0x27, 0xc0,
+	0x7a, 0x93, 0xf7, 0x28, 0xca, 0x85, 0x4b, 0xf1, 0x8a, 0x5d, 0xb0, 0xfd, 0x76, 0xe6, 0x60, 0x50,
+	0xca, 0xcf, 0x31, 0x62, 0x49, 0x35, 0x73, 0xea, 0x06, 0x43, 0xa5, 0x58, 0x61, 0x4e, 0x45, 0xca,
+	0x43, 0x5e, 0xaf, 0xd4, 0xaf, 0x35, 0xc5, 0x4f, 0xf7, 0xb5, 0xe6, 0x9f, 0x58, 0xc8, 0x99, 0xd6,
+	0xbc, 0x79, 0xfb, 0x32, 0x36, 0xb5, 0x68, 0xa1, 0x4d, 0x1f, 0x34, 0xb6, 0x24, 0xa6, 0x94, 0x4c,
+	0x72, 0x4a, 0x09, 0xa7, 0x8f, 0xec, 0xb2, 0xe9, 0x23, 0xb7, 0x38, 0x7d, 0xe4, 0xc3, 0xe9, 0x43,
+	0x9d, 0x22, 0x0a, 0xf1, 0x29, 0xe2, 0xbf, 0x29, 0xf1, 0xe0, 0xc6, 0xf4, 0xb5, 0x3d, 0xc1, 0x06,
+	0x80, 0x64, 0x65, 0x99, 0x20, 0x7b, 0xaa, 0xa6, 0x5c, 0x62, 0xad, 0xe6, 0x66, 0xf2, 0x8f, 0x50,
+	0x6b, 0xa1, 0x99, 0x4f, 0xf8, 0x59, 0x8a, 0xa1, 0xf1, 0x4e, 0x26, 0x93, 0xec, 0x64, 0xe2, 0x96,
+	0x66, 0x93, 0x96, 0xde, 0x11, 0x6e, 0xca, 0x45, 0x1d, 0x70, 0x0c, 0x22, 0xc2, 0x5f, 0x51, 0xca,
+	0xca, 0xc7, 0x52, 0xd6, 0x6d, 0xd1, 0x64, 0x8a, 0x0e, 0xba, 0x16, 0x7f, 0x0e, 0x93, 0x33, 0x37,
+	0xf7, 0x20, 0xcf, 0xbf, 0xa0, 0x69, 0x65, 0x28, 0x1c, 0x75, 0xf7, 0xbb, 0xbd, 0xe7, 0xdd, 0xfa,
+	0x25, 0xad, 0x00, 0x99, 0x5d, 0x63, 0x50, 0x4f, 0x69, 0x45, 0x1c, 0xa2, 0x7a, 0x87, 0x83, 0x7a,
+	0x9a, 0x48, 0xfd, 0xa3, 0x41, 0x3d, 0xa3, 0x95, 0x20, 0xd7, 0x6f, 0x0d, 0xda, 0x7b, 0xf5, 0xac,
+	0x06, 0x90, 0xdf, 0x31, 0x0e, 0x8c, 0x81, 0x51, 0xcf, 0x6d, 0x3e, 0x80, 0xa2, 0xfc, 0x80, 0xae,
+	0x55, 0xa1, 0xd4, 0x3a, 0x1a, 0xec, 0x0d, 0xbb, 0xbd, 0xae, 0x81, 0xa7, 0xd5, 0xb0, 0xdf, 0xa3,
+	0xe5, 0xc3, 0xd6, 0x61, 0xa7, 0x8d, 0x87, 0xae, 0x40, 0x99, 0xaf, 0x8d, 0x96, 0x69, 0x98, 0xf5,
+	0xf4, 0xe6, 0x7d, 0x28, 0xca, 0x2f, 0x69, 0xda, 0x15, 0x58, 0x35, 0xba, 0xed, 0xde, 0x4e, 0xa7,
+	0xbb, 0x3b, 0xec, 0xec, 0x18, 0xdd, 0x41, 0x67, 0xf0, 0x6b, 0x3c, 0x63, 0x15, 0xaa, 0x21, 0x79,
+	0xf7, 0x37, 0x9d, 0x7e, 0x3d, 0xb5, 0xf9, 0x18, 0xaa, 0xb1, 0xef, 0x99, 0x88, 0x82, 0x5a, 0xef,
+	0xd1, 0xa3, 0x83, 0x4e, 0xd7, 0x18, 0xb6, 0xda, 0x6d, 0xa3, 0x3f, 0xe0, 0xfb, 0x24, 0xed, 0xe9,
+	0x91, 0x71, 0x64, 0xe0, 0xf5, 0x8a, 0x98, 0x69, 0x3c, 0x36, 0xda, 0x68, 0xdd, 0xa6, 0x0b, 0xd5,
+	0xd8, 0x84, 0x8d, 0x6e, 0xd5, 0xf6, 0x7a, 0xbd, 0xfd, 0xa1, 0xf1, 0x0c, 0x55, 0x18, 0x46, 0x9e,
+	0xa9, 0x43, 0x85, 0xd1, 0xdb, 0xa6, 0xd1, 0x1a, 0x18, 0x3b, 0x78, 0x9c, 0xa4, 0x1c, 0xf5, 0x77,
+	0x18, 0x25, 0x1d, 0x52, 0xb8, 0x6f, 0x76, 0xd0, 0x67, 0x92, 0x62, 0x7c, 0xdf, 0xef, 0x98, 0x48,
+	0xc9, 0x6e, 0x4e, 0xa0, 0x9e, 0x44, 0x0d, 0xe9, 0xca, 0x74, 0x54, 0xae, 0x43, 0xd7, 0x71, 0x12,
+	0x6d, 0xc7, 0xcb, 0xd6, 0x70, 0x1b, 0x5b, 0xef, 0x74, 0x0e, 0xdb, 0xbd, 0x6e, 0x97, 0x69, 0x1f,
+	0x49, 0xed, 0x77, 0xda, 0xfb, 0x78, 0x1d, 0x3a, 0x98, 0xaf, 0x99, 0xf6, 0x78, 0xdb, 0x9f, 0xd2,
+	0x50, 0x8b, 0xc7, 0x22, 0x56, 0xdd, 0xcb, 0x8a, 0x8c, 0x72, 0xe5, 0x0d, 0x68, 0xa8, 0x8c, 0x84,
+	0xb5, 0xcb, 0xb8, 0x91, 0xe5, 0xcb, 0xb8, 0x91, 0x17, 0x96, 0x71, 0x43, 0x8f, 0xe0, 0xb4, 0xd3,
+	0x54, 0xb9, 0x87, 0xc6, 0xe1, 0x61, 0xa7, 0xd7, 0x65, 0x26, 0x21, 0x3f, 0x87, 0xe1, 0x72, 0x4d,
+	0xe5, 0x3f, 0x3d, 0xea, 0x0d, 0x5a, 0xc3, 0xe7, 0x2d, 0xb3, 0x8b, 0x90, 0xa8, 0xe7, 0x93, 0xec,
+	0x41, 0x6f, 0xdf, 0xe8, 0xe2, 0xfb, 0x3e, 0xeb, 0xd1, 0xee, 0x42, 0xf2, 0xee, 0x56, 0xb7, 0xdb,
+	0x3b, 0xea, 0xb6, 0x8d, 0x27, 0xe4, 0x9f, 0xe2, 0xf6, 0xef, 0xf3, 0x90, 0xdf, 0x65, 0x7f, 0x17,
+	0x51, 0x6e, 0xe6, 0x13, 0xb4, 0xb6, 0xaa, 0x4e, 0xd3, 0xac, 0xbc, 0x34, 0x35, 0x95, 0x24, 0xaa,
+	0xd1, 0xa5, 0x8d, 0xd4, 0x37, 0x29, 0xed, 0x2e, 0x64, 0xa9, 0x38, 0x6b, 0x6c, 0xe8, 0x52, 0x86,
+	0xba, 0x66, 0x3d, 0x22, 0xc8, 0x0d, 0x58, 0xae, 0xf3, 0x7c, 0x04, 0xe3, 0x77, 0xc4, 0x86, 0x38,
+	0x7e, 0x47, 0x7c, 0x42, 0xe3, 0x5b, 0xf8, 0x90, 0xc5, 0xb7, 0xc4, 0x26, 0x32, 0xbe, 0x25, 0x3e,
+	0x83, 0xe1, 0x96, 0xef, 0x28, 0x22, 0xf9, 0xa0, 0xa1, 0x5d, 0x0e, 0xe7, 0x89, 0x68, 0x12, 0x69,
+	0xae, 0xc5, 0x89, 0xe1, 0xc6, 0x36, 0x9f, 0x43, 0x65, 0x2b, 0xaa, 0xad, 0x4b, 0x13, 0x12, 0x3d,
+	0x6b, 0xb3, 0xb1, 0xc8, 0x08, 0x0f, 0xf9, 0x15, 0x94, 0x95, 0x46, 0x53, 0xbb, 0x4a, 0xa2, 0x8b,
+	0xfd, 0x69, 0x73, 0x7d, 0x81, 0xae, 0xea, 0x2f, 0x1b, 0x50, 0xae, 0x7f, 0xa2, 0x43, 0xe5, 0xfa,
+	0x27, 0x7b, 0x54, 0xdc, 0x78, 0x00, 0x2b, 0x89, 0x46, 0x49, 0x6b, 0x4a, 0x4d, 0x17, 0x7b, 0x86,
+	0xe6, 0xf5, 0xa5, 0xbc, 0xf0, 0xb4, 0x47, 0x50, 0x8d, 0x35, 0x40, 0x1a, 0xb3, 0x7a, 0x59, 0xef,
+	0xd4, 0xbc, 0xb6, 0x84, 0x13, 0x9e, 0x63, 0xc2, 0xea, 0x42, 0x4b, 0xa3, 0xdd, 0xa0, 0x1d, 0x17,
+	0x75, 0x45, 0xcd, 0x9b, 0x17, 0x70, 0xc3, 0x33, 0x7b, 0xd8, 0xa3, 0x27, 0x9a, 0x1b, 0x8d, 0x99,
+	0x73, 0x41, 0x7f, 0xd4, 0xbc, 0xb1, 0x9c, 0x29, 0x0f, 0x3c, 0xce, 0xb3, 0x61, 0xe8, 0xdb, 0xff,
+	0x03, 0x0d, 0x64, 0x64, 0xaa, 0x3d, 0x1d, 0x00, 0x00,
 }
@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/satori/go.uuid"
+)
+
+const (
+	// DelayedKey is the sorted set of the delayed hook calls, scored
+	// by when they are due in unix milliseconds.
+	DelayedKey = "delayed:hooks"
+	// DelayedDataKey is the hash of the encoded delayed calls.
+	DelayedDataKey = "delayed:hooks:data"
+)
+
+// dueScript hands out the calls due by ARGV[1] and moves them to the
+// end of their lease ARGV[2], so only one process releases a call at
+// a time. It returns pairs of ids and encoded messages.
+var dueScript = redis.NewScript(2, `
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[3])
+local due = {}
+for _, id in ipairs(ids) do
+  local data = redis.call("HGET", KEYS[2], id)
+  if data then
+    redis.call("ZADD", KEYS[1], ARGV[2], id)
+    table.insert(due, id)
+    table.insert(due, data)
+  else
+    redis.call("ZREM", KEYS[1], id)
+  end
+end
+return due
+`)
+
+// delayedCalls keeps the delayed hook calls of the Redis queues. They
+// are stored in Redis, so they outlive the process that took them and
+// are released by whichever process asks for them first.
+type delayedCalls struct {
+	pool *redis.Pool
+}
+
+func (d delayedCalls) Delay(m *tunnel.QueueMessage) error {
+	conn := d.pool.Get()
+	defer conn.Close()
+
+	data, err := marshalMessage(m)
+	if err != nil {
+		return err
+	}
+
+	id := uuid.NewV4().String()
+	conn.Send("MULTI")
+	conn.Send("HSET", DelayedDataKey, id, data)
+	conn.Send("ZADD", DelayedKey, unixMilli(m.Hook.NotBefore), id)
+	_, err = conn.Do("EXEC")
+	return err
+}
+
+func (d delayedCalls) Due(now time.Time, max int) ([]*tunnel.QueueMessage, error) {
+	conn := d.pool.Get()
+	defer conn.Close()
+
+	reply, err := redis.ByteSlices(dueScript.Do(conn, DelayedKey, DelayedDataKey,
+		unixMilli(now), unixMilli(now.Add(tunnel.DelayLease)), max))
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := []*tunnel.QueueMessage{}
+	for i := 0; i+1 < len(reply); i += 2 {
+		id := string(reply[i])
+		msg, err := unmarshalMessage(reply[i+1])
+		if err != nil {
+			// left in place for a newer release to read
+			fmt.Printf("[redis] Failed to decode delayed message %s. %v\n", id, err)
+			continue
+		}
+		msg.Ack = func() error {
+			return d.ack(id)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (d delayedCalls) ack(id string) error {
+	conn := d.pool.Get()
+	defer conn.Close()
+
+	conn.Send("MULTI")
+	conn.Send("ZREM", DelayedKey, id)
+	conn.Send("HDEL", DelayedDataKey, id)
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+)
+
+// RedisPresence keeps the instances of every account in a sorted set
+// scored by the time their presence expires.
+type RedisPresence struct {
+	pool *redis.Pool
+}
+
+func NewRedisPresence(address string) (tunnel.Presence, error) {
+	p := &RedisPresence{
+		pool: newPool(address),
+	}
+
+	// ensure redis connection is up
+	if err := pingRedis(p.pool); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func presenceKey(accountId user.AccountId) string {
+	return fmt.Sprintf("presence:%s", accountId)
+}
+
+func unixMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func (p *RedisPresence) Put(accountId user.AccountId, instance tunnel.InstanceId) error {
+	conn := p.pool.Get()
+	defer conn.Close()
+
+	key := presenceKey(accountId)
+	conn.Send("MULTI")
+	conn.Send("ZADD", key, unixMillis(time.Now().Add(tunnel.PresenceExpiry)), string(instance))
+	conn.Send("PEXPIRE", key, int64(tunnel.PresenceExpiry/time.Millisecond))
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func (p *RedisPresence) Remove(accountId user.AccountId, instance tunnel.InstanceId) error {
+	conn := p.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("ZREM", presenceKey(accountId), string(instance))
+	return err
+}
+
+func (p *RedisPresence) Find(accountId user.AccountId) (*tunnel.PresenceInfo, error) {
+	conn := p.pool.Get()
+	defer conn.Close()
+
+	key := presenceKey(accountId)
+	instances, err := redis.Strings(conn.Do("ZRANGEBYSCORE", key, unixMillis(time.Now()), "+inf"))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &tunnel.PresenceInfo{
+		AccountId: accountId,
+		Instances: []tunnel.InstanceId{},
+	}
+	for _, instance := range instances {
+		info.Instances = append(info.Instances, tunnel.InstanceId(instance))
+	}
+	return info, nil
+}
+
+func (p *RedisPresence) Close() error {
+	return p.pool.Close()
+}
@@ -3,28 +3,62 @@ package redis
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"github.com/cenkalti/backoff"
 	"github.com/garyburd/redigo/redis"
 	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"sync"
 	"time"
 )
 
+// SubscriberRoomName is the channel of the messages for every process,
+// such as announcements. The messages of an account are published to
+// its own channel, which a process only subscribes to while it holds
+// sessions of the account.
 const SubscriberRoomName = "HOOKS"
 
+var ErrListenerReconnecting = errors.New("Queue Listener Reconnecting")
+
+// listenRetryTimeout is how long a listener tries to reconnect before
+// it gives up and closes its channel.
+const listenRetryTimeout = 5 * time.Minute
+
+func accountChannel(accountId user.AccountId) string {
+	return fmt.Sprintf("%s:%s", SubscriberRoomName, accountId)
+}
+
 type RedisQueue struct {
 	pool     *redis.Pool
 	receivec tunnel.ReceiveC
+	channels *channels
+	health   *listenerHealth
+	delayed  delayedCalls
+}
+
+// channels counts the subscriptions to the account channels. The
+// pubsub connection is set once Listen has been called.
+type channels struct {
+	mtx    sync.Mutex
+	counts map[user.AccountId]int
+	psc    *redis.PubSubConn
 }
 
 func NewRedisQueue(address string) (tunnel.HookQueue, error) {
+	pool := newPool(address)
 	q := &RedisQueue{
-		pool:     newPool(address),
+		pool:     pool,
 		receivec: make(tunnel.ReceiveC),
+		channels: &channels{
+			counts: make(map[user.AccountId]int),
+		},
+		health:  &listenerHealth{},
+		delayed: delayedCalls{pool},
 	}
 
 	// ensure redis connection is up
-	if err := q.pingRedis(); err != nil {
+	if err := pingRedis(q.pool); err != nil {
 		return nil, err
 	}
 
@@ -49,9 +83,9 @@ func newPool(address string) *redis.Pool {
 	}
 }
 
-func (i *RedisQueue) pingRedis() error {
+func pingRedis(pool *redis.Pool) error {
 	return backoff.Retry(func() error {
-		con := i.pool.Get()
+		con := pool.Get()
 		defer con.Close()
 
 		_, err := con.Do("PING")
@@ -65,14 +99,44 @@ func (i *RedisQueue) pingRedis() error {
 	}, backoff.NewExponentialBackOff())
 }
 
+// listenerHealth records why a listener is not receiving messages.
+type listenerHealth struct {
+	mtx sync.Mutex
+	err error
+}
+
+func (h *listenerHealth) set(err error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.err = err
+}
+
+func (h *listenerHealth) get() error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.err
+}
+
+// retryListen calls connect with backoff until it succeeds or
+// listenRetryTimeout has passed.
+func retryListen(connect func() error) error {
+	b := backoff.NewExponentialBackOff()
+	b.MaxElapsedTime = listenRetryTimeout
+	return backoff.Retry(connect, b)
+}
+
+// marshalMessage encodes queue messages as protobuf envelopes.
 func marshalMessage(m *tunnel.QueueMessage) ([]byte, error) {
-	buf := new(bytes.Buffer)
-	enc := gob.NewEncoder(buf)
-	err := enc.Encode(m)
-	return buf.Bytes(), err
+	return tunnel.EncodeQueueMessage(m)
 }
 
+// unmarshalMessage also reads the gob payloads written by earlier
+// releases, so messages published during a rolling deploy are not
+// lost. The gob fallback can go once every process writes envelopes.
 func unmarshalMessage(b []byte) (*tunnel.QueueMessage, error) {
+	if tunnel.IsEnvelope(b) {
+		return tunnel.DecodeQueueMessage(b)
+	}
 	m := &tunnel.QueueMessage{}
 	buf := bytes.NewBuffer(b)
 	dec := gob.NewDecoder(buf)
@@ -86,52 +150,195 @@ func (i *RedisQueue) Close() error {
 
 func (i RedisQueue) Broadcast(m *tunnel.QueueMessage) error {
 	conn := i.pool.Get()
+	defer conn.Close()
+
 	data, err := marshalMessage(m)
 	if err != nil {
 		return err
 	}
 
-	_, err = conn.Do("PUBLISH", SubscriberRoomName, data)
+	if m.AccountId == "" {
+		_, err = conn.Do("PUBLISH", SubscriberRoomName, data)
+		return err
+	}
+	if m.Type != tunnel.MessageHook {
+		_, err = conn.Do("PUBLISH", accountChannel(m.AccountId), data)
+		return err
+	}
+
+	// Processes from before the account channels only listen on the
+	// shared channel and read gob payloads. Hook calls are copied there
+	// until none of them is left running.
+	legacy := new(bytes.Buffer)
+	if err := gob.NewEncoder(legacy).Encode(legacyMessage{AccountId: m.AccountId, Hook: m.Hook, Copy: true}); err != nil {
+		return err
+	}
+	conn.Send("MULTI")
+	conn.Send("PUBLISH", accountChannel(m.AccountId), data)
+	conn.Send("PUBLISH", SubscriberRoomName, legacy.Bytes())
+	_, err = conn.Do("EXEC")
 	return err
 }
 
-func (i RedisQueue) Listen() (tunnel.ReceiveC, error) {
-	// subscribe and send messages
-	c := make(tunnel.ReceiveC)
+// legacyMessage is the gob payload of the hook calls copied to the
+// shared channel. Older processes ignore the Copy field.
+type legacyMessage struct {
+	AccountId user.AccountId
+	Hook      tunnel.HookCall
+	// set by processes that also publish on the account channel
+	Copy bool
+}
 
-	conn := i.pool.Get()
+// isLegacyCopy reports whether the payload is the copy of a hook call
+// published on its account channel as well.
+func isLegacyCopy(data []byte) bool {
+	if tunnel.IsEnvelope(data) {
+		return false
+	}
+	m := legacyMessage{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&m); err != nil {
+		return false
+	}
+	return m.Copy
+}
 
-	psc := redis.PubSubConn{conn}
+// Delay keeps the message in Redis until its call is due.
+func (i RedisQueue) Delay(m *tunnel.QueueMessage) error {
+	return i.delayed.Delay(m)
+}
 
-	err := psc.Subscribe(SubscriberRoomName)
+// Due hands out the delayed messages due by now.
+func (i RedisQueue) Due(now time.Time, max int) ([]*tunnel.QueueMessage, error) {
+	return i.delayed.Due(now, max)
+}
+
+// Subscribe starts receiving the messages of the account.
+func (i RedisQueue) Subscribe(accountId user.AccountId) error {
+	c := i.channels
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.counts[accountId]++
+	if c.counts[accountId] > 1 || c.psc == nil {
+		return nil
+	}
+	if err := c.psc.Subscribe(accountChannel(accountId)); err != nil {
+		// the connection is gone, the listener subscribes to every
+		// counted account once it has reconnected
+		fmt.Printf("[redis] Failed to subscribe to account %s. %v\n", accountId, err)
+	}
+	return nil
+}
+
+// Unsubscribe stops receiving the messages of the account once every
+// subscriber is gone.
+func (i RedisQueue) Unsubscribe(accountId user.AccountId) error {
+	c := i.channels
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.counts[accountId] == 0 {
+		return nil
+	}
+	c.counts[accountId]--
+	if c.counts[accountId] > 0 {
+		return nil
+	}
+	delete(c.counts, accountId)
+	if c.psc == nil {
+		return nil
+	}
+	return c.psc.Unsubscribe(accountChannel(accountId))
+}
+
+// Health reports whether the listener is receiving messages.
+func (i RedisQueue) Health() error {
+	return i.health.get()
+}
+
+// Listen receives the messages of every process and of the accounts
+// subscribed to. A lost connection is reconnected with backoff. The
+// channel is only closed once reconnecting has failed for
+// listenRetryTimeout.
+func (i RedisQueue) Listen() (tunnel.ReceiveC, error) {
+	c := make(tunnel.ReceiveC)
+
+	psc, err := i.subscribe()
 	if err != nil {
 		close(c)
 		return c, err
 	}
 
 	go func(c tunnel.ReceiveC) {
-		defer conn.Close()
 		defer close(c)
 
 		for {
-			switch v := psc.Receive().(type) {
-			case redis.Message:
-				msg, err := unmarshalMessage(v.Data)
-				if err != nil {
-					fmt.Printf("[redis] Failed to decode message. %v\n", err)
-					continue
-				}
-				c <- msg
-			case redis.Subscription:
-				fmt.Printf("[redis] Subscribed to channel: %s\n", v.Channel)
-			case error:
-				fmt.Printf("[redis] Error processing messages. Closing channel. %v\n", err)
+			err := i.receive(psc, c)
+			i.health.set(ErrListenerReconnecting)
+			fmt.Printf("[redis] Error processing messages. Reconnecting. %v\n", err)
+
+			err = retryListen(func() error {
+				psc, err = i.subscribe()
+				return err
+			})
+			if err != nil {
+				i.health.set(err)
+				fmt.Printf("[redis] Could not reconnect. Closing channel. %v\n", err)
 				return
-			default:
-				fmt.Printf("[redis] Received unknown message. Ignored: %#v\n", v)
 			}
+			i.health.set(nil)
 		}
 	}(c)
 
 	return c, nil
 }
+
+// subscribe opens a pubsub connection subscribed to the global channel
+// and to the accounts subscribed to so far. Later subscriptions are
+// made on the same connection.
+func (i RedisQueue) subscribe() (*redis.PubSubConn, error) {
+	conn := i.pool.Get()
+	psc := &redis.PubSubConn{conn}
+
+	i.channels.mtx.Lock()
+	defer i.channels.mtx.Unlock()
+	rooms := []interface{}{SubscriberRoomName}
+	for accountId := range i.channels.counts {
+		rooms = append(rooms, accountChannel(accountId))
+	}
+	if err := psc.Subscribe(rooms...); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	i.channels.psc = psc
+	return psc, nil
+}
+
+// receive hands the messages to c until the connection fails.
+func (i RedisQueue) receive(psc *redis.PubSubConn, c tunnel.ReceiveC) error {
+	defer func() {
+		i.channels.mtx.Lock()
+		i.channels.psc = nil
+		i.channels.mtx.Unlock()
+		psc.Close()
+	}()
+
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			if v.Channel == SubscriberRoomName && isLegacyCopy(v.Data) {
+				continue
+			}
+			msg, err := unmarshalMessage(v.Data)
+			if err != nil {
+				fmt.Printf("[redis] Failed to decode message. %v\n", err)
+				continue
+			}
+			c <- msg
+		case redis.Subscription:
+			fmt.Printf("[redis] %s channel: %s\n", v.Kind, v.Channel)
+		case error:
+			return v
+		default:
+			fmt.Printf("[redis] Received unknown message. Ignored: %#v\n", v)
+		}
+	}
+}
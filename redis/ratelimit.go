@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/gohook/gohook-server/user"
+)
+
+// tokenBucketScript refills and takes a token from a bucket stored
+// as a hash in a single atomic step. It returns whether a token was
+// taken and otherwise the milliseconds until the next one is ready.
+var tokenBucketScript = redis.NewScript(1, `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+  tokens = burst
+  ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait}
+`)
+
+// refundScript puts a token back into a bucket that still exists.
+var refundScript = redis.NewScript(1, `
+local burst = tonumber(ARGV[1])
+local tokens = tonumber(redis.call("HGET", KEYS[1], "tokens"))
+if tokens ~= nil then
+  redis.call("HSET", KEYS[1], "tokens", tostring(math.min(burst, tokens + 1)))
+end
+return 0
+`)
+
+// RedisRateLimiter keeps token buckets in redis so every gohookd
+// process enforces the same limits.
+type RedisRateLimiter struct {
+	pool *redis.Pool
+}
+
+func NewRedisRateLimiter(address string) (user.RateLimiter, error) {
+	l := &RedisRateLimiter{
+		pool: newPool(address),
+	}
+
+	// ensure redis connection is up
+	if err := pingRedis(l.pool); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *RedisRateLimiter) Take(key string, rate, burst int) (bool, time.Duration, error) {
+	if rate <= 0 {
+		return true, 0, nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	reply, err := redis.Values(tokenBucketScript.Do(conn, "ratelimit:"+key, rate, burst, now))
+	if err != nil {
+		return false, 0, err
+	}
+	var allowed, wait int64
+	if _, err := redis.Scan(reply, &allowed, &wait); err != nil {
+		return false, 0, err
+	}
+	return allowed == 1, time.Duration(wait) * time.Millisecond, nil
+}
+
+func (l *RedisRateLimiter) Refund(key string, rate, burst int) error {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	_, err := refundScript.Do(conn, "ratelimit:"+key, burst)
+	return err
+}
+
+func (l *RedisRateLimiter) Close() error {
+	return l.pool.Close()
+}
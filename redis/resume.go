@@ -0,0 +1,200 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+)
+
+// RedisResumeStore keeps the backlog of every account in a sorted set
+// scored by sequence number, so any gohookd process can resume a
+// session.
+type RedisResumeStore struct {
+	pool *redis.Pool
+}
+
+type backlogEntry struct {
+	Time time.Time
+	Hook tunnel.HookCall
+}
+
+func NewRedisResumeStore(address string) (tunnel.ResumeStore, error) {
+	r := &RedisResumeStore{
+		pool: newPool(address),
+	}
+
+	// ensure redis connection is up
+	if err := pingRedis(r.pool); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func backlogKey(accountId user.AccountId) string {
+	return fmt.Sprintf("backlog:%s", accountId)
+}
+
+// The sequence counter never expires so sequence numbers keep
+// increasing after the backlog itself has expired.
+func sequenceKey(accountId user.AccountId) string {
+	return fmt.Sprintf("backlog:%s:seq", accountId)
+}
+
+func ackedKey(accountId user.AccountId) string {
+	return fmt.Sprintf("backlog:%s:acked", accountId)
+}
+
+// ackScript only moves the cursor forward, so a late ack can not make
+// calls show up again.
+var ackScript = redis.NewScript(1, `
+local acked = tonumber(redis.call("GET", KEYS[1]) or "0")
+if tonumber(ARGV[1]) > acked then
+  redis.call("SET", KEYS[1], ARGV[1])
+end
+return redis.status_reply("OK")
+`)
+
+func resumeKey(token tunnel.ResumeToken) string {
+	return fmt.Sprintf("resume:%s", token)
+}
+
+func (r *RedisResumeStore) Append(accountId user.AccountId, call tunnel.HookCall) (int64, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	seq, err := redis.Int64(conn.Do("INCR", sequenceKey(accountId)))
+	if err != nil {
+		return 0, err
+	}
+	call.Seq = seq
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(backlogEntry{Time: time.Now(), Hook: call}); err != nil {
+		return 0, err
+	}
+
+	key := backlogKey(accountId)
+	conn.Send("MULTI")
+	conn.Send("ZADD", key, seq, buf.Bytes())
+	conn.Send("ZREMRANGEBYRANK", key, 0, -(tunnel.ResumeBacklog + 1))
+	conn.Send("PEXPIRE", key, int64(tunnel.ResumeRetention/time.Millisecond))
+	if _, err := conn.Do("EXEC"); err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+func (r *RedisResumeStore) Since(accountId user.AccountId, seq int64) ([]tunnel.HookCall, bool, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	// read the counter first so calls appended meanwhile can not
+	// make the backlog look incomplete
+	current, err := redis.Int64(conn.Do("GET", sequenceKey(accountId)))
+	if err != nil && err != redis.ErrNil {
+		return nil, false, err
+	}
+
+	values, err := redis.ByteSlices(conn.Do("ZRANGEBYSCORE", backlogKey(accountId), fmt.Sprintf("(%d", seq), "+inf"))
+	if err != nil {
+		return nil, false, err
+	}
+
+	calls := []tunnel.HookCall{}
+	for _, v := range values {
+		entry := backlogEntry{}
+		if err := gob.NewDecoder(bytes.NewBuffer(v)).Decode(&entry); err != nil {
+			return nil, false, err
+		}
+		if time.Since(entry.Time) > tunnel.ResumeRetention {
+			continue
+		}
+		calls = append(calls, entry.Hook)
+	}
+
+	if len(calls) > 0 {
+		return calls, calls[0].Seq == seq+1, nil
+	}
+	return calls, current <= seq, nil
+}
+
+func (r *RedisResumeStore) NewToken(state tunnel.ResumeState) (tunnel.ResumeToken, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	token := tunnel.ResumeToken(uuid.NewV4().String())
+	key := resumeKey(token)
+	conn.Send("MULTI")
+	conn.Send("HMSET", key, "account", string(state.AccountId), "session", string(state.SessionId))
+	conn.Send("PEXPIRE", key, int64(tunnel.ResumeRetention/time.Millisecond))
+	if _, err := conn.Do("EXEC"); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *RedisResumeStore) FindToken(token tunnel.ResumeToken) (*tunnel.ResumeState, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	fields, err := redis.StringMap(conn.Do("HGETALL", resumeKey(token)))
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, tunnel.ErrResumeExpired
+	}
+	return &tunnel.ResumeState{
+		AccountId: user.AccountId(fields["account"]),
+		SessionId: tunnel.SessionId(fields["session"]),
+	}, nil
+}
+
+func (r *RedisResumeStore) Touch(token tunnel.ResumeToken) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("PEXPIRE", resumeKey(token), int64(tunnel.ResumeRetention/time.Millisecond))
+	return err
+}
+
+func (r *RedisResumeStore) Ack(accountId user.AccountId, seq int64) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	_, err := ackScript.Do(conn, ackedKey(accountId), seq)
+	return err
+}
+
+func (r *RedisResumeStore) Current(accountId user.AccountId) (int64, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	current, err := redis.Int64(conn.Do("GET", sequenceKey(accountId)))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+	return current, err
+}
+
+func (r *RedisResumeStore) Acked(accountId user.AccountId) (int64, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	acked, err := redis.Int64(conn.Do("GET", ackedKey(accountId)))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+	return acked, err
+}
+
+func (r *RedisResumeStore) Close() error {
+	return r.pool.Close()
+}
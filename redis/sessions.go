@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
+)
+
+// RedisSessionRegistry keeps the sessions of every account in a hash
+// with one field per instance and session.
+type RedisSessionRegistry struct {
+	pool *redis.Pool
+}
+
+func NewRedisSessionRegistry(address string) (tunnel.SessionRegistry, error) {
+	r := &RedisSessionRegistry{
+		pool: newPool(address),
+	}
+
+	// ensure redis connection is up
+	if err := pingRedis(r.pool); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func sessionsKey(accountId user.AccountId) string {
+	return fmt.Sprintf("sessions:%s", accountId)
+}
+
+// A resumed session keeps its id, so the field includes the instance
+// to keep the old process from removing the new registration.
+func sessionField(info *tunnel.SessionInfo) string {
+	return fmt.Sprintf("%s:%s", info.Instance, info.Id)
+}
+
+func (r *RedisSessionRegistry) Put(info *tunnel.SessionInfo) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(info); err != nil {
+		return err
+	}
+
+	key := sessionsKey(info.AccountId)
+	conn.Send("MULTI")
+	conn.Send("HSET", key, sessionField(info), buf.Bytes())
+	conn.Send("PEXPIRE", key, int64(tunnel.SessionExpiry/time.Millisecond))
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+func (r *RedisSessionRegistry) Remove(info *tunnel.SessionInfo) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HDEL", sessionsKey(info.AccountId), sessionField(info))
+	return err
+}
+
+func (r *RedisSessionRegistry) FindByAccountId(accountId user.AccountId) (tunnel.SessionInfoList, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	key := sessionsKey(accountId)
+	values, err := redis.ByteSlices(conn.Do("HVALS", key))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := tunnel.SessionInfoList{}
+	now := time.Now()
+	for _, v := range values {
+		info := &tunnel.SessionInfo{}
+		if err := gob.NewDecoder(bytes.NewBuffer(v)).Decode(info); err != nil {
+			return nil, err
+		}
+		// drop sessions of processes that went away
+		if now.After(info.Expires) {
+			conn.Do("HDEL", key, sessionField(info))
+			continue
+		}
+		sessions = append(sessions, info)
+	}
+	return sessions, nil
+}
+
+func (r *RedisSessionRegistry) Close() error {
+	return r.pool.Close()
+}
@@ -0,0 +1,384 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/gohook/gohook-server/tunnel"
+)
+
+// StreamKey is the stream every queue message is added to.
+const StreamKey = "stream:hooks"
+
+// DefaultStreamMaxLen caps the stream when no length is configured.
+const DefaultStreamMaxLen = 100000
+
+const (
+	// field of a stream entry holding the encoded message
+	streamField = "m"
+
+	streamCount = 100
+	streamBlock = 5 * time.Second
+
+	// Entries left pending by a consumer for longer than streamClaimIdle
+	// are claimed by another consumer of the group.
+	streamClaimIdle     = 30 * time.Second
+	streamClaimInterval = 15 * time.Second
+)
+
+// DefaultStreamGroupExpiry is how long every consumer of a group has to
+// be idle before other processes destroy the group.
+const DefaultStreamGroupExpiry = 24 * time.Hour
+
+type StreamOptions struct {
+	// Group is the consumer group of the process. Every group reads
+	// every message, and sessions are held by a single process, so
+	// each process needs a group of its own. The name has to be stable
+	// across restarts, such as the name of a stateful set member. A
+	// process started under the group of a crashed one claims the
+	// messages it left pending.
+	Group string
+	// Consumer names the process within its group, such as its host
+	// name.
+	Consumer string
+	// MaxLen caps the stream. Older entries are trimmed.
+	MaxLen int64
+	// GroupExpiry is how long the consumers of another group have to
+	// be idle before the group is destroyed, so groups of instances
+	// that are gone do not pile up.
+	GroupExpiry time.Duration
+}
+
+// RedisStreamQueue implements the HookQueue on a Redis stream. Unlike
+// pub/sub, messages added while a process is disconnected are read
+// once it is back.
+type RedisStreamQueue struct {
+	pool    *redis.Pool
+	opts    StreamOptions
+	health  *listenerHealth
+	delayed delayedCalls
+}
+
+type streamEntry struct {
+	id   string
+	data []byte
+}
+
+func NewRedisStreamQueue(address string, opts StreamOptions) (tunnel.HookQueue, error) {
+	if opts.MaxLen <= 0 {
+		opts.MaxLen = DefaultStreamMaxLen
+	}
+	if opts.GroupExpiry <= 0 {
+		opts.GroupExpiry = DefaultStreamGroupExpiry
+	}
+	pool := newPool(address)
+	q := &RedisStreamQueue{
+		pool:    pool,
+		opts:    opts,
+		health:  &listenerHealth{},
+		delayed: delayedCalls{pool},
+	}
+
+	// ensure redis connection is up
+	if err := pingRedis(q.pool); err != nil {
+		return nil, err
+	}
+
+	conn := q.pool.Get()
+	defer conn.Close()
+	if err := q.createGroup(conn); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// createGroup creates the group of the process unless it exists. New
+// groups only read the messages added from now on.
+func (q RedisStreamQueue) createGroup(conn redis.Conn) error {
+	_, err := conn.Do("XGROUP", "CREATE", StreamKey, q.opts.Group, "$", "MKSTREAM")
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// Health reports whether the listener is reading the stream.
+func (q RedisStreamQueue) Health() error {
+	return q.health.get()
+}
+
+func (q *RedisStreamQueue) Close() error {
+	return q.pool.Close()
+}
+
+func (q RedisStreamQueue) Broadcast(m *tunnel.QueueMessage) error {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	data, err := marshalMessage(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Do("XADD", StreamKey, "MAXLEN", "~", q.opts.MaxLen, "*", streamField, data)
+	return err
+}
+
+// Delay keeps the message in Redis until its call is due. It is
+// added to the stream once released.
+func (q RedisStreamQueue) Delay(m *tunnel.QueueMessage) error {
+	return q.delayed.Delay(m)
+}
+
+// Due hands out the delayed messages due by now.
+func (q RedisStreamQueue) Due(now time.Time, max int) ([]*tunnel.QueueMessage, error) {
+	return q.delayed.Due(now, max)
+}
+
+// Listen reads the messages of the group. Every message has to be
+// acked with its Ack func once it has been handled, or it is given to
+// another consumer of the group after a while. Like the pub/sub
+// listener, it reconnects with backoff when reading fails.
+func (q RedisStreamQueue) Listen() (tunnel.ReceiveC, error) {
+	c := make(tunnel.ReceiveC)
+
+	// reads block, so they get a connection of their own
+	conn := q.pool.Get()
+
+	go func(c tunnel.ReceiveC) {
+		defer func() { conn.Close() }()
+		defer close(c)
+
+		var lastClaim time.Time
+		for {
+			if time.Since(lastClaim) >= streamClaimInterval {
+				entries, err := q.claim(conn)
+				if err != nil {
+					fmt.Printf("[redis] Failed to claim pending messages. %v\n", err)
+				}
+				q.deliver(c, entries)
+				if err := q.expireGroups(); err != nil {
+					fmt.Printf("[redis] Failed to expire stream groups. %v\n", err)
+				}
+				lastClaim = time.Now()
+			}
+
+			entries, err := q.read(conn)
+			if err != nil {
+				q.health.set(ErrListenerReconnecting)
+				fmt.Printf("[redis] Error reading stream. Reconnecting. %v\n", err)
+				conn.Close()
+
+				// the stream, and the group with it, may be gone
+				err = retryListen(func() error {
+					conn = q.pool.Get()
+					if err := q.createGroup(conn); err != nil {
+						conn.Close()
+						return err
+					}
+					return nil
+				})
+				if err != nil {
+					q.health.set(err)
+					fmt.Printf("[redis] Could not reconnect. Closing channel. %v\n", err)
+					return
+				}
+				q.health.set(nil)
+				continue
+			}
+			q.deliver(c, entries)
+		}
+	}(c)
+
+	return c, nil
+}
+
+func (q RedisStreamQueue) read(conn redis.Conn) ([]streamEntry, error) {
+	reply, err := conn.Do("XREADGROUP", "GROUP", q.opts.Group, q.opts.Consumer,
+		"COUNT", streamCount, "BLOCK", int64(streamBlock/time.Millisecond),
+		"STREAMS", StreamKey, ">")
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	streams, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	entries := []streamEntry{}
+	for _, stream := range streams {
+		s, err := redis.Values(stream, nil)
+		if err != nil || len(s) != 2 {
+			return nil, fmt.Errorf("Unexpected Stream Reply: %v", err)
+		}
+		e, err := parseStreamEntries(s[1])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e...)
+	}
+	return entries, nil
+}
+
+// claim takes over the messages other consumers of the group left
+// pending, such as those of a crashed process.
+func (q RedisStreamQueue) claim(conn redis.Conn) ([]streamEntry, error) {
+	entries := []streamEntry{}
+	cursor := "0-0"
+	for {
+		reply, err := redis.Values(conn.Do("XAUTOCLAIM", StreamKey, q.opts.Group, q.opts.Consumer,
+			int64(streamClaimIdle/time.Millisecond), cursor, "COUNT", streamCount))
+		if err != nil {
+			return entries, err
+		}
+		if len(reply) < 2 {
+			return entries, fmt.Errorf("Unexpected Claim Reply")
+		}
+		if cursor, err = redis.String(reply[0], nil); err != nil {
+			return entries, err
+		}
+		e, err := parseStreamEntries(reply[1])
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, e...)
+		if cursor == "0-0" {
+			return entries, nil
+		}
+	}
+}
+
+// expireGroups destroys the groups of other processes whose consumers
+// have all been idle for GroupExpiry, and removes the consumers of its
+// own group that are gone and have nothing pending.
+func (q RedisStreamQueue) expireGroups() error {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	groups, err := xinfo(conn, "GROUPS", StreamKey)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		name, _ := redis.String(group["name"], nil)
+		consumers, err := xinfo(conn, "CONSUMERS", StreamKey, name)
+		if err != nil {
+			return err
+		}
+
+		active := false
+		for _, consumer := range consumers {
+			consumerName, _ := redis.String(consumer["name"], nil)
+			idle, _ := redis.Int64(consumer["idle"], nil)
+			pending, _ := redis.Int64(consumer["pending"], nil)
+			expired := time.Duration(idle)*time.Millisecond >= q.opts.GroupExpiry
+			if !expired {
+				active = true
+			}
+			if name == q.opts.Group && expired && pending == 0 && consumerName != q.opts.Consumer {
+				if _, err := conn.Do("XGROUP", "DELCONSUMER", StreamKey, name, consumerName); err != nil {
+					return err
+				}
+			}
+		}
+
+		if name != q.opts.Group && !active {
+			fmt.Printf("[redis] Destroying idle stream group %s.\n", name)
+			if _, err := conn.Do("XGROUP", "DESTROY", StreamKey, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// xinfo reads an XINFO reply listing field value pairs per item.
+func xinfo(conn redis.Conn, args ...interface{}) ([]map[string]interface{}, error) {
+	items, err := redis.Values(conn.Do("XINFO", args...))
+	if err != nil {
+		return nil, err
+	}
+	infos := []map[string]interface{}{}
+	for _, item := range items {
+		fields, err := redis.Values(item, nil)
+		if err != nil {
+			return nil, err
+		}
+		info := map[string]interface{}{}
+		for i := 0; i+1 < len(fields); i += 2 {
+			key, err := redis.String(fields[i], nil)
+			if err != nil {
+				return nil, err
+			}
+			info[key] = fields[i+1]
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (q RedisStreamQueue) deliver(c tunnel.ReceiveC, entries []streamEntry) {
+	for _, entry := range entries {
+		id := entry.id
+		msg, err := unmarshalMessage(entry.data)
+		if err != nil {
+			fmt.Printf("[redis] Failed to decode message %s. %v\n", id, err)
+			// nobody will be able to decode it
+			q.ack(id)
+			continue
+		}
+		msg.Ack = func() error {
+			return q.ack(id)
+		}
+		c <- msg
+	}
+}
+
+func (q RedisStreamQueue) ack(id string) error {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("XACK", StreamKey, q.opts.Group, id)
+	return err
+}
+
+// parseStreamEntries reads a list of [id, [field, value, ...]] entries.
+// Entries deleted from the stream meanwhile are skipped.
+func parseStreamEntries(reply interface{}) ([]streamEntry, error) {
+	items, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	entries := []streamEntry{}
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		fields, err := redis.Values(item, nil)
+		if err != nil || len(fields) != 2 {
+			return nil, fmt.Errorf("Unexpected Stream Entry: %v", err)
+		}
+		id, err := redis.String(fields[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		if fields[1] == nil {
+			continue
+		}
+		values, err := redis.ByteSlices(fields[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		entry := streamEntry{id: id}
+		for i := 0; i+1 < len(values); i += 2 {
+			if string(values[i]) == streamField {
+				entry.data = values[i+1]
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/gohook/gohook-server/user"
+)
+
+// RedisUsageCounter keeps usage counters in redis so every gohookd
+// process counts against the same limits.
+type RedisUsageCounter struct {
+	pool *redis.Pool
+}
+
+func NewRedisUsageCounter(address string) (user.UsageCounter, error) {
+	u := &RedisUsageCounter{
+		pool: newPool(address),
+	}
+
+	// ensure redis connection is up
+	if err := pingRedis(u.pool); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (u *RedisUsageCounter) Incr(accountId user.AccountId, key string, window time.Duration) (int, error) {
+	conn := u.pool.Get()
+	defer conn.Close()
+
+	seconds := int64(window / time.Second)
+	slot := time.Now().Unix() / seconds
+	k := fmt.Sprintf("usage:%s:%s:%d", accountId, key, slot)
+
+	count, err := redis.Int(conn.Do("INCR", k))
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		if _, err := conn.Do("EXPIRE", k, seconds); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// decrScript only decrements counters that exist, so a refund made
+// after the window rolled over does not start the next one below zero.
+var decrScript = redis.NewScript(1, `
+if redis.call("EXISTS", KEYS[1]) == 1 and tonumber(redis.call("GET", KEYS[1])) > 0 then
+  return redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+
+func (u *RedisUsageCounter) Decr(accountId user.AccountId, key string, window time.Duration) error {
+	conn := u.pool.Get()
+	defer conn.Close()
+
+	seconds := int64(window / time.Second)
+	slot := time.Now().Unix() / seconds
+	k := fmt.Sprintf("usage:%s:%s:%d", accountId, key, slot)
+
+	_, err := decrScript.Do(conn, k)
+	return err
+}
+
+func (u *RedisUsageCounter) Close() error {
+	return u.pool.Close()
+}
@@ -0,0 +1,101 @@
+package tunnel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"expvar"
+	"time"
+
+	"github.com/gohook/gohook-server/pb"
+)
+
+const (
+	// BatchWindow is how long the writer of a batching session waits
+	// for more calls before it sends a frame.
+	BatchWindow = 10 * time.Millisecond
+
+	// BatchMaxCalls and BatchMaxBytes bound the size of a frame. A
+	// frame is sent early once the buffer holds BatchMaxCalls calls.
+	BatchMaxCalls = 100
+	BatchMaxBytes = 1 << 20
+
+	// CompressMinSize is the smallest body that gets compressed.
+	CompressMinSize = 1024
+)
+
+var (
+	batchesSent      = expvar.NewInt("tunnel_batches_sent")
+	bodiesCompressed = expvar.NewInt("tunnel_bodies_compressed")
+)
+
+// encodeHookCall converts the call to its wire format. The body is
+// compressed when compress is set and it is worth it.
+func encodeHookCall(call HookCall, compress bool) *pb.HookCall {
+	message := &pb.HookCall{
+		Id:     call.Id,
+		Body:   call.Body,
+		Seq:    call.Seq,
+		Labels: call.Labels,
+	}
+	if compress && len(call.Body) >= CompressMinSize {
+		if body, err := gzipBody(call.Body); err == nil && len(body) < len(call.Body) {
+			message.Body = body
+			message.Encoding = pb.Encoding_ENCODING_GZIP
+			bodiesCompressed.Add(1)
+		}
+	}
+	return message
+}
+
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// frameHookCalls splits the calls into frames of at most
+// BatchMaxCalls calls and BatchMaxBytes of bodies. A call larger than
+// BatchMaxBytes gets a frame of its own.
+func frameHookCalls(calls []*pb.HookCall) [][]*pb.HookCall {
+	frames := [][]*pb.HookCall{}
+	var frame []*pb.HookCall
+	size := 0
+	for _, call := range calls {
+		if len(frame) > 0 && (len(frame) >= BatchMaxCalls || size+len(call.Body) > BatchMaxBytes) {
+			frames = append(frames, frame)
+			frame, size = nil, 0
+		}
+		frame = append(frame, call)
+		size += len(call.Body)
+	}
+	if len(frame) > 0 {
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// hookFrame wraps the calls in a tunnel response. A single call is
+// sent as a plain hook event.
+func hookFrame(calls []*pb.HookCall) *pb.TunnelResponse {
+	if len(calls) == 1 {
+		return &pb.TunnelResponse{
+			Event: &pb.TunnelResponse_Hook{
+				Hook: calls[0],
+			},
+		}
+	}
+	batchesSent.Add(1)
+	return &pb.TunnelResponse{
+		Event: &pb.TunnelResponse_Batch{
+			Batch: &pb.HookBatch{
+				Calls: calls,
+			},
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package tunnel
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/gohook/gohook-server/pb"
+	"github.com/golang/protobuf/proto"
+)
+
+// benchCalls returns n calls with JSON bodies of about size bytes, as
+// webhook senders post them.
+func benchCalls(n, size int) []HookCall {
+	calls := make([]HookCall, n)
+	for i := range calls {
+		var body bytes.Buffer
+		body.WriteString(`{"events":[`)
+		for j := 0; body.Len() < size; j++ {
+			if j > 0 {
+				body.WriteByte(',')
+			}
+			fmt.Fprintf(&body, `{"id":%d,"type":"push","repository":"gohook/gohook-server","ref":"refs/heads/master"}`, j)
+		}
+		body.WriteString(`]}`)
+		calls[i] = HookCall{
+			Id:     fmt.Sprintf("hook-%d", i),
+			Method: "POST",
+			Body:   body.Bytes(),
+			Seq:    int64(i + 1),
+		}
+	}
+	return calls
+}
+
+// sendFrames encodes the calls and marshals the frames a session
+// would send, with or without batching and compression. It returns the
+// number of frames and their size on the wire.
+func sendFrames(b *testing.B, calls []HookCall, batch, compress bool) (frames, wire int) {
+	encoded := make([]*pb.HookCall, len(calls))
+	for j, call := range calls {
+		encoded[j] = encodeHookCall(call, compress)
+	}
+
+	var split [][]*pb.HookCall
+	if batch {
+		split = frameHookCalls(encoded)
+	} else {
+		for _, call := range encoded {
+			split = append(split, []*pb.HookCall{call})
+		}
+	}
+
+	for _, frame := range split {
+		data, err := proto.Marshal(hookFrame(frame))
+		if err != nil {
+			b.Fatal(err)
+		}
+		frames++
+		wire += len(data)
+	}
+	return frames, wire
+}
+
+// benchmarkSend reports the wire bytes of an op as its MB/s, and logs
+// the number of frames sent.
+func benchmarkSend(b *testing.B, batch, compress bool) {
+	calls := benchCalls(BatchMaxCalls, 4*CompressMinSize)
+	frames, wire := sendFrames(b, calls, batch, compress)
+	b.SetBytes(int64(wire))
+	b.Logf("%d calls in %d frames, %d bytes on the wire", len(calls), frames, wire)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sendFrames(b, calls, batch, compress)
+	}
+}
+
+func BenchmarkSendUnbatchedPlain(b *testing.B) {
+	benchmarkSend(b, false, false)
+}
+
+func BenchmarkSendUnbatchedCompressed(b *testing.B) {
+	benchmarkSend(b, false, true)
+}
+
+func BenchmarkSendBatchedPlain(b *testing.B) {
+	benchmarkSend(b, true, false)
+}
+
+func BenchmarkSendBatchedCompressed(b *testing.B) {
+	benchmarkSend(b, true, true)
+}
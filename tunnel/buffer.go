@@ -0,0 +1,220 @@
+package tunnel
+
+import (
+	"errors"
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSlowConsumer  = errors.New("Slow Consumer")
+	ErrInvalidPolicy = errors.New("Invalid Buffer Policy")
+	ErrSpillOverflow = errors.New("Spill Overflow")
+)
+
+// BufferPolicy decides what happens when the send buffer of a session
+// is full.
+type BufferPolicy string
+
+const (
+	// BufferDropOldest drops the oldest buffered call.
+	BufferDropOldest BufferPolicy = "drop-oldest"
+	// BufferDisconnect closes the session. The client can resume it
+	// once it has caught up.
+	BufferDisconnect BufferPolicy = "disconnect"
+	// BufferSpill leaves new calls in the resume backlog and reads
+	// them back once the session has drained its buffer. The backlog
+	// only keeps ResumeBacklog calls for ResumeRetention, calls that
+	// stay spilled for half of either are dead lettered instead.
+	BufferSpill BufferPolicy = "spill"
+)
+
+// Spilled calls are dead lettered before the resume backlog drops them.
+const (
+	spillMaxCalls = ResumeBacklog / 2
+	spillMaxAge   = ResumeRetention / 2
+)
+
+func ParseBufferPolicy(policy string) (BufferPolicy, error) {
+	switch p := BufferPolicy(policy); p {
+	case BufferDropOldest, BufferDisconnect, BufferSpill:
+		return p, nil
+	}
+	return "", ErrInvalidPolicy
+}
+
+type BufferOptions struct {
+	// Size is the number of hook calls buffered per session.
+	Size   int
+	Policy BufferPolicy
+}
+
+var DefaultBufferOptions = BufferOptions{
+	Size:   256,
+	Policy: BufferDropOldest,
+}
+
+var (
+	bufferDropped     = expvar.NewInt("tunnel_buffer_dropped")
+	bufferSpilled     = expvar.NewInt("tunnel_buffer_spilled")
+	bufferDisconnects = expvar.NewInt("tunnel_buffer_disconnects")
+)
+
+// sendBuffer is the bounded outbound queue of a session. It is
+// drained by the writer goroutine of the session. Calls are kept in
+// order of priority, and in the order they came within a priority.
+type sendBuffer struct {
+	mtx   sync.Mutex
+	calls []HookCall
+	opts  BufferOptions
+	// expired calls waiting to be reported by the writer
+	expired []HookCall
+	// set when calls were left to the resume backlog, from the
+	// sequence number and time of the first one
+	spilled   bool
+	spillFrom int64
+	spillAt   time.Time
+	// set while the writer sends the calls it took
+	busy bool
+	// signals the writer that calls are waiting
+	notify chan struct{}
+}
+
+func newSendBuffer(opts BufferOptions) *sendBuffer {
+	if opts.Size <= 0 {
+		opts.Size = DefaultBufferOptions.Size
+	}
+	return &sendBuffer{
+		opts:   opts,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// push adds the call to the buffer ahead of the calls of a lower
+// priority. ErrSlowConsumer is returned when the session has to be
+// disconnected, and ErrSpillOverflow once the spilled calls have to be
+// dead lettered. The call dropped to make room, if any, is returned.
+func (b *sendBuffer) push(call HookCall) (*HookCall, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	var dropped *HookCall
+	if len(b.calls) >= b.opts.Size {
+		b.dropExpired(time.Now())
+	}
+	if len(b.calls) >= b.opts.Size {
+		switch {
+		case b.opts.Policy == BufferDisconnect:
+			bufferDisconnects.Add(1)
+			return nil, ErrSlowConsumer
+		case b.opts.Policy == BufferSpill && call.Seq != 0:
+			if b.spillFrom == 0 {
+				b.spillFrom, b.spillAt = call.Seq, time.Now()
+			}
+			b.spilled = true
+			bufferSpilled.Add(1)
+			b.signal()
+			if call.Seq-b.spillFrom >= spillMaxCalls || time.Since(b.spillAt) >= spillMaxAge {
+				return nil, ErrSpillOverflow
+			}
+			return nil, nil
+		default:
+			// calls without a sequence number are not in the
+			// backlog, so they can not be spilled either. The oldest
+			// call of the lowest priority is dropped, which is the
+			// new call when every buffered call is more important.
+			bufferDropped.Add(1)
+			last := b.calls[len(b.calls)-1].Priority
+			if call.Priority < last {
+				return &call, nil
+			}
+			i := sort.Search(len(b.calls), func(i int) bool {
+				return b.calls[i].Priority <= last
+			})
+			oldest := b.calls[i]
+			dropped = &oldest
+			b.calls = append(b.calls[:i], b.calls[i+1:]...)
+		}
+	}
+
+	i := sort.Search(len(b.calls), func(i int) bool {
+		return b.calls[i].Priority < call.Priority
+	})
+	b.calls = append(b.calls, HookCall{})
+	copy(b.calls[i+1:], b.calls[i:])
+	b.calls[i] = call
+	b.signal()
+	return dropped, nil
+}
+
+// dropExpired moves the expired calls out of the way.
+func (b *sendBuffer) dropExpired(now time.Time) {
+	calls := b.calls[:0]
+	for _, call := range b.calls {
+		if call.Expired(now) {
+			b.expired = append(b.expired, call)
+			continue
+		}
+		calls = append(calls, call)
+	}
+	b.calls = calls
+}
+
+// take empties the buffer. Calls that expired meanwhile are returned
+// apart from the calls to send. spilled reports whether calls have to
+// be read back from the resume backlog first.
+func (b *sendBuffer) take() (calls, expired []HookCall, spilled bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.dropExpired(time.Now())
+	calls, expired, spilled = b.calls, b.expired, b.spilled
+	b.calls, b.expired, b.spilled = nil, nil, false
+	b.spillFrom = 0
+	b.busy = len(calls) > 0 || spilled
+	return calls, expired, spilled
+}
+
+// spillStart is the sequence number of the first spilled call, zero
+// when no call is spilled.
+func (b *sendBuffer) spillStart() int64 {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.spillFrom
+}
+
+// restartSpill starts counting the spilled calls again, once the ones
+// before have been dead lettered.
+func (b *sendBuffer) restartSpill() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.spillFrom = 0
+}
+
+// done is called by the writer once it has sent the calls it took.
+func (b *sendBuffer) done() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.busy = false
+}
+
+// flushed reports whether every call pushed has been sent.
+func (b *sendBuffer) flushed() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return len(b.calls) == 0 && len(b.expired) == 0 && !b.spilled && !b.busy
+}
+
+func (b *sendBuffer) len() int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return len(b.calls)
+}
+
+func (b *sendBuffer) signal() {
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
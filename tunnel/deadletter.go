@@ -0,0 +1,146 @@
+package tunnel
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/gohook/gohook-server/user"
+)
+
+var (
+	ErrDeadLetterNotFound = errors.New("Dead Letter Not Found")
+	ErrNoSessions         = errors.New("No Sessions")
+)
+
+const (
+	// MaxDeliveryAttempts is the number of times a call is handed to
+	// the sessions of its account before it is given up.
+	MaxDeliveryAttempts = 5
+	// DeliveryTimeout bounds the time a call waits between attempts.
+	DeliveryTimeout = time.Minute
+	// DeadLetterRetention is how long dead letters are kept.
+	DeadLetterRetention = 7 * 24 * time.Hour
+)
+
+// Reasons a call is given up for.
+const (
+	ReasonBufferFull        = "Send Buffer Full"
+	ReasonSpillOverflow     = "Spilled Too Long"
+	ReasonAttemptsExhausted = "Delivery Attempts Exhausted"
+	ReasonDeliveryTimedOut  = "Delivery Timed Out"
+)
+
+var deadLetters = expvar.NewInt("tunnel_dead_letters")
+
+type DeadLetterId string
+
+// DeadLetter is a hook call that could not be delivered, kept so the
+// client can inspect it and have it delivered again.
+type DeadLetter struct {
+	Id        DeadLetterId   `json:"id"`
+	AccountId user.AccountId `json:"account_id"`
+	Call      HookCall       `json:"call"`
+	Reason    string         `json:"reason"`
+	Attempts  int            `json:"attempts"`
+	Time      time.Time      `json:"time"`
+}
+
+type DeadLetterList []*DeadLetter
+
+// DeadLetterStore keeps the dead letters of every account for
+// DeadLetterRetention. Lookups are scoped to an account, other
+// accounts' letters are not found.
+type DeadLetterStore interface {
+	Add(letter *DeadLetter) error
+	// FindByAccount returns the letters of the account, newest first.
+	FindByAccount(accountId user.AccountId) (DeadLetterList, error)
+	Find(accountId user.AccountId, id DeadLetterId) (*DeadLetter, error)
+	Remove(accountId user.AccountId, id DeadLetterId) (*DeadLetter, error)
+	// Purge removes every letter of the account and returns how many
+	// there were.
+	Purge(accountId user.AccountId) (int, error)
+}
+
+// deliver hands the call to the sessions of the account held by this
+// process. Sessions that did not take the call are tried again with
+// backoff, and the call is given up once MaxDeliveryAttempts or
+// DeliveryTimeout is reached. Accounts without sessions here are left
+// alone, their clients pick the call up from the backlog.
+func (s GohookTunnelServer) deliver(accountId user.AccountId, call HookCall, attempt int, first time.Time) {
+	s.deliverTo(accountId, call, nil, attempt, first)
+}
+
+// deliverTo is deliver limited to the sessions in retry, or every
+// session of the account when retry is nil. Sessions that went away
+// in between are not tried again.
+func (s GohookTunnelServer) deliverTo(accountId user.AccountId, call HookCall, retry map[SessionId]bool, attempt int, first time.Time) {
+	sessions, err := s.sessions.FindByAccountId(accountId)
+	if err != nil {
+		return
+	}
+	if retry != nil {
+		remaining := SessionList{}
+		for _, session := range sessions {
+			if retry[session.Id] {
+				remaining = append(remaining, session)
+			}
+		}
+		sessions = remaining
+	}
+	if len(sessions) == 0 {
+		return
+	}
+
+	failed, err := s.sendToSessions(accountId, sessions, call)
+	if len(failed) == 0 {
+		return
+	}
+
+	delay := time.Duration(attempt) * time.Second
+	switch {
+	case attempt >= MaxDeliveryAttempts:
+		s.deadLetter(accountId, call, attempt, fmt.Sprintf("%s: %v", ReasonAttemptsExhausted, err))
+	case time.Since(first)+delay > DeliveryTimeout:
+		s.deadLetter(accountId, call, attempt, ReasonDeliveryTimedOut)
+	default:
+		time.AfterFunc(delay, func() {
+			s.deliverTo(accountId, call, failed, attempt+1, first)
+		})
+	}
+}
+
+// deadLetterSpilled dead letters the calls the session spilled before
+// the resume backlog drops them.
+func (s GohookTunnelServer) deadLetterSpilled(session *Session) {
+	err := session.DropSpilled(func(lastSeq int64) ([]HookCall, error) {
+		calls, _, err := s.resumes.Since(session.AccountId, lastSeq)
+		return calls, err
+	}, func(call HookCall) {
+		if call.Expired(time.Now()) {
+			s.expire(session.AccountId, call, ReasonExpiredInBuffer)
+			return
+		}
+		s.deadLetter(session.AccountId, call, 1, ReasonSpillOverflow)
+	})
+	if err != nil {
+		s.logger.Log("msg", "Dead lettering spilled calls failed", "sessionId", session.Id, "err", err)
+	}
+}
+
+// deadLetter keeps the call for the client to requeue.
+func (s GohookTunnelServer) deadLetter(accountId user.AccountId, call HookCall, attempts int, reason string) {
+	deadLetters.Add(1)
+	s.logger.Log("msg", "Dead lettering call", "account_id", accountId, "hook", call.Id, "seq", call.Seq, "reason", reason)
+	err := s.deadLetters.Add(&DeadLetter{
+		AccountId: accountId,
+		Call:      call,
+		Reason:    reason,
+		Attempts:  attempts,
+		Time:      time.Now(),
+	})
+	if err != nil {
+		s.logger.Log("msg", "Dead letter failed", "account_id", accountId, "hook", call.Id, "err", err)
+	}
+}
@@ -0,0 +1,39 @@
+package tunnel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/user"
+)
+
+func TestDeliverRetriesFailedSessions(t *testing.T) {
+	account := user.AccountId("account")
+	s := GohookTunnelServer{sessions: NewSessionStore(), logger: log.NewNopLogger()}
+	full := NewSession("full", account, nil, BufferOptions{Size: 1, Policy: BufferDisconnect})
+	ok := NewSession("ok", account, nil, BufferOptions{Size: 4, Policy: BufferDisconnect})
+	for _, session := range []*Session{full, ok} {
+		if err := s.sessions.Add(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := full.Enqueue(HookCall{Id: "waiting", Seq: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	call := HookCall{Id: "hook", Seq: 2}
+	failed, err := s.sendToSessions(account, SessionList{full, ok}, call)
+	if err != ErrSlowConsumer || len(failed) != 1 || !failed["full"] {
+		t.Fatalf("expected only the full session to fail, got %v, %v", failed, err)
+	}
+	if ok.BufferDepth() != 1 {
+		t.Fatalf("expected the call to be buffered once, got %d", ok.BufferDepth())
+	}
+
+	// a retry only reaches the sessions that failed
+	s.deliverTo(account, call, map[SessionId]bool{"gone": true}, 2, time.Now())
+	if ok.BufferDepth() != 1 {
+		t.Fatalf("expected the retry to leave the other sessions alone, got %d", ok.BufferDepth())
+	}
+}
@@ -0,0 +1,128 @@
+package tunnel
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/pb"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var ErrServerDraining = errors.New("Server Draining")
+
+// MaxReconnectDelay spreads the reconnects of drained clients so they
+// do not all hit the remaining instances at once.
+const MaxReconnectDelay = 5 * time.Second
+
+// Drain stops accepting tunnels and closes every session held by this
+// process once its buffered calls are sent. It returns when all
+// sessions and hijacked connections are gone, or the context is done.
+// Connections still open at the deadline are closed.
+func (s *GohookTunnelServer) Drain(ctx context.Context) error {
+	select {
+	case <-s.draining:
+	default:
+		close(s.draining)
+	}
+
+	poll := time.NewTicker(50 * time.Millisecond)
+	defer poll.Stop()
+	for len(s.sessions.All()) > 0 || s.hijacked.len() > 0 {
+		select {
+		case <-poll.C:
+		case <-ctx.Done():
+			if n := s.hijacked.closeAll(); n > 0 {
+				s.logger.Log("msg", "Closed connections at drain deadline", "count", n)
+			}
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *GohookTunnelServer) isDraining() bool {
+	select {
+	case <-s.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// drainSession sends the buffered calls of the session, then tells the
+// client to reconnect elsewhere.
+func (s *GohookTunnelServer) drainSession(session *Session) error {
+	if err := session.Flush(session.Stream.Context().Done()); err != nil {
+		s.logger.Log("msg", "Flush failed", "sessionId", session.Id, "err", err)
+	}
+
+	if !session.Capabilities.Has(CapGoingAway) {
+		return grpc.Errorf(codes.Unavailable, "%v", ErrServerDraining)
+	}
+
+	delay := time.Duration(rand.Int63n(int64(MaxReconnectDelay)))
+	err := session.Send(&pb.TunnelResponse{
+		Event: &pb.TunnelResponse_GoingAway{
+			GoingAway: &pb.GoingAway{
+				ReconnectDelayMs: int64(delay / time.Millisecond),
+				Reason:           ErrServerDraining.Error(),
+			},
+		},
+	})
+	if err != nil {
+		s.logger.Log("msg", "Going away failed", "sessionId", session.Id, "err", err)
+	}
+	return grpc.Errorf(codes.Unavailable, "%v", ErrServerDraining)
+}
+
+/*
+Hijacked Connections
+--------------------
+
+The HTTP server does not wait for the connections taken over by a
+WebSocket upgrade when it shuts down. Tunnels over them register
+their connection here, so draining waits for the close frame to be
+written, and closes what is left at the deadline.
+*/
+
+type hijackedConns struct {
+	mtx   sync.Mutex
+	conns map[io.Closer]bool
+}
+
+func newHijackedConns() *hijackedConns {
+	return &hijackedConns{conns: map[io.Closer]bool{}}
+}
+
+func (h *hijackedConns) add(c io.Closer) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.conns[c] = true
+}
+
+func (h *hijackedConns) remove(c io.Closer) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	delete(h.conns, c)
+}
+
+func (h *hijackedConns) len() int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return len(h.conns)
+}
+
+// closeAll closes the connections still open and returns how many.
+func (h *hijackedConns) closeAll() int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	for c := range h.conns {
+		c.Close()
+	}
+	return len(h.conns)
+}
@@ -0,0 +1,72 @@
+package tunnel
+
+import (
+	"github.com/go-kit/kit/endpoint"
+	"golang.org/x/net/context"
+)
+
+type Endpoints struct {
+	ListSessionsEndpoint endpoint.Endpoint
+	KickSessionEndpoint  endpoint.Endpoint
+	PresenceEndpoint     endpoint.Endpoint
+}
+
+// ListSessions Endpoint
+type listSessionsRequest struct{}
+
+func (e Endpoints) ListSessions(ctx context.Context) (SessionInfoList, error) {
+	response, err := e.ListSessionsEndpoint(ctx, listSessionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return response.(SessionInfoList), nil
+}
+
+func MakeListSessionsEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (response interface{}, err error) {
+		sessions, err := s.ListSessions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return sessions, nil
+	}
+}
+
+// KickSession Endpoint
+type kickSessionResponse struct{}
+
+func (e Endpoints) KickSession(ctx context.Context, id SessionId) error {
+	_, err := e.KickSessionEndpoint(ctx, id)
+	return err
+}
+
+func MakeKickSessionEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		id := request.(SessionId)
+		if err := s.KickSession(ctx, id); err != nil {
+			return nil, err
+		}
+		return kickSessionResponse{}, nil
+	}
+}
+
+// Presence Endpoint
+type presenceRequest struct{}
+
+func (e Endpoints) Presence(ctx context.Context) (*PresenceInfo, error) {
+	response, err := e.PresenceEndpoint(ctx, presenceRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return response.(*PresenceInfo), nil
+}
+
+func MakePresenceEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (response interface{}, err error) {
+		presence, err := s.Presence(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return presence, nil
+	}
+}
@@ -0,0 +1,159 @@
+package tunnel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gohook/gohook-server/pb"
+	"github.com/gohook/gohook-server/user"
+	"github.com/golang/protobuf/proto"
+)
+
+// EnvelopeVersion is the version of the queue envelopes written by this
+// process. It is only incremented on changes older processes can not
+// read; new fields are ignored by them.
+const EnvelopeVersion = 1
+
+// envelopeTag is the first byte of every encoded envelope, the key of
+// the version field. Gob streams start with the length of a type
+// definition, which is never as short.
+const envelopeTag = 0x08
+
+var (
+	ErrNotEnvelope         = errors.New("Not A Queue Envelope")
+	ErrUnsupportedEnvelope = errors.New("Unsupported Queue Envelope Version")
+)
+
+var queueMessageTypes = map[MessageType]pb.QueueMessageType{
+	MessageHook:       pb.QueueMessageType_QUEUE_HOOK,
+	MessageDisconnect: pb.QueueMessageType_QUEUE_DISCONNECT,
+	MessageKick:       pb.QueueMessageType_QUEUE_KICK,
+	MessageEvent:      pb.QueueMessageType_QUEUE_EVENT,
+}
+
+// IsEnvelope reports whether data looks like an encoded envelope rather
+// than a legacy payload.
+func IsEnvelope(data []byte) bool {
+	return len(data) > 0 && data[0] == envelopeTag
+}
+
+// EncodeQueueMessage encodes the message as a versioned protobuf
+// envelope, readable by processes in any language. The Ack func is not
+// encoded.
+func EncodeQueueMessage(m *QueueMessage) ([]byte, error) {
+	env := &pb.QueueEnvelope{
+		Version:   EnvelopeVersion,
+		Type:      queueMessageTypes[m.Type],
+		AccountId: string(m.AccountId),
+		SessionId: string(m.SessionId),
+		Reason:    m.Reason,
+	}
+	if m.Type == MessageHook {
+		env.Hook = &pb.QueueHookCall{
+			Seq:       m.Hook.Seq,
+			Id:        m.Hook.Id,
+			Method:    m.Hook.Method,
+			Body:      m.Hook.Body,
+			Labels:    m.Hook.Labels,
+			Expires:   encodeTime(m.Hook.Expires),
+			NotBefore: encodeTime(m.Hook.NotBefore),
+			Priority:  int32(m.Hook.Priority),
+		}
+	}
+	if e := m.Event; e != nil {
+		env.Event = &pb.QueueEvent{
+			Type:      pb.QueueEventType(e.Type),
+			SessionId: string(e.SessionId),
+			Limit:     e.Limit,
+			Used:      int64(e.Used),
+			Max:       int64(e.Max),
+			Message:   e.Message,
+		}
+		if e.Hook.Id != "" {
+			env.Event.Hook = &pb.Hook{
+				Id:     e.Hook.Id,
+				Url:    e.Hook.Url,
+				Method: pb.Method(pb.Method_value[e.Hook.Method]),
+				Labels: e.Hook.Labels,
+			}
+		}
+	}
+	return proto.Marshal(env)
+}
+
+// DecodeQueueMessage decodes an envelope written by
+// EncodeQueueMessage. ErrNotEnvelope is returned for other payloads,
+// and ErrUnsupportedEnvelope for envelopes of a newer version.
+func DecodeQueueMessage(data []byte) (*QueueMessage, error) {
+	if !IsEnvelope(data) {
+		return nil, ErrNotEnvelope
+	}
+	env := &pb.QueueEnvelope{}
+	if err := proto.Unmarshal(data, env); err != nil {
+		return nil, err
+	}
+	if env.Version > EnvelopeVersion {
+		return nil, ErrUnsupportedEnvelope
+	}
+
+	m := &QueueMessage{
+		AccountId: user.AccountId(env.AccountId),
+		SessionId: SessionId(env.SessionId),
+		Reason:    env.Reason,
+	}
+	known := false
+	for t, et := range queueMessageTypes {
+		if et == env.Type {
+			m.Type, known = t, true
+		}
+	}
+	if !known {
+		return nil, errors.New("Unknown Queue Message Type")
+	}
+	if h := env.GetHook(); h != nil {
+		m.Hook = HookCall{
+			Seq:       h.Seq,
+			Id:        h.Id,
+			Method:    h.Method,
+			Body:      h.Body,
+			Labels:    h.GetLabels(),
+			Expires:   decodeTime(h.Expires),
+			NotBefore: decodeTime(h.NotBefore),
+			Priority:  int(h.Priority),
+		}
+	}
+	if e := env.GetEvent(); e != nil {
+		m.Event = &Event{
+			Type:      EventType(e.Type),
+			SessionId: SessionId(e.SessionId),
+			Limit:     e.Limit,
+			Used:      int(e.Used),
+			Max:       int(e.Max),
+			Message:   e.Message,
+		}
+		if h := e.GetHook(); h != nil {
+			m.Event.Hook = HookInfo{
+				Id:     h.Id,
+				Url:    h.Url,
+				Method: h.Method.String(),
+				Labels: h.GetLabels(),
+			}
+		}
+	}
+	return m, nil
+}
+
+// Times are unix nanoseconds, zero times are left unset.
+func encodeTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func decodeTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
@@ -0,0 +1,148 @@
+package tunnel
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gohook/gohook-server/pb"
+	"github.com/gohook/gohook-server/user"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	at := time.Unix(0, time.Date(2017, 4, 1, 12, 0, 0, 0, time.UTC).UnixNano())
+	messages := []*QueueMessage{
+		{
+			Type:      MessageHook,
+			AccountId: user.AccountId("account"),
+			Hook: HookCall{
+				Seq:       42,
+				Id:        "hook",
+				Method:    "POST",
+				Body:      []byte(`{"ref":"refs/heads/master"}`),
+				Labels:    map[string]string{"env": "prod"},
+				Expires:   at.Add(time.Hour),
+				NotBefore: at,
+				Priority:  2,
+			},
+		},
+		{
+			Type:      MessageDisconnect,
+			AccountId: user.AccountId("account"),
+			Reason:    "Token Revoked",
+			Event: &Event{
+				Type:    EventTokenRevoked,
+				Message: "Token Revoked",
+			},
+		},
+		{
+			Type:      MessageKick,
+			AccountId: user.AccountId("account"),
+			SessionId: SessionId("session"),
+			Reason:    "Kicked",
+			Event: &Event{
+				Type:      EventSessionKicked,
+				SessionId: SessionId("session"),
+				Message:   "Kicked",
+			},
+		},
+		{
+			Type:      MessageEvent,
+			AccountId: user.AccountId("account"),
+			Event: &Event{
+				Type: EventHookCreated,
+				Hook: HookInfo{
+					Id:     "hook",
+					Url:    "https://example.com/hook",
+					Method: "POST",
+					Labels: map[string]string{"env": "prod"},
+				},
+			},
+		},
+		{
+			Type: MessageEvent,
+			Event: &Event{
+				Type:  EventQuotaWarning,
+				Limit: "hooks",
+				Used:  9,
+				Max:   10,
+			},
+		},
+	}
+
+	for _, m := range messages {
+		data, err := EncodeQueueMessage(m)
+		if err != nil {
+			t.Fatalf("encoding message of type %d: %v", m.Type, err)
+		}
+		if !IsEnvelope(data) {
+			t.Fatalf("expected message of type %d to be an envelope", m.Type)
+		}
+		decoded, err := DecodeQueueMessage(data)
+		if err != nil {
+			t.Fatalf("decoding message of type %d: %v", m.Type, err)
+		}
+		if !reflect.DeepEqual(decoded, m) {
+			t.Fatalf("expected %+v, got %+v", m, decoded)
+		}
+	}
+}
+
+// legacyQueueMessage is the queue message gob encoded by releases
+// before the envelope.
+type legacyQueueMessage struct {
+	AccountId user.AccountId
+	Hook      struct {
+		Id     string
+		Method string
+		Body   []byte
+	}
+}
+
+func TestEnvelopeLegacyGob(t *testing.T) {
+	legacy := legacyQueueMessage{AccountId: user.AccountId("account")}
+	legacy.Hook.Id = "hook"
+	legacy.Hook.Method = "POST"
+	legacy.Hook.Body = []byte("body")
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(legacy); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if IsEnvelope(data) {
+		t.Fatal("expected a gob payload not to look like an envelope")
+	}
+	if _, err := DecodeQueueMessage(data); err != ErrNotEnvelope {
+		t.Fatalf("expected %v, got %v", ErrNotEnvelope, err)
+	}
+
+	m := &QueueMessage{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != MessageHook || m.AccountId != legacy.AccountId {
+		t.Fatalf("expected a hook message of the account, got %+v", m)
+	}
+	if m.Hook.Id != "hook" || m.Hook.Method != "POST" || string(m.Hook.Body) != "body" {
+		t.Fatalf("expected the legacy hook call, got %+v", m.Hook)
+	}
+}
+
+func TestEnvelopeFutureVersion(t *testing.T) {
+	data, err := proto.Marshal(&pb.QueueEnvelope{
+		Version:   EnvelopeVersion + 1,
+		Type:      pb.QueueMessageType_QUEUE_HOOK,
+		AccountId: "account",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeQueueMessage(data); err != ErrUnsupportedEnvelope {
+		t.Fatalf("expected %v, got %v", ErrUnsupportedEnvelope, err)
+	}
+}
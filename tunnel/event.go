@@ -0,0 +1,123 @@
+package tunnel
+
+import (
+	"github.com/gohook/gohook-server/pb"
+)
+
+type EventType int
+
+const (
+	EventHookCreated EventType = iota + 1
+	EventHookUpdated
+	EventHookDeleted
+	EventHookExpired
+	EventSessionKicked
+	EventQuotaWarning
+	EventTokenRevoked
+	EventAnnouncement
+)
+
+// HookInfo describes the webhook of a hook event. The access policy is
+// left out since it holds secrets.
+type HookInfo struct {
+	Id     string
+	Url    string
+	Method string
+	Labels map[string]string
+}
+
+// Event is sent to the sessions that negotiated the events capability.
+// Only the fields of its type are set.
+type Event struct {
+	Type EventType
+
+	Hook HookInfo
+
+	SessionId SessionId
+
+	// Limit is the name of the limit a quota warning is about.
+	Limit string
+	Used  int
+	Max   int
+
+	// Message is the reason of a kick or revocation, or the text of
+	// an announcement.
+	Message string
+}
+
+// response converts the event to its wire format. It returns nil for
+// unknown event types.
+func (e *Event) response() *pb.TunnelResponse {
+	switch e.Type {
+	case EventHookCreated, EventHookUpdated, EventHookDeleted, EventHookExpired:
+		return &pb.TunnelResponse{
+			Event: &pb.TunnelResponse_HookEvent{
+				HookEvent: &pb.HookEvent{
+					Type: hookEventTypes[e.Type],
+					Hook: &pb.Hook{
+						Id:     e.Hook.Id,
+						Url:    e.Hook.Url,
+						Method: pb.Method(pb.Method_value[e.Hook.Method]),
+						Labels: e.Hook.Labels,
+					},
+				},
+			},
+		}
+	case EventSessionKicked:
+		return &pb.TunnelResponse{
+			Event: &pb.TunnelResponse_SessionKicked{
+				SessionKicked: &pb.SessionKicked{
+					SessionId: string(e.SessionId),
+					Reason:    e.Message,
+				},
+			},
+		}
+	case EventQuotaWarning:
+		return &pb.TunnelResponse{
+			Event: &pb.TunnelResponse_QuotaWarning{
+				QuotaWarning: &pb.QuotaWarning{
+					Limit: e.Limit,
+					Used:  int64(e.Used),
+					Max:   int64(e.Max),
+				},
+			},
+		}
+	case EventTokenRevoked:
+		return &pb.TunnelResponse{
+			Event: &pb.TunnelResponse_TokenRevoked{
+				TokenRevoked: &pb.TokenRevoked{
+					Reason: e.Message,
+				},
+			},
+		}
+	case EventAnnouncement:
+		return &pb.TunnelResponse{
+			Event: &pb.TunnelResponse_Announcement{
+				Announcement: &pb.Announcement{
+					Message: e.Message,
+				},
+			},
+		}
+	}
+	return nil
+}
+
+var hookEventTypes = map[EventType]pb.HookEventType{
+	EventHookCreated: pb.HookEventType_HOOK_CREATED,
+	EventHookUpdated: pb.HookEventType_HOOK_UPDATED,
+	EventHookDeleted: pb.HookEventType_HOOK_DELETED,
+	EventHookExpired: pb.HookEventType_HOOK_EXPIRED,
+}
+
+// notify sends the event to the session if the client asked for
+// events.
+func notify(session *Session, event *Event) error {
+	if event == nil || !session.Capabilities.Has(CapEvents) {
+		return nil
+	}
+	res := event.response()
+	if res == nil {
+		return nil
+	}
+	return session.Send(res)
+}
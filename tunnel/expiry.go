@@ -0,0 +1,36 @@
+package tunnel
+
+import (
+	"expvar"
+
+	"github.com/gohook/gohook-server/user"
+)
+
+// Reasons a call expired for.
+const (
+	ReasonExpiredInQueue  = "Expired Before Delivery"
+	ReasonExpiredInBuffer = "Expired In Send Buffer"
+	// Calls replayed from the backlog, to a resumed session or a
+	// polling client, that expired before they were sent. A call
+	// replayed to several sessions is recorded by each of them.
+	ReasonExpiredInBacklog = "Expired In Backlog"
+)
+
+var callsExpired = expvar.NewInt("tunnel_calls_expired")
+
+// ExpiryRecorder keeps a record of the calls dropped because they
+// expired before they were sent.
+type ExpiryRecorder interface {
+	RecordExpired(accountId user.AccountId, call HookCall, reason string) error
+}
+
+// expire drops the call and records why.
+func (s GohookTunnelServer) expire(accountId user.AccountId, call HookCall, reason string) {
+	callsExpired.Add(1)
+	if s.expiries == nil {
+		return
+	}
+	if err := s.expiries.RecordExpired(accountId, call, reason); err != nil {
+		s.logger.Log("msg", "Recording expired call failed", "account_id", accountId, "hook", call.Id, "err", err)
+	}
+}
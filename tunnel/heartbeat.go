@@ -0,0 +1,36 @@
+package tunnel
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrHeartbeatTimeout = errors.New("Heartbeat Timeout")
+
+const (
+	// DefaultHeartbeatInterval is used when the client does not ask
+	// for an interval.
+	DefaultHeartbeatInterval = 30 * time.Second
+	MinHeartbeatInterval     = 5 * time.Second
+	MaxHeartbeatInterval     = 5 * time.Minute
+
+	// MaxMissedHeartbeats is the number of unanswered pings after
+	// which a session is considered dead.
+	MaxMissedHeartbeats = 3
+)
+
+// NegotiateHeartbeat returns the heartbeat interval to use for the
+// interval requested by the client in milliseconds.
+func NegotiateHeartbeat(requestedMs int64) time.Duration {
+	if requestedMs <= 0 {
+		return DefaultHeartbeatInterval
+	}
+	interval := time.Duration(requestedMs) * time.Millisecond
+	if interval < MinHeartbeatInterval {
+		return MinHeartbeatInterval
+	}
+	if interval > MaxHeartbeatInterval {
+		return MaxHeartbeatInterval
+	}
+	return interval
+}
@@ -0,0 +1,61 @@
+package tunnel
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"golang.org/x/net/context"
+)
+
+type Middleware func(Service) Service
+
+func ServiceLoggingMiddleware(logger log.Logger) Middleware {
+	return func(next Service) Service {
+		return serviceLoggingMiddleware{
+			logger: logger,
+			next:   next,
+		}
+	}
+}
+
+type serviceLoggingMiddleware struct {
+	logger log.Logger
+	next   Service
+}
+
+func (mw serviceLoggingMiddleware) ListSessions(ctx context.Context) (v SessionInfoList, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "ListSessions",
+			"layer", "service",
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.ListSessions(ctx)
+}
+
+func (mw serviceLoggingMiddleware) KickSession(ctx context.Context, id SessionId) (err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "KickSession",
+			"layer", "service",
+			"sessionId", id,
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.KickSession(ctx, id)
+}
+
+func (mw serviceLoggingMiddleware) Presence(ctx context.Context) (v *PresenceInfo, err error) {
+	defer func(begin time.Time) {
+		mw.logger.Log(
+			"method", "Presence",
+			"layer", "service",
+			"error", err,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+	return mw.next.Presence(ctx)
+}
@@ -0,0 +1,37 @@
+package tunnel
+
+import (
+	"github.com/gohook/gohook-server/user"
+)
+
+// PresenceExpiry drops the presence of processes that went away
+// without removing it. Presence is refreshed with the sessions.
+const PresenceExpiry = SessionExpiry
+
+// PresenceInfo lists the processes holding sessions of an account.
+type PresenceInfo struct {
+	AccountId user.AccountId `json:"account_id"`
+	Instances []InstanceId   `json:"instances"`
+}
+
+// Online reports whether any process holds a session of the account.
+func (p PresenceInfo) Online() bool {
+	return len(p.Instances) > 0
+}
+
+/*
+Presence
+--------
+
+Presence records which gohookd processes hold sessions for which
+accounts, so ingress can tell whether anyone is listening before
+accepting a hook call.
+*/
+
+type Presence interface {
+	// Put records that the instance holds sessions of the account
+	// for the next PresenceExpiry.
+	Put(accountId user.AccountId, instance InstanceId) error
+	Remove(accountId user.AccountId, instance InstanceId) error
+	Find(accountId user.AccountId) (*PresenceInfo, error)
+}
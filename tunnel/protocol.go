@@ -0,0 +1,142 @@
+package tunnel
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/gohook/gohook-server/pb"
+)
+
+var ErrClientTooOld = errors.New("Client Too Old")
+
+const (
+	// ProtocolVersion is the tunnel protocol spoken by this server.
+	ProtocolVersion = 2
+
+	// MinProtocolVersion is the oldest protocol still accepted.
+	// Clients that do not open the tunnel with a TunnelOpen message
+	// speak version 0. They were built when the tunnel was a server
+	// stream, and are only sent hook calls.
+	MinProtocolVersion = 0
+)
+
+// Version of the server. It is set at build time with
+// -ldflags "-X github.com/gohook/gohook-server/tunnel.Version=...".
+var Version = "dev"
+
+// Capability is an optional tunnel feature. It is only used when
+// both the client and the server support it.
+type Capability string
+
+const (
+	// CapResume hands out resume tokens.
+	CapResume Capability = "resume"
+	// CapGoingAway sends a GoingAway event before the server closes
+	// the tunnel on shutdown.
+	CapGoingAway Capability = "going-away"
+	// CapBatching sends several hook calls per frame.
+	CapBatching Capability = "batching"
+	// CapCompression compresses large hook call bodies.
+	CapCompression Capability = "compression"
+	// CapEvents sends hook, session, quota, token and announcement
+	// events next to the hook calls.
+	CapEvents Capability = "events"
+)
+
+// ServerCapabilities are the capabilities this server supports.
+var ServerCapabilities = []Capability{
+	CapResume,
+	CapGoingAway,
+	CapBatching,
+	CapCompression,
+	CapEvents,
+}
+
+// Version 1 clients do not send their capabilities, but support
+// everything the protocol had at the time.
+var protocolV1Capabilities = []Capability{
+	CapResume,
+	CapGoingAway,
+}
+
+// Capabilities is the set of capabilities negotiated for a session.
+type Capabilities map[Capability]bool
+
+// NegotiateCapabilities keeps the client capabilities the server
+// supports as well.
+func NegotiateCapabilities(client []Capability) Capabilities {
+	caps := Capabilities{}
+	for _, c := range client {
+		for _, supported := range ServerCapabilities {
+			if c == supported {
+				caps[c] = true
+			}
+		}
+	}
+	return caps
+}
+
+func (c Capabilities) Has(capability Capability) bool {
+	return c[capability]
+}
+
+func (c Capabilities) Strings() []string {
+	list := []string{}
+	for capability := range c {
+		list = append(list, string(capability))
+	}
+	sort.Strings(list)
+	return list
+}
+
+// tunnelOpen is the decoded first message of a tunnel.
+type tunnelOpen struct {
+	protocolVersion int32
+	heartbeatMs     int64
+	resumeToken     ResumeToken
+	lastSeq         int64
+	clientName      string
+	clientVersion   string
+	labels          map[string]string
+	subscription    Subscription
+	capabilities    Capabilities
+}
+
+// decodeTunnelOpen reads the first message of a tunnel. Clients older
+// than MinProtocolVersion are refused with ErrClientTooOld.
+func decodeTunnelOpen(req *pb.TunnelRequest) (tunnelOpen, error) {
+	open := req.GetOpen()
+	if open == nil {
+		// the empty request of a version 0 client
+		return tunnelOpen{capabilities: Capabilities{}}, nil
+	}
+
+	// clients from before the negotiation did not send a version
+	version := open.ProtocolVersion
+	if version == 0 {
+		version = 1
+	}
+	if version < MinProtocolVersion {
+		return tunnelOpen{}, ErrClientTooOld
+	}
+
+	client := protocolV1Capabilities
+	if version > 1 {
+		client = []Capability{}
+		for _, c := range open.Capabilities {
+			client = append(client, Capability(c))
+		}
+	}
+
+	return tunnelOpen{
+		protocolVersion: version,
+		heartbeatMs:     open.HeartbeatIntervalMs,
+		resumeToken:     ResumeToken(open.ResumeToken),
+		lastSeq:         open.LastSeq,
+		clientName:      open.ClientName,
+		clientVersion:   open.ClientVersion,
+		labels:          open.Labels,
+		subscription:    decodeSubscription(open.Subscription),
+		capabilities:    NegotiateCapabilities(client),
+	}, nil
+}
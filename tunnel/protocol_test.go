@@ -0,0 +1,32 @@
+package tunnel
+
+import (
+	"testing"
+
+	"github.com/gohook/gohook-server/pb"
+)
+
+func TestDecodeTunnelOpenLegacy(t *testing.T) {
+	open, err := decodeTunnelOpen(&pb.TunnelRequest{})
+	if err != nil {
+		t.Fatalf("expected the empty request of a version 0 client to be accepted, got %v", err)
+	}
+	if open.protocolVersion != 0 {
+		t.Fatalf("expected protocol version 0, got %d", open.protocolVersion)
+	}
+	if len(open.capabilities) != 0 {
+		t.Fatalf("expected no capabilities, got %v", open.capabilities.Strings())
+	}
+}
+
+func TestDecodeTunnelOpenUnversioned(t *testing.T) {
+	open, err := decodeTunnelOpen(&pb.TunnelRequest{
+		Event: &pb.TunnelRequest_Open{Open: &pb.TunnelOpen{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open.protocolVersion != 1 || !open.capabilities.Has(CapResume) {
+		t.Fatalf("expected a version 1 client with resume, got %d %v", open.protocolVersion, open.capabilities.Strings())
+	}
+}
@@ -1,9 +1,17 @@
 package tunnel
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
 )
 
+// ErrQueueClosed is reported once the queue stopped delivering
+// messages for good.
+var ErrQueueClosed = errors.New("Queue Closed")
+
 /*
 Queue Interface
 ---------------
@@ -17,14 +25,56 @@ sending the message down to the client.
 */
 
 type HookCall struct {
+	// Seq orders the calls of an account. It is zero when the queue
+	// does not keep a backlog.
+	Seq    int64  `json:"seq"`
 	Id     string `json:"id"`
 	Method string `json:"method"`
 	Body   []byte `json:"body"`
+	// Labels of the hook, used to match session subscriptions
+	Labels map[string]string `json:"labels"`
+	// Expires is when the call is dropped if not delivered yet, and
+	// NotBefore when it is released to the sessions. Zero times are
+	// not set.
+	Expires   time.Time `json:"expires"`
+	NotBefore time.Time `json:"not_before"`
+	// Calls of a higher priority are sent ahead of waiting calls.
+	Priority int `json:"priority"`
 }
 
+// Expired reports whether the call expired by now.
+func (c HookCall) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !now.Before(c.Expires)
+}
+
+type MessageType int
+
+const (
+	// MessageHook carries a hook call to the sessions of an account.
+	MessageHook MessageType = iota
+	// MessageDisconnect closes every session of an account.
+	MessageDisconnect
+	// MessageKick closes a single session of an account.
+	MessageKick
+	// MessageEvent sends an event to the sessions of an account, or
+	// to every session when the account id is empty.
+	MessageEvent
+)
+
 type QueueMessage struct {
+	Type      MessageType
 	AccountId user.AccountId
+	SessionId SessionId
 	Hook      HookCall
+	// Reason is sent to the client when its session is closed.
+	Reason string
+	// Event is the event of a MessageEvent. Disconnect and kick
+	// messages can carry one to notify the sessions first.
+	Event *Event
+
+	// Ack is set by queues that redeliver messages until they are
+	// acknowledged. It is called once the message has been handled.
+	Ack func() error
 }
 
 type ReceiveC chan *QueueMessage
@@ -33,3 +83,51 @@ type HookQueue interface {
 	Broadcast(message *QueueMessage) error
 	Listen() (ReceiveC, error)
 }
+
+// AccountSubscriber is implemented by queues that only deliver the
+// messages of an account to the processes subscribed to it. Calls are
+// counted, so every Subscribe has to be matched by an Unsubscribe.
+// Messages without an account are delivered to every process.
+type AccountSubscriber interface {
+	Subscribe(accountId user.AccountId) error
+	Unsubscribe(accountId user.AccountId) error
+}
+
+// HealthReporter is implemented by queues that can lose their
+// connection to the broker. Health returns nil while messages are
+// delivered, and the reason otherwise.
+type HealthReporter interface {
+	Health() error
+}
+
+// DelayedQueue is implemented by queues that keep the hook calls with
+// a NotBefore time until they are due. Delayed calls are stored with
+// the other queued messages, so they outlive the process that took
+// them.
+type DelayedQueue interface {
+	// Delay keeps the message until the NotBefore time of its call.
+	Delay(message *QueueMessage) error
+	// Due hands out up to max messages due by now. Every message has
+	// to be acked with its Ack func once it has been broadcast, or it
+	// is handed out again after DelayLease.
+	Due(now time.Time, max int) ([]*QueueMessage, error)
+}
+
+// DelayLease is how long a delayed message handed out by Due is held
+// back from the other processes.
+const DelayLease = 30 * time.Second
+
+// ContextBroadcaster is implemented by queues that can wait for room
+// to broadcast a message. They wait until the context is done at most.
+type ContextBroadcaster interface {
+	BroadcastContext(ctx context.Context, message *QueueMessage) error
+}
+
+// BroadcastContext broadcasts the message, bounded by ctx when the
+// queue supports it.
+func BroadcastContext(ctx context.Context, q HookQueue, message *QueueMessage) error {
+	if b, ok := q.(ContextBroadcaster); ok {
+		return b.BroadcastContext(ctx, message)
+	}
+	return q.Broadcast(message)
+}
@@ -0,0 +1,66 @@
+package tunnel
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gohook/gohook-server/user"
+	"github.com/satori/go.uuid"
+)
+
+const (
+	// SessionRefreshInterval is how often a process refreshes the
+	// sessions it holds in the registry.
+	SessionRefreshInterval = time.Minute
+
+	// SessionExpiry drops sessions of processes that went away
+	// without removing them.
+	SessionExpiry = 3 * SessionRefreshInterval
+)
+
+// InstanceId identifies a running gohookd process.
+type InstanceId string
+
+func NewInstanceId() InstanceId {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "gohookd"
+	}
+	return InstanceId(fmt.Sprintf("%s-%s", host, uuid.NewV4().String()[:8]))
+}
+
+// SessionInfo describes a connected session.
+type SessionInfo struct {
+	Id            SessionId         `json:"id"`
+	AccountId     user.AccountId    `json:"account_id"`
+	Instance      InstanceId        `json:"instance"`
+	Start         time.Time         `json:"start"`
+	ClientIP      string            `json:"client_ip"`
+	ClientName    string            `json:"client_name"`
+	ClientVersion string            `json:"client_version"`
+	Protocol      int32             `json:"protocol"`
+	Capabilities  []string          `json:"capabilities"`
+	Labels        map[string]string `json:"labels"`
+	Delivered     int64             `json:"delivered"`
+	Subscription  Subscription      `json:"subscription"`
+	Expires       time.Time         `json:"expires"`
+}
+
+type SessionInfoList []*SessionInfo
+
+/*
+Session Registry
+----------------
+
+The SessionRegistry tracks the sessions held by every gohookd
+process, so they can be listed and managed no matter which
+process a request lands on. Every process puts its sessions
+again at the refresh interval and removes them once they end.
+*/
+
+type SessionRegistry interface {
+	Put(info *SessionInfo) error
+	Remove(info *SessionInfo) error
+	FindByAccountId(accountId user.AccountId) (SessionInfoList, error)
+}
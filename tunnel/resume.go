@@ -0,0 +1,227 @@
+package tunnel
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+var (
+	ErrResumeExpired  = errors.New("Resume Token Expired")
+	ErrSessionResumed = errors.New("Session Resumed")
+)
+
+const (
+	// ResumeRetention is how long hook calls and resume tokens are
+	// kept around for reconnecting clients. It has to be longer than
+	// MaxHeartbeatInterval since tokens are refreshed on every ping.
+	ResumeRetention = 10 * time.Minute
+
+	// ResumeBacklog caps the number of hook calls kept per account.
+	ResumeBacklog = 1000
+)
+
+type ResumeToken string
+
+// ResumeState is what a resume token points to.
+type ResumeState struct {
+	AccountId user.AccountId
+	SessionId SessionId
+}
+
+/*
+Resume Store
+------------
+
+The ResumeStore keeps the recent hook calls of every account under
+an increasing sequence number, and the resume tokens handed out to
+sessions. A client that reconnects presents its token and the last
+sequence number it has seen to get the calls it missed. Clients
+polling for calls instead move a per account cursor by acking them.
+
+Sequence numbers are kept per account rather than per session. Every
+session of an account is sent from the same backlog, a resumed
+session is a new stream picking up where the old one left, and
+polling clients share the numbers as well. A session sees gaps in
+the numbers for calls outside its subscription, and keeps the calls
+it has sent in a seqWindow since calls can arrive out of order.
+*/
+
+type ResumeStore interface {
+	// Append stores the call in the backlog of the account and
+	// returns the sequence number given to it.
+	Append(accountId user.AccountId, call HookCall) (int64, error)
+	// Current returns the highest sequence number given to a call of
+	// the account, zero before the first one.
+	Current(accountId user.AccountId) (int64, error)
+	// Since returns the retained calls after seq. complete is false
+	// when calls after seq have already been dropped.
+	Since(accountId user.AccountId, seq int64) (calls []HookCall, complete bool, err error)
+
+	NewToken(state ResumeState) (ResumeToken, error)
+	FindToken(token ResumeToken) (*ResumeState, error)
+	// Touch restarts the retention window of the token.
+	Touch(token ResumeToken) error
+
+	// Ack moves the delivery cursor of the account forward to seq.
+	// Polling clients are given the calls after the cursor.
+	Ack(accountId user.AccountId, seq int64) error
+	// Acked returns the delivery cursor of the account.
+	Acked(accountId user.AccountId) (int64, error)
+}
+
+var ErrDelayUnsupported = errors.New("Queue Does Not Support Delayed Calls")
+
+const (
+	// DelayPollInterval is how often the queue is asked for the
+	// delayed calls that are due.
+	DelayPollInterval = time.Second
+
+	// delayBatch is the number of due calls released at once.
+	delayBatch = 100
+)
+
+var (
+	callsDelayed      = expvar.NewInt("tunnel_calls_delayed")
+	delayedCallErrors = expvar.NewInt("tunnel_delayed_call_errors")
+)
+
+// NewResumableQueue sequences every hook call broadcast on the queue
+// and keeps it in the backlog of its account. Calls with a NotBefore
+// time are kept by the next queue until then, and only sequenced once
+// released so sessions see them in order. Listening releases them.
+func NewResumableQueue(next HookQueue, store ResumeStore) HookQueue {
+	return &resumableQueue{
+		next:    next,
+		store:   store,
+		release: &sync.Once{},
+	}
+}
+
+type resumableQueue struct {
+	next  HookQueue
+	store ResumeStore
+
+	// starts the release loop with the first listener
+	release *sync.Once
+}
+
+func (q resumableQueue) Broadcast(message *QueueMessage) error {
+	if delayed, err := q.delay(message); delayed {
+		return err
+	}
+	if err := q.sequence(message); err != nil {
+		return err
+	}
+	return q.next.Broadcast(message)
+}
+
+func (q resumableQueue) BroadcastContext(ctx context.Context, message *QueueMessage) error {
+	if delayed, err := q.delay(message); delayed {
+		return err
+	}
+	if err := q.sequence(message); err != nil {
+		return err
+	}
+	return BroadcastContext(ctx, q.next, message)
+}
+
+// delay hands hook calls that are not due yet to the next queue to
+// keep, and reports whether it did.
+func (q resumableQueue) delay(message *QueueMessage) (bool, error) {
+	if message.Type != MessageHook || !message.Hook.NotBefore.After(time.Now()) {
+		return false, nil
+	}
+	dq, ok := q.next.(DelayedQueue)
+	if !ok {
+		return true, ErrDelayUnsupported
+	}
+	callsDelayed.Add(1)
+	return true, dq.Delay(message)
+}
+
+// releaseDue broadcasts the delayed calls that are due until the queue
+// is closed. Every listening process releases calls, the queue hands
+// each of them to one process at a time.
+func (q resumableQueue) releaseDue(dq DelayedQueue) {
+	poll := time.NewTicker(DelayPollInterval)
+	defer poll.Stop()
+	for range poll.C {
+		for {
+			messages, err := dq.Due(time.Now(), delayBatch)
+			if err == ErrQueueClosed {
+				return
+			}
+			if err != nil {
+				delayedCallErrors.Add(1)
+				break
+			}
+			for _, message := range messages {
+				// the ack belongs to the delayed copy, not to the
+				// message broadcast
+				ack := message.Ack
+				message.Ack = nil
+				// calls not acked are handed out again after DelayLease
+				if err := q.sequence(message); err != nil {
+					delayedCallErrors.Add(1)
+					continue
+				}
+				if err := q.next.Broadcast(message); err != nil {
+					delayedCallErrors.Add(1)
+					continue
+				}
+				if ack != nil && ack() != nil {
+					delayedCallErrors.Add(1)
+				}
+			}
+			if len(messages) < delayBatch {
+				break
+			}
+		}
+	}
+}
+
+func (q resumableQueue) sequence(message *QueueMessage) error {
+	if message.Type == MessageHook {
+		seq, err := q.store.Append(message.AccountId, message.Hook)
+		if err != nil {
+			return err
+		}
+		message.Hook.Seq = seq
+	}
+	return nil
+}
+
+func (q resumableQueue) Listen() (ReceiveC, error) {
+	if dq, ok := q.next.(DelayedQueue); ok {
+		q.release.Do(func() {
+			go q.releaseDue(dq)
+		})
+	}
+	return q.next.Listen()
+}
+
+func (q resumableQueue) Subscribe(accountId user.AccountId) error {
+	if sub, ok := q.next.(AccountSubscriber); ok {
+		return sub.Subscribe(accountId)
+	}
+	return nil
+}
+
+func (q resumableQueue) Unsubscribe(accountId user.AccountId) error {
+	if sub, ok := q.next.(AccountSubscriber); ok {
+		return sub.Unsubscribe(accountId)
+	}
+	return nil
+}
+
+func (q resumableQueue) Health() error {
+	if h, ok := q.next.(HealthReporter); ok {
+		return h.Health()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package tunnel
+
+import "sync"
+
+// seqWindowSize bounds the sequence numbers remembered above the low
+// water mark. Gaps older than the backlog can not be filled anyway.
+const seqWindowSize = ResumeBacklog
+
+/*
+Sequence Window
+---------------
+
+Sequence numbers are handed out before calls are published, so
+calls published by different processes can arrive out of order,
+and calls of a higher priority are sent ahead of older ones. A
+session can therefore not skip every call below the highest
+number it has sent. The window keeps the low water mark below
+which every call was sent, and the numbers sent above it.
+*/
+
+type seqWindow struct {
+	mtx sync.Mutex
+	// every call up to base was sent, or is no longer retained
+	base int64
+	sent map[int64]bool
+}
+
+func newSeqWindow(base int64) *seqWindow {
+	return &seqWindow{
+		base: base,
+		sent: map[int64]bool{},
+	}
+}
+
+// lowWater is the sequence number up to which every call was sent.
+func (w *seqWindow) lowWater() int64 {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.base
+}
+
+// raise moves the low water mark up to base, for calls the client
+// already has.
+func (w *seqWindow) raise(base int64) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if base <= w.base {
+		return
+	}
+	for seq := range w.sent {
+		if seq <= base {
+			delete(w.sent, seq)
+		}
+	}
+	w.base = base
+	w.compact()
+}
+
+// add marks seq as sent, and reports false when it already was. Calls
+// the session leaves out on purpose are added as well, so they do not
+// hold up the low water mark.
+func (w *seqWindow) add(seq int64) bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if seq <= w.base || w.sent[seq] {
+		return false
+	}
+	w.sent[seq] = true
+	if len(w.sent) > seqWindowSize {
+		lowest := seq
+		for s := range w.sent {
+			if s < lowest {
+				lowest = s
+			}
+		}
+		w.base = lowest - 1
+	}
+	w.compact()
+	return true
+}
+
+// compact moves the low water mark over the calls sent without a gap.
+// Once too many numbers are waiting on a gap the mark skips it.
+func (w *seqWindow) compact() {
+	for w.sent[w.base+1] {
+		delete(w.sent, w.base+1)
+		w.base++
+	}
+}
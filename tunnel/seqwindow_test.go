@@ -0,0 +1,50 @@
+package tunnel
+
+import "testing"
+
+func TestSeqWindowOutOfOrder(t *testing.T) {
+	w := newSeqWindow(0)
+	if !w.add(2) {
+		t.Fatal("expected 2 to be new")
+	}
+	if w.lowWater() != 0 {
+		t.Fatalf("expected the gap at 1 to hold the low water mark, got %d", w.lowWater())
+	}
+	if !w.add(1) {
+		t.Fatal("expected 1 to be sent after 2")
+	}
+	if w.lowWater() != 2 {
+		t.Fatalf("expected the low water mark at 2, got %d", w.lowWater())
+	}
+	if w.add(1) || w.add(2) {
+		t.Fatal("expected sent calls to be skipped")
+	}
+}
+
+func TestSeqWindowRaise(t *testing.T) {
+	w := newSeqWindow(0)
+	w.add(7)
+	w.raise(5)
+	if w.add(3) {
+		t.Fatal("expected calls the client has to be skipped")
+	}
+	if !w.add(6) {
+		t.Fatal("expected 6 to be new")
+	}
+	if w.lowWater() != 7 {
+		t.Fatalf("expected the low water mark at 7, got %d", w.lowWater())
+	}
+}
+
+func TestSeqWindowSkipsOldGaps(t *testing.T) {
+	w := newSeqWindow(0)
+	for seq := int64(2); seq <= seqWindowSize+2; seq++ {
+		w.add(seq)
+	}
+	if w.lowWater() != seqWindowSize+2 {
+		t.Fatalf("expected the gap to be given up, got %d", w.lowWater())
+	}
+	if len(w.sent) != 0 {
+		t.Fatalf("expected an empty window, got %d", len(w.sent))
+	}
+}
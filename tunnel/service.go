@@ -0,0 +1,83 @@
+package tunnel
+
+import (
+	"errors"
+
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+var ErrSessionNotFound = errors.New("Session Not Found")
+
+const kickReason = "Session Kicked"
+
+/*
+Session Service
+---------------
+
+The session service lets a client see and manage the tunnel
+sessions of its account. The sessions are read from the session
+registry, and kicks are broadcast on the queue so the process
+holding the session closes it.
+*/
+
+type Service interface {
+	ListSessions(ctx context.Context) (SessionInfoList, error)
+	KickSession(ctx context.Context, id SessionId) error
+	Presence(ctx context.Context) (*PresenceInfo, error)
+}
+
+func NewBasicService(registry SessionRegistry, presence Presence, queue HookQueue) Service {
+	return &basicService{
+		registry: registry,
+		presence: presence,
+		queue:    queue,
+	}
+}
+
+type basicService struct {
+	registry SessionRegistry
+	presence Presence
+	queue    HookQueue
+}
+
+func (s basicService) ListSessions(ctx context.Context) (SessionInfoList, error) {
+	account := ctx.Value("account").(*user.Account)
+	return s.registry.FindByAccountId(account.Id)
+}
+
+func (s basicService) KickSession(ctx context.Context, id SessionId) error {
+	account := ctx.Value("account").(*user.Account)
+	sessions, err := s.registry.FindByAccountId(account.Id)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, session := range sessions {
+		if session.Id == id {
+			found = true
+			s.registry.Remove(session)
+		}
+	}
+	if !found {
+		return ErrSessionNotFound
+	}
+
+	return s.queue.Broadcast(&QueueMessage{
+		Type:      MessageKick,
+		AccountId: account.Id,
+		SessionId: id,
+		Reason:    kickReason,
+		Event: &Event{
+			Type:      EventSessionKicked,
+			SessionId: id,
+			Message:   kickReason,
+		},
+	})
+}
+
+func (s basicService) Presence(ctx context.Context) (*PresenceInfo, error) {
+	account := ctx.Value("account").(*user.Account)
+	return s.presence.Find(account.Id)
+}
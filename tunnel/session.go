@@ -3,19 +3,386 @@ package tunnel
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gohook/gohook-server/pb"
 	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+)
+
+var (
+	ErrSessionClosed = errors.New("Session Closed")
+	ErrFlushTimeout  = errors.New("Flush Timeout")
 )
 
 type SessionId string
 
+// TunnelStream carries the messages of a session. It is implemented by
+// gRPC tunnel streams, and by the WebSocket and SSE transports.
+type TunnelStream interface {
+	Context() context.Context
+	Send(*pb.TunnelResponse) error
+	Recv() (*pb.TunnelRequest, error)
+}
+
 type Session struct {
+	// number of hook calls sent, updated atomically. It comes first
+	// so it stays 64-bit aligned on 32-bit platforms.
+	delivered int64
+
 	Id        SessionId
 	AccountId user.AccountId
 	Start     time.Time
-	Stream    pb.Gohook_TunnelServer
+	Stream    TunnelStream
+
+	// Token the client presents to resume the session
+	ResumeToken ResumeToken
+
+	ClientIP        string
+	ClientName      string
+	ClientVersion   string
+	ProtocolVersion int32
+	Labels          map[string]string
+
+	// Capabilities negotiated with the client
+	Capabilities Capabilities
+
+	// OnExpired is told about the buffered and replayed calls that
+	// expired before they were sent, and why.
+	OnExpired func(call HookCall, reason string)
+
+	subMtx       sync.RWMutex
+	subscription Subscription
+
+	// outbound hook calls waiting for the writer
+	buffer *sendBuffer
+	// set while spilled calls are dead lettered, updated atomically
+	overflowing int32
+
+	// grpc streams do not support concurrent sends
+	sendMtx sync.Mutex
+	// sequence numbers of the hook calls sent
+	seqs *seqWindow
+	// closed once Open has sent the ready message and the backlog
+	opened chan struct{}
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	closeErr  error
+}
+
+func NewSession(id SessionId, accountId user.AccountId, stream TunnelStream, opts BufferOptions) *Session {
+	return &Session{
+		Id:        id,
+		AccountId: accountId,
+		Start:     time.Now(),
+		Stream:    stream,
+		buffer:    newSendBuffer(opts),
+		seqs:      newSeqWindow(0),
+		opened:    make(chan struct{}),
+		closed:    make(chan struct{}),
+	}
+}
+
+// Open sends the ready message followed by the calls the client
+// missed since lastSeq. Nothing else is sent on the session before.
+// Version 0 clients do not know the ready message and go without.
+func (s *Session) Open(ready *pb.TunnelReady, lastSeq int64, backlog []HookCall) error {
+	s.sendMtx.Lock()
+	defer s.sendMtx.Unlock()
+	defer close(s.opened)
+
+	if s.ProtocolVersion > 0 {
+		err := s.Stream.Send(&pb.TunnelResponse{
+			Event: &pb.TunnelResponse_Ready{
+				Ready: ready,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	s.seqs.raise(lastSeq)
+	return s.sendHooks(backlog)
+}
+
+// Send writes a message to the client stream once the session has
+// been opened.
+func (s *Session) Send(message *pb.TunnelResponse) error {
+	select {
+	case <-s.opened:
+	case <-s.closed:
+		return ErrSessionClosed
+	}
+	s.sendMtx.Lock()
+	defer s.sendMtx.Unlock()
+	if s.Err() != nil {
+		return ErrSessionClosed
+	}
+	return s.Stream.Send(message)
+}
+
+// Enqueue buffers the call for the writer of the session so a slow
+// client does not hold up the caller. ErrSlowConsumer is returned when
+// the buffer policy asks for the session to be closed. A call dropped
+// from the full buffer is returned, the session will never send it.
+func (s *Session) Enqueue(call HookCall) (*HookCall, error) {
+	if !s.Subscription().Matches(call) {
+		s.skip(call)
+		return nil, nil
+	}
+	dropped, err := s.buffer.push(call)
+	if dropped != nil {
+		s.skip(*dropped)
+	}
+	return dropped, err
+}
+
+// skip marks a call the session will never send, so it does not hold
+// up the low water mark of the sequence numbers.
+func (s *Session) skip(call HookCall) {
+	if call.Seq != 0 {
+		s.seqs.add(call.Seq)
+	}
+}
+
+// DropSpilled hands the spilled calls the session has not sent yet to
+// drop, and marks them as sent. It holds the send lock so the writer
+// does not send them as well. Only one call runs at a time, others
+// return right away.
+func (s *Session) DropSpilled(backlog func(lastSeq int64) ([]HookCall, error), drop func(call HookCall)) error {
+	if !atomic.CompareAndSwapInt32(&s.overflowing, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreInt32(&s.overflowing, 0)
+
+	s.sendMtx.Lock()
+	defer s.sendMtx.Unlock()
+	from := s.buffer.spillStart()
+	if from == 0 {
+		return nil
+	}
+	calls, err := backlog(s.seqs.lowWater())
+	if err != nil {
+		return err
+	}
+	subscription := s.Subscription()
+	for _, call := range calls {
+		// calls before the spill are still in the buffer
+		if call.Seq < from || !s.seqs.add(call.Seq) {
+			continue
+		}
+		if subscription.Matches(call) {
+			drop(call)
+		}
+	}
+	s.buffer.restartSpill()
+	return nil
+}
+
+// BufferDepth is the number of calls waiting to be sent.
+func (s *Session) BufferDepth() int {
+	return s.buffer.len()
+}
+
+// WriteLoop sends the buffered calls until the session ends. Spilled
+// calls are read back with backlog, which returns the calls after the
+// given sequence number.
+func (s *Session) WriteLoop(backlog func(lastSeq int64) ([]HookCall, error)) {
+	select {
+	case <-s.opened:
+	case <-s.closed:
+		return
+	}
+
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-s.Stream.Context().Done():
+			return
+		case <-s.buffer.notify:
+		}
+
+		if s.Capabilities.Has(CapBatching) && !s.collect() {
+			return
+		}
+
+		calls, expired, spilled := s.buffer.take()
+		for _, call := range expired {
+			s.skip(call)
+			if s.OnExpired != nil {
+				s.OnExpired(call, ReasonExpiredInBuffer)
+			}
+		}
+		if spilled {
+			missed, err := backlog(s.LastSeq())
+			if err != nil {
+				s.Close(err)
+				return
+			}
+			calls = append(missed, calls...)
+		}
+		if err := s.SendHooks(calls); err != nil {
+			s.Close(err)
+			return
+		}
+		s.buffer.done()
+	}
+}
+
+// collect waits for a burst of calls to fill the buffer, until
+// BatchWindow has passed or a full frame is waiting. It returns false
+// when the session ended meanwhile.
+func (s *Session) collect() bool {
+	timer := time.NewTimer(BatchWindow)
+	defer timer.Stop()
+	for s.buffer.len() < BatchMaxCalls {
+		select {
+		case <-timer.C:
+			return true
+		case <-s.buffer.notify:
+		case <-s.closed:
+			return false
+		case <-s.Stream.Context().Done():
+			return false
+		}
+	}
+	return true
+}
+
+// Flush waits until the writer has sent every buffered call, or until
+// done is closed.
+func (s *Session) Flush(done <-chan struct{}) error {
+	poll := time.NewTicker(10 * time.Millisecond)
+	defer poll.Stop()
+	for !s.buffer.flushed() {
+		select {
+		case <-poll.C:
+		case <-s.closed:
+			return ErrSessionClosed
+		case <-done:
+			return ErrFlushTimeout
+		}
+	}
+	return nil
+}
+
+// LastSeq is the sequence number up to which every hook call of the
+// session was sent. Calls after it may have been sent as well.
+func (s *Session) LastSeq() int64 {
+	return s.seqs.lowWater()
+}
+
+// SendHooks sends the calls that have not already been sent on this
+// session, leaving out expired calls and reporting them to OnExpired.
+// Clients that negotiated batching
+// get them in as few frames as possible.
+func (s *Session) SendHooks(calls []HookCall) error {
+	select {
+	case <-s.opened:
+	case <-s.closed:
+		return ErrSessionClosed
+	}
+	s.sendMtx.Lock()
+	defer s.sendMtx.Unlock()
+	return s.sendHooks(calls)
+}
+
+func (s *Session) sendHooks(calls []HookCall) error {
+	subscription := s.Subscription()
+	compress := s.Capabilities.Has(CapCompression)
+	pending := []*pb.HookCall{}
+	now := time.Now()
+	for _, call := range calls {
+		if call.Seq != 0 && !s.seqs.add(call.Seq) {
+			continue
+		}
+		if !subscription.Matches(call) {
+			continue
+		}
+		if call.Expired(now) {
+			if s.OnExpired != nil {
+				s.OnExpired(call, ReasonExpiredInBacklog)
+			}
+			continue
+		}
+		pending = append(pending, encodeHookCall(call, compress))
+	}
+
+	frames := [][]*pb.HookCall{}
+	if s.Capabilities.Has(CapBatching) {
+		frames = frameHookCalls(pending)
+	} else {
+		for _, call := range pending {
+			frames = append(frames, []*pb.HookCall{call})
+		}
+	}
+	for _, frame := range frames {
+		if err := s.Stream.Send(hookFrame(frame)); err != nil {
+			return err
+		}
+		atomic.AddInt64(&s.delivered, int64(len(frame)))
+	}
+	return nil
+}
+
+func (s *Session) Subscription() Subscription {
+	s.subMtx.RLock()
+	defer s.subMtx.RUnlock()
+	return s.subscription
+}
+
+// Subscribe replaces the subscription of the session. It applies to
+// every call sent afterwards.
+func (s *Session) Subscribe(subscription Subscription) {
+	s.subMtx.Lock()
+	defer s.subMtx.Unlock()
+	s.subscription = subscription
+}
+
+// Info describes the session for the session registry.
+func (s *Session) Info(instance InstanceId) *SessionInfo {
+	return &SessionInfo{
+		Id:            s.Id,
+		AccountId:     s.AccountId,
+		Instance:      instance,
+		Start:         s.Start,
+		ClientIP:      s.ClientIP,
+		ClientName:    s.ClientName,
+		ClientVersion: s.ClientVersion,
+		Protocol:      s.ProtocolVersion,
+		Capabilities:  s.Capabilities.Strings(),
+		Labels:        s.Labels,
+		Delivered:     atomic.LoadInt64(&s.delivered),
+		Subscription:  s.Subscription(),
+		Expires:       time.Now().Add(SessionExpiry),
+	}
+}
+
+// Close ends the session. The error is returned to the client when
+// the stream handler exits.
+func (s *Session) Close(err error) {
+	s.closeOnce.Do(func() {
+		s.closeErr = err
+		close(s.closed)
+	})
+}
+
+// Done is closed once the session has been closed by the server.
+func (s *Session) Done() <-chan struct{} {
+	return s.closed
+}
+
+func (s *Session) Err() error {
+	select {
+	case <-s.closed:
+		return s.closeErr
+	default:
+		return nil
+	}
 }
 
 type SessionList []*Session
@@ -40,14 +407,36 @@ func (s *SessionStore) Add(session *Session) error {
 	return nil
 }
 
-func (s *SessionStore) Remove(accountId user.AccountId, id SessionId) error {
+// AddLimited adds the session unless the account already holds as
+// many sessions as its limits allow. others is the number of sessions
+// of the account held by other processes. A session it resumes is
+// closed but still in the store, and is not counted.
+func (s *SessionStore) AddLimited(session *Session, limits user.Limits, others int) error {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
-	sessions, ok := s.sessions[accountId]
+	count := others
+	for _, other := range s.sessions[session.AccountId] {
+		if other.Id != session.Id {
+			count++
+		}
+	}
+	if err := limits.CheckSessions(count); err != nil {
+		return err
+	}
+	s.sessions[session.AccountId] = append(s.sessions[session.AccountId], session)
+	return nil
+}
+
+// Remove drops the session from the store. Sessions are matched by
+// identity since a resumed session reuses the id of the old one.
+func (s *SessionStore) Remove(session *Session) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	sessions, ok := s.sessions[session.AccountId]
 	if ok {
-		for i, session := range sessions {
-			if session.Id == id {
-				s.sessions[accountId] = append(s.sessions[accountId][:i], s.sessions[accountId][i+1:]...)
+		for i, other := range sessions {
+			if other == session {
+				s.sessions[session.AccountId] = append(sessions[:i], sessions[i+1:]...)
 				return nil
 			}
 		}
@@ -55,6 +444,17 @@ func (s *SessionStore) Remove(accountId user.AccountId, id SessionId) error {
 	return errors.New("Not Found")
 }
 
+// All returns every session held by the store.
+func (s *SessionStore) All() SessionList {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	all := SessionList{}
+	for _, sessions := range s.sessions {
+		all = append(all, sessions...)
+	}
+	return all
+}
+
 func (s *SessionStore) FindBySessionId(id SessionId) (*Session, error) {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
@@ -73,7 +473,8 @@ func (s *SessionStore) FindByAccountId(accountId user.AccountId) (SessionList, e
 	defer s.mtx.RUnlock()
 	sessions, ok := s.sessions[accountId]
 	if ok {
-		return sessions, nil
+		// copy so callers can range over the list without holding the lock
+		return append(SessionList{}, sessions...), nil
 	}
 	return nil, errors.New("Not Found")
 }
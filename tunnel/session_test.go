@@ -0,0 +1,26 @@
+package tunnel
+
+import (
+	"testing"
+
+	"github.com/gohook/gohook-server/user"
+)
+
+func TestAddLimitedResumed(t *testing.T) {
+	store := NewSessionStore()
+	limits := user.Limits{MaxSessions: 1}
+	account := user.AccountId("account")
+
+	old := NewSession("session", account, nil, BufferOptions{})
+	if err := store.AddLimited(old, limits, 0); err != nil {
+		t.Fatal(err)
+	}
+	resumed := NewSession("session", account, nil, BufferOptions{})
+	if err := store.AddLimited(resumed, limits, 0); err != nil {
+		t.Fatalf("expected the resumed session to replace the old one, got %v", err)
+	}
+	other := NewSession("other", account, nil, BufferOptions{})
+	if _, ok := store.AddLimited(other, limits, 0).(user.QuotaError); !ok {
+		t.Fatal("expected a new session over the limit to be refused")
+	}
+}
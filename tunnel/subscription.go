@@ -0,0 +1,57 @@
+package tunnel
+
+import (
+	"github.com/gohook/gohook-server/pb"
+)
+
+// Subscription selects the hooks a session receives calls for. A call
+// matches when its hook id is listed or when the hook has every label
+// of the selector. The zero value matches every call.
+type Subscription struct {
+	HookIds  []string          `json:"hook_ids"`
+	Selector map[string]string `json:"selector"`
+}
+
+func (s Subscription) Empty() bool {
+	return len(s.HookIds) == 0 && len(s.Selector) == 0
+}
+
+func (s Subscription) Matches(call HookCall) bool {
+	if s.Empty() {
+		return true
+	}
+	for _, id := range s.HookIds {
+		if id == call.Id {
+			return true
+		}
+	}
+	if len(s.Selector) == 0 {
+		return false
+	}
+	for k, v := range s.Selector {
+		if label, ok := call.Labels[k]; !ok || label != v {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeSubscription(s Subscription) *pb.Subscription {
+	if s.Empty() {
+		return nil
+	}
+	return &pb.Subscription{
+		HookIds:  s.HookIds,
+		Selector: s.Selector,
+	}
+}
+
+func decodeSubscription(s *pb.Subscription) Subscription {
+	if s == nil {
+		return Subscription{}
+	}
+	return Subscription{
+		HookIds:  s.HookIds,
+		Selector: s.Selector,
+	}
+}
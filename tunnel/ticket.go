@@ -0,0 +1,77 @@
+package tunnel
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// TicketTTL is how long a ticket can be used to open a tunnel.
+const TicketTTL = 30 * time.Second
+
+var ErrInvalidTicket = errors.New("Invalid Ticket")
+
+// ticketSealer issues the tickets browsers open tunnels with, since
+// they can not set headers on WebSocket and EventSource requests. A
+// ticket is the account token sealed with its expiry, so it can be put
+// in the url without giving the token away. Every process sharing the
+// secret can open the tickets of the others.
+type ticketSealer struct {
+	aead cipher.AEAD
+}
+
+// newTicketSealer derives the key from secret. Without a secret a
+// random one is used, and tickets only work on the process that
+// issued them.
+func newTicketSealer(secret []byte) (ticketSealer, error) {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return ticketSealer{}, err
+		}
+	}
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return ticketSealer{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return ticketSealer{}, err
+	}
+	return ticketSealer{aead: aead}, nil
+}
+
+// issue seals the token into a ticket valid until expires.
+func (t ticketSealer) issue(token string, expires time.Time) (string, error) {
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	plain := make([]byte, 8, 8+len(token))
+	binary.BigEndian.PutUint64(plain, uint64(expires.Unix()))
+	plain = append(plain, token...)
+	return base64.RawURLEncoding.EncodeToString(t.aead.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// open returns the token of a ticket that has not expired by now.
+func (t ticketSealer) open(ticket string, now time.Time) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(ticket)
+	if err != nil || len(data) < t.aead.NonceSize() {
+		return "", ErrInvalidTicket
+	}
+	n := t.aead.NonceSize()
+	plain, err := t.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil || len(plain) < 8 {
+		return "", ErrInvalidTicket
+	}
+	if now.Unix() > int64(binary.BigEndian.Uint64(plain[:8])) {
+		return "", ErrInvalidTicket
+	}
+	return string(plain[8:]), nil
+}
@@ -0,0 +1,36 @@
+package tunnel
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestTicket(t *testing.T) {
+	tickets, err := newTicketSealer([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	ticket, err := tickets.issue("token", now.Add(TicketTTL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token, err := tickets.open(ticket, now); err != nil || token != "token" {
+		t.Fatalf("expected the token back, got %q, %v", token, err)
+	}
+	if _, err := tickets.open(ticket, now.Add(TicketTTL+time.Second)); err != ErrInvalidTicket {
+		t.Fatalf("expected the expired ticket to be refused, got %v", err)
+	}
+	data, _ := base64.RawURLEncoding.DecodeString(ticket)
+	data[len(data)-1] ^= 1
+	if _, err := tickets.open(base64.RawURLEncoding.EncodeToString(data), now); err != ErrInvalidTicket {
+		t.Fatalf("expected the altered ticket to be refused, got %v", err)
+	}
+
+	other, _ := newTicketSealer([]byte("other"))
+	if _, err := other.open(ticket, now); err != ErrInvalidTicket {
+		t.Fatalf("expected the ticket of another secret to be refused, got %v", err)
+	}
+}
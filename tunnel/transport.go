@@ -2,14 +2,21 @@ package tunnel
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
 	"github.com/go-kit/kit/log"
 	"github.com/gohook/gohook-server/pb"
 	"github.com/gohook/gohook-server/user"
 	"github.com/satori/go.uuid"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"time"
+	"google.golang.org/grpc/peer"
 )
 
 type GohookTunnelServer struct {
@@ -22,32 +29,209 @@ type GohookTunnelServer struct {
 	// Session Store for adding new sessions
 	sessions *SessionStore
 
+	// Resume Store for handing out resume tokens and replaying
+	// missed hook calls
+	resumes ResumeStore
+
+	// Session Registry shared by every process and the id this
+	// process registers its sessions under
+	registry SessionRegistry
+	instance InstanceId
+
+	// Presence of this process for the accounts it holds sessions of
+	presence Presence
+
+	// Send buffer size and slow consumer policy of new sessions
+	buffers BufferOptions
+
+	// closed once the server starts draining
+	draining chan struct{}
+	// tunnel connections taken over from the HTTP server
+	hijacked *hijackedConns
+
+	// clients polling for hook calls
+	watchers *watchers
+
+	// calls that could not be delivered
+	deadLetters DeadLetterStore
+	// calls dropped because they expired
+	expiries ExpiryRecorder
+
+	// closed once the queue stopped delivering messages
+	queueClosed chan struct{}
+
 	// Message logger
 	logger log.Logger
 }
 
+// Health returns nil while the server receives the messages of the
+// queue. ErrQueueClosed is returned once the queue gave up.
+func (s GohookTunnelServer) Health() error {
+	select {
+	case <-s.queueClosed:
+		return ErrQueueClosed
+	default:
+	}
+	if h, ok := s.queue.(HealthReporter); ok {
+		return h.Health()
+	}
+	return nil
+}
+
+// SendToStream buffers the call on every session of the account held
+// by this process. ErrNoSessions is returned when there are none, and
+// the last error when no session took the call. Calls dropped from
+// full buffers, or spilled for too long, are dead lettered. Expired
+// calls are dropped.
 func (s GohookTunnelServer) SendToStream(accountId user.AccountId, message HookCall) error {
+	sessions, err := s.sessions.FindByAccountId(accountId)
+	if err != nil || len(sessions) == 0 {
+		return ErrNoSessions
+	}
+	failed, err := s.sendToSessions(accountId, sessions, message)
+	if len(failed) == len(sessions) {
+		return err
+	}
+	return nil
+}
+
+// sendToSessions enqueues the call on each of the sessions. It returns
+// the sessions that did not take it and the last of their errors.
+func (s GohookTunnelServer) sendToSessions(accountId user.AccountId, sessions SessionList, message HookCall) (map[SessionId]bool, error) {
+	if message.Expired(time.Now()) {
+		s.expire(accountId, message, ReasonExpiredInQueue)
+		return nil, nil
+	}
+
+	var lastErr error
+	failed := map[SessionId]bool{}
+	// a call dropped by several sessions is only given up once
+	dropped := map[string]bool{}
+	for _, session := range sessions {
+		d, err := session.Enqueue(message)
+		if d != nil {
+			key := fmt.Sprintf("%s:%d", d.Id, d.Seq)
+			if !dropped[key] {
+				dropped[key] = true
+				s.deadLetter(accountId, *d, 1, ReasonBufferFull)
+			}
+		}
+		if err == ErrSlowConsumer {
+			s.logger.Log("msg", "Closing slow session", "sessionId", session.Id)
+			session.Close(grpc.Errorf(codes.ResourceExhausted, "%v", err))
+		}
+		if err == ErrSpillOverflow {
+			// the call is in the backlog with the other spilled calls
+			go s.deadLetterSpilled(session)
+			err = nil
+		}
+		if err != nil {
+			lastErr = err
+			failed[session.Id] = true
+		}
+	}
+	return failed, lastErr
+}
+
+// BufferDepths reports the send buffer depth of every session held
+// by this process. It is published as an expvar.
+func (s GohookTunnelServer) BufferDepths() interface{} {
+	depths := map[SessionId]int{}
+	total := 0
+	for _, session := range s.sessions.All() {
+		depth := session.BufferDepth()
+		depths[session.Id] += depth
+		total += depth
+	}
+	return struct {
+		Sessions map[SessionId]int `json:"sessions"`
+		Total    int               `json:"total"`
+	}{depths, total}
+}
+
+// CloseSessions closes every session of the account that is held
+// by this process.
+func (s GohookTunnelServer) CloseSessions(accountId user.AccountId, reason string, notice *Event) error {
 	sessions, err := s.sessions.FindByAccountId(accountId)
 	if err != nil {
 		return err
 	}
 
 	for _, session := range sessions {
-		session.Stream.Send(&pb.TunnelResponse{
-			Event: &pb.TunnelResponse_Hook{
-				Hook: &pb.HookCall{
-					Id:   string(message.Id),
-					Body: message.Body,
-				},
-			},
-		})
+		go s.closeSession(session, notice, grpc.Errorf(codes.PermissionDenied, "%s", reason))
+	}
+
+	return nil
+}
+
+// CloseSession closes the session if it is held by this process. The
+// notice is sent to every session of the account.
+func (s GohookTunnelServer) CloseSession(accountId user.AccountId, id SessionId, reason string, notice *Event) error {
+	sessions, err := s.sessions.FindByAccountId(accountId)
+	if err != nil {
+		return err
 	}
 
+	for _, session := range sessions {
+		if session.Id == id {
+			go s.closeSession(session, notice, grpc.Errorf(codes.Aborted, "%s", reason))
+		} else {
+			go s.sendEvent(session, notice)
+		}
+	}
+
+	return nil
+}
+
+// Watch wakes up a client polling for the hook calls of the account.
+// The process subscribes to the account while the client waits, so
+// polling clients can be served by any process.
+func (s GohookTunnelServer) Watch(accountId user.AccountId) (<-chan struct{}, func()) {
+	if err := s.subscribe(accountId); err != nil {
+		s.logger.Log("msg", "Subscribe failed", "account_id", accountId, "err", err)
+	}
+	woken, stop := s.watchers.watch(accountId)
+	return woken, func() {
+		stop()
+		s.unsubscribe(accountId)
+	}
+}
+
+// SendEvent sends the event to the sessions of the account held by
+// this process, or to all of them when the account id is empty.
+func (s GohookTunnelServer) SendEvent(accountId user.AccountId, event *Event) error {
+	var sessions SessionList
+	if accountId == "" {
+		sessions = s.sessions.All()
+	} else {
+		var err error
+		sessions, err = s.sessions.FindByAccountId(accountId)
+		if err != nil {
+			return err
+		}
+	}
+
+	// sent in the background so a slow client does not hold up the
+	// queue
+	for _, session := range sessions {
+		go s.sendEvent(session, event)
+	}
 	return nil
 }
 
+func (s GohookTunnelServer) sendEvent(session *Session, event *Event) {
+	if err := notify(session, event); err != nil {
+		s.logger.Log("msg", "Failed to send event", "sessionId", session.Id, "err", err)
+	}
+}
+
+func (s GohookTunnelServer) closeSession(session *Session, notice *Event, err error) {
+	s.sendEvent(session, notice)
+	session.Close(err)
+}
+
 // Tunnel transport handler
-func (s *GohookTunnelServer) Tunnel(req *pb.TunnelRequest, stream pb.Gohook_TunnelServer) error {
+func (s *GohookTunnelServer) Tunnel(stream pb.Gohook_TunnelServer) error {
 	streamCtx := stream.Context()
 
 	token, err := getTokenFromContext(streamCtx)
@@ -55,36 +239,319 @@ func (s *GohookTunnelServer) Tunnel(req *pb.TunnelRequest, stream pb.Gohook_Tunn
 		return err
 	}
 
-	account, err := s.auth.AuthAccountFromToken(token)
+	account, err := s.accept(token)
 	if err != nil {
 		return err
 	}
 
+	return s.serve(stream, account, clientIPFromContext(streamCtx))
+}
+
+// accept authenticates a new tunnel. New tunnels are refused while
+// the server is draining.
+func (s *GohookTunnelServer) accept(token string) (*user.Account, error) {
+	if s.isDraining() {
+		return nil, grpc.Errorf(codes.Unavailable, "%v", ErrServerDraining)
+	}
+
+	account, err := s.auth.AuthAccountFromToken(token)
+	if err != nil {
+		if err == user.ErrAccountSuspended {
+			return nil, grpc.Errorf(codes.PermissionDenied, "%v", err)
+		}
+		return nil, grpc.Errorf(codes.Unauthenticated, "%v", err)
+	}
+
 	s.logger.Log("msg", "Have authed user", "account_id", account.Id, "account_token", account.Token)
+	return account, nil
+}
+
+// serve runs the session of an accepted tunnel until the stream ends.
+// It is shared by every tunnel transport.
+func (s *GohookTunnelServer) serve(stream TunnelStream, account *user.Account, clientIP string) error {
+	streamCtx := stream.Context()
 
-	id := uuid.NewV4()
-	newSession := &Session{
-		Id:        SessionId(id.String()),
-		AccountId: account.Id,
-		Start:     time.Now(),
-		Stream:    stream,
+	// The first message opens the tunnel
+	req, err := stream.Recv()
+	if err != nil {
+		return err
 	}
+	open, err := decodeTunnelOpen(req)
+	if err != nil {
+		s.logger.Log("msg", "Refused client", "account_id", account.Id, "err", err)
+		return grpc.Errorf(codes.FailedPrecondition, "%v: minimum protocol version is %d", err, MinProtocolVersion)
+	}
+	interval := NegotiateHeartbeat(open.heartbeatMs)
 
-	err = s.sessions.Add(newSession)
+	var sessionId SessionId
+	var resumeToken ResumeToken
+	var resumed bool
+	if open.capabilities.Has(CapResume) {
+		sessionId, resumed = s.resumeSession(account.Id, open.resumeToken)
+		resumeToken = open.resumeToken
+	}
+	if !resumed {
+		sessionId = SessionId(uuid.NewV4().String())
+		resumeToken = ""
+		if open.capabilities.Has(CapResume) {
+			resumeToken, err = s.resumes.NewToken(ResumeState{
+				AccountId: account.Id,
+				SessionId: sessionId,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	newSession := NewSession(sessionId, account.Id, stream, s.buffers)
+	newSession.ResumeToken = resumeToken
+	newSession.ClientIP = clientIP
+	newSession.ClientName = open.clientName
+	newSession.ClientVersion = open.clientVersion
+	newSession.ProtocolVersion = open.protocolVersion
+	newSession.Capabilities = open.capabilities
+	newSession.Labels = open.labels
+	newSession.Subscribe(open.subscription)
+	newSession.OnExpired = func(call HookCall, reason string) {
+		s.expire(newSession.AccountId, call, reason)
+	}
+
+	others, err := s.remoteSessions(account.Id, sessionId)
 	if err != nil {
 		return err
 	}
-	s.logger.Log("msg", "Added stream to list", "streamId", newSession.Id, "account_id", newSession.AccountId)
+	err = s.sessions.AddLimited(newSession, account.EffectiveLimits(), others)
+	if err != nil {
+		if _, ok := err.(user.QuotaError); ok {
+			return grpc.Errorf(codes.ResourceExhausted, "%v", err)
+		}
+		return err
+	}
+	if err := s.subscribe(account.Id); err != nil {
+		s.sessions.Remove(newSession)
+		return err
+	}
+	defer s.unsubscribe(account.Id)
+	if err := s.register(newSession); err != nil {
+		s.sessions.Remove(newSession)
+		return err
+	}
+	defer s.unregister(newSession)
+	// The retention window of the token starts once the client is gone
+	defer s.touchResumeToken(resumeToken)
 
+	// The backlog is read once the session receives new calls, so no
+	// call published meanwhile is missed. Calls in both are sent once.
+	// Clients switching over from polling pass the last call they
+	// acked to catch up without a resume token.
+	lastSeq := open.lastSeq
+	var backlog []HookCall
+	if resumed || (lastSeq > 0 && open.capabilities.Has(CapResume)) {
+		var complete bool
+		backlog, complete, err = s.resumes.Since(account.Id, lastSeq)
+		if err != nil {
+			return err
+		}
+		resumed = resumed && complete
+	} else {
+		lastSeq = 0
+	}
+	s.logger.Log("msg", "Added stream to list", "streamId", newSession.Id, "account_id", newSession.AccountId, "heartbeat", interval, "resumed", resumed, "backlog", len(backlog), "client", open.clientName, "client_version", open.clientVersion, "protocol", open.protocolVersion)
+
+	err = newSession.Open(&pb.TunnelReady{
+		SessionId:           string(newSession.Id),
+		HeartbeatIntervalMs: int64(interval / time.Millisecond),
+		ResumeToken:         string(resumeToken),
+		Resumed:             resumed,
+		ProtocolVersion:     ProtocolVersion,
+		ServerVersion:       Version,
+		Capabilities:        open.capabilities.Strings(),
+	}, lastSeq, backlog)
+	if err != nil {
+		return err
+	}
+
+	go newSession.WriteLoop(func(lastSeq int64) ([]HookCall, error) {
+		calls, complete, err := s.resumes.Since(newSession.AccountId, lastSeq)
+		if err == nil && !complete {
+			s.logger.Log("msg", "Spilled calls expired", "sessionId", newSession.Id, "lastSeq", lastSeq)
+		}
+		return calls, err
+	})
+
+	pongs := make(chan int64)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			switch {
+			case req.GetPong() != nil:
+				select {
+				case pongs <- req.GetPong().Seq:
+				case <-streamCtx.Done():
+					return
+				}
+			case req.GetSubscribe() != nil:
+				newSession.Subscribe(decodeSubscription(req.GetSubscribe()))
+				s.register(newSession)
+				s.logger.Log("msg", "Changed subscription", "sessionId", newSession.Id)
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+	heartbeatC := heartbeat.C
+	if open.protocolVersion == 0 {
+		// version 0 clients neither read pings nor answer them
+		heartbeatC = nil
+	}
+
+	refresh := time.NewTicker(SessionRefreshInterval)
+	defer refresh.Stop()
+
+	var sent, acked int64
 	for {
 		select {
 		case <-streamCtx.Done():
 			err := streamCtx.Err()
 			s.logger.Log("msg", "Stream done", "sessionId", newSession.Id, "err", err)
-			return s.sessions.Remove(newSession.AccountId, newSession.Id)
+			return nil
+		case <-s.draining:
+			s.logger.Log("msg", "Draining session", "sessionId", newSession.Id)
+			return s.drainSession(newSession)
+		case <-newSession.Done():
+			err := newSession.Err()
+			s.logger.Log("msg", "Session closed", "sessionId", newSession.Id, "err", err)
+			return err
+		case err := <-recvErr:
+			if err != io.EOF {
+				s.logger.Log("msg", "Stream receive failed", "sessionId", newSession.Id, "err", err)
+				return err
+			}
+			// A client that closed its side can not answer pings. It
+			// is left to the transport keepalive to detect.
+			s.logger.Log("msg", "Client closed send side, heartbeats disabled", "sessionId", newSession.Id)
+			heartbeatC = nil
+		case seq := <-pongs:
+			if seq > acked && seq <= sent {
+				acked = seq
+			}
+		case <-refresh.C:
+			if err := s.register(newSession); err != nil {
+				s.logger.Log("msg", "Session refresh failed", "sessionId", newSession.Id, "err", err)
+			}
+		case <-heartbeatC:
+			if sent-acked >= MaxMissedHeartbeats {
+				s.logger.Log("msg", "Missed heartbeats", "sessionId", newSession.Id, "missed", sent-acked)
+				return grpc.Errorf(codes.Unavailable, "%v", ErrHeartbeatTimeout)
+			}
+			s.touchResumeToken(resumeToken)
+			sent++
+			err := newSession.Send(&pb.TunnelResponse{
+				Event: &pb.TunnelResponse_Ping{
+					Ping: &pb.Ping{Seq: sent},
+				},
+			})
+			if err != nil {
+				s.logger.Log("msg", "Ping failed", "sessionId", newSession.Id, "err", err)
+				return err
+			}
+		}
+	}
+}
+
+// register puts the session into the session registry and records
+// the presence of this process for the account. It is called again at
+// every refresh.
+func (s *GohookTunnelServer) register(session *Session) error {
+	if err := s.registry.Put(session.Info(s.instance)); err != nil {
+		return err
+	}
+	return s.presence.Put(session.AccountId, s.instance)
+}
+
+// remoteSessions counts the sessions of the account registered by
+// other processes, leaving out the session being resumed. Processes
+// opening sessions at the same time may both be let through.
+func (s *GohookTunnelServer) remoteSessions(accountId user.AccountId, resumed SessionId) (int, error) {
+	infos, err := s.registry.FindByAccountId(accountId)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, info := range infos {
+		if info.Instance != s.instance && info.Id != resumed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// unregister removes the session, and the presence of this process
+// once it holds no other session of the account.
+func (s *GohookTunnelServer) unregister(session *Session) {
+	s.sessions.Remove(session)
+	s.registry.Remove(session.Info(s.instance))
+	if sessions, _ := s.sessions.FindByAccountId(session.AccountId); len(sessions) == 0 {
+		s.presence.Remove(session.AccountId, s.instance)
+	}
+}
+
+// subscribe makes the queue deliver the messages of the account to
+// this process, for queues that only deliver them on demand.
+func (s GohookTunnelServer) subscribe(accountId user.AccountId) error {
+	if sub, ok := s.queue.(AccountSubscriber); ok {
+		return sub.Subscribe(accountId)
+	}
+	return nil
+}
+
+func (s GohookTunnelServer) unsubscribe(accountId user.AccountId) {
+	if sub, ok := s.queue.(AccountSubscriber); ok {
+		if err := sub.Unsubscribe(accountId); err != nil {
+			s.logger.Log("msg", "Unsubscribe failed", "account_id", accountId, "err", err)
 		}
+	}
+}
+
+func (s *GohookTunnelServer) touchResumeToken(token ResumeToken) {
+	if token != "" {
+		s.resumes.Touch(token)
+	}
+}
+
+// resumeSession looks up the session the token was handed out to. A
+// session of the same id still held by this process is closed.
+func (s *GohookTunnelServer) resumeSession(accountId user.AccountId, token ResumeToken) (SessionId, bool) {
+	if token == "" {
+		return "", false
+	}
+	state, err := s.resumes.FindToken(token)
+	if err != nil || state.AccountId != accountId {
+		return "", false
+	}
+	if old, err := s.sessions.FindBySessionId(state.SessionId); err == nil {
+		old.Close(grpc.Errorf(codes.Aborted, "%v", ErrSessionResumed))
+	}
+	return state.SessionId, true
+}
 
+func clientIPFromContext(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
 	}
+	return host
 }
 
 func getTokenFromContext(ctx context.Context) (string, error) {
@@ -101,7 +568,7 @@ func getTokenFromContext(ctx context.Context) (string, error) {
 	return mdToken[0], nil
 }
 
-func MakeTunnelServer(authService user.AuthService, q HookQueue, logger log.Logger) (*GohookTunnelServer, error) {
+func MakeTunnelServer(authService user.AuthService, q HookQueue, resumes ResumeStore, registry SessionRegistry, presence Presence, deadLetters DeadLetterStore, expiries ExpiryRecorder, buffers BufferOptions, logger log.Logger) (*GohookTunnelServer, error) {
 	queuec, err := q.Listen()
 	if err != nil {
 		return nil, err
@@ -114,6 +581,18 @@ func MakeTunnelServer(authService user.AuthService, q HookQueue, logger log.Logg
 		logger:   logger,
 		queue:    q,
 		sessions: sessions,
+		resumes:  resumes,
+		registry: registry,
+		instance: NewInstanceId(),
+		presence: presence,
+		buffers:  buffers,
+		draining: make(chan struct{}),
+		hijacked: newHijackedConns(),
+		watchers: newWatchers(),
+
+		deadLetters: deadLetters,
+		expiries:    expiries,
+		queueClosed: make(chan struct{}),
 	}
 
 	// Process for handling queue messages
@@ -124,12 +603,36 @@ func MakeTunnelServer(authService user.AuthService, q HookQueue, logger log.Logg
 			// process incoming messages from RedisPubSub, and send messages.
 			case msg := <-queuec:
 				if msg == nil {
-					logger.Log("msg", "Message Channel has closed. Exiting.")
+					// sessions get no hook calls anymore, the
+					// process has to be replaced
+					logger.Log("msg", "Message Channel has closed. Exiting.", "err", ErrQueueClosed)
+					close(server.queueClosed)
 					return
 				}
 
-				logger.Log("msg", "Handling incoming messsage...", "message", msg.Hook.Id)
-				server.SendToStream(msg.AccountId, msg.Hook)
+				switch msg.Type {
+				case MessageKick:
+					logger.Log("msg", "Kicking session", "account_id", msg.AccountId, "sessionId", msg.SessionId)
+					server.CloseSession(msg.AccountId, msg.SessionId, msg.Reason, msg.Event)
+				case MessageDisconnect:
+					logger.Log("msg", "Closing sessions", "account_id", msg.AccountId, "reason", msg.Reason)
+					server.CloseSessions(msg.AccountId, msg.Reason, msg.Event)
+				case MessageEvent:
+					if msg.Event != nil {
+						server.SendEvent(msg.AccountId, msg.Event)
+					}
+				default:
+					logger.Log("msg", "Handling incoming messsage...", "message", msg.Hook.Id)
+					server.deliver(msg.AccountId, msg.Hook, 1, time.Now())
+					server.watchers.notify(msg.AccountId)
+				}
+
+				// the calls are in the buffers of the sessions now
+				if msg.Ack != nil {
+					if err := msg.Ack(); err != nil {
+						logger.Log("msg", "Ack failed", "err", err)
+					}
+				}
 			}
 
 		}
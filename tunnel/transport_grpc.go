@@ -0,0 +1,197 @@
+package tunnel
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"github.com/gohook/gohook-server/pb"
+	"github.com/gohook/gohook-server/user"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+type SessionServer struct {
+	listSessions grpctransport.Handler
+	kickSession  grpctransport.Handler
+	presence     grpctransport.Handler
+}
+
+func extractAuthToken(ctx context.Context, md *metadata.MD) context.Context {
+	if token, ok := (*md)["token"]; ok && len(token) > 0 {
+		return context.WithValue(ctx, "token", token[0])
+	}
+	return ctx
+}
+
+func MakeSessionServer(ctx context.Context, endpoints Endpoints, logger log.Logger) *SessionServer {
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorLogger(logger),
+		grpctransport.ServerBefore(extractAuthToken),
+	}
+	return &SessionServer{
+		listSessions: grpctransport.NewServer(
+			ctx,
+			endpoints.ListSessionsEndpoint,
+			DecodeGRPCListSessionsRequest,
+			EncodeGRPCListSessionsResponse,
+			options...,
+		),
+		kickSession: grpctransport.NewServer(
+			ctx,
+			endpoints.KickSessionEndpoint,
+			DecodeGRPCKickSessionRequest,
+			EncodeGRPCKickSessionResponse,
+			options...,
+		),
+		presence: grpctransport.NewServer(
+			ctx,
+			endpoints.PresenceEndpoint,
+			DecodeGRPCPresenceRequest,
+			EncodeGRPCPresenceResponse,
+			options...,
+		),
+	}
+}
+
+// grpcError maps service errors to their gRPC status codes.
+func grpcError(err error) error {
+	switch err {
+	case user.ErrAccountSuspended:
+		return grpc.Errorf(codes.PermissionDenied, "%v", err)
+	case ErrSessionNotFound:
+		return grpc.Errorf(codes.NotFound, "%v", err)
+	}
+	return err
+}
+
+// ListSessions transport handler
+func (s *SessionServer) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	_, rep, err := s.listSessions.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return rep.(*pb.ListSessionsResponse), nil
+}
+
+// KickSession transport handler
+func (s *SessionServer) KickSession(ctx context.Context, req *pb.KickSessionRequest) (*pb.KickSessionResponse, error) {
+	_, rep, err := s.kickSession.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return rep.(*pb.KickSessionResponse), nil
+}
+
+// Presence transport handler
+func (s *SessionServer) Presence(ctx context.Context, req *pb.PresenceRequest) (*pb.PresenceResponse, error) {
+	_, rep, err := s.presence.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return rep.(*pb.PresenceResponse), nil
+}
+
+// ListSessions transforms
+func EncodeGRPCListSessionsRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.ListSessionsRequest{}, nil
+}
+
+func DecodeGRPCListSessionsRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return listSessionsRequest{}, nil
+}
+
+func EncodeGRPCListSessionsResponse(_ context.Context, response interface{}) (interface{}, error) {
+	sessions := response.(SessionInfoList)
+	pbSessions := []*pb.Session{}
+	for _, s := range sessions {
+		pbSessions = append(pbSessions, &pb.Session{
+			Id:              string(s.Id),
+			Instance:        string(s.Instance),
+			Start:           s.Start.UnixNano(),
+			ClientIp:        s.ClientIP,
+			ClientName:      s.ClientName,
+			ClientVersion:   s.ClientVersion,
+			ProtocolVersion: s.Protocol,
+			Capabilities:    s.Capabilities,
+			Labels:          s.Labels,
+			Delivered:       s.Delivered,
+			Subscription:    encodeSubscription(s.Subscription),
+		})
+	}
+	return &pb.ListSessionsResponse{Sessions: pbSessions}, nil
+}
+
+func DecodeGRPCListSessionsResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	resp := grpcReply.(*pb.ListSessionsResponse)
+	sessions := SessionInfoList{}
+	for _, s := range resp.Sessions {
+		sessions = append(sessions, &SessionInfo{
+			Id:            SessionId(s.Id),
+			Instance:      InstanceId(s.Instance),
+			Start:         time.Unix(0, s.Start),
+			ClientIP:      s.ClientIp,
+			ClientName:    s.ClientName,
+			ClientVersion: s.ClientVersion,
+			Protocol:      s.ProtocolVersion,
+			Capabilities:  s.Capabilities,
+			Labels:        s.Labels,
+			Delivered:     s.Delivered,
+			Subscription:  decodeSubscription(s.Subscription),
+		})
+	}
+	return sessions, nil
+}
+
+// KickSession transforms
+func EncodeGRPCKickSessionRequest(_ context.Context, request interface{}) (interface{}, error) {
+	id := request.(SessionId)
+	return &pb.KickSessionRequest{Id: string(id)}, nil
+}
+
+func DecodeGRPCKickSessionRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.KickSessionRequest)
+	return SessionId(req.Id), nil
+}
+
+func EncodeGRPCKickSessionResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.KickSessionResponse{}, nil
+}
+
+func DecodeGRPCKickSessionResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return kickSessionResponse{}, nil
+}
+
+// Presence transforms
+func EncodeGRPCPresenceRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.PresenceRequest{}, nil
+}
+
+func DecodeGRPCPresenceRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return presenceRequest{}, nil
+}
+
+func EncodeGRPCPresenceResponse(_ context.Context, response interface{}) (interface{}, error) {
+	presence := response.(*PresenceInfo)
+	instances := []string{}
+	for _, instance := range presence.Instances {
+		instances = append(instances, string(instance))
+	}
+	return &pb.PresenceResponse{
+		Online:    presence.Online(),
+		Instances: instances,
+	}, nil
+}
+
+func DecodeGRPCPresenceResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	resp := grpcReply.(*pb.PresenceResponse)
+	presence := &PresenceInfo{
+		Instances: []InstanceId{},
+	}
+	for _, instance := range resp.Instances {
+		presence.Instances = append(presence.Instances, InstanceId(instance))
+	}
+	return presence, nil
+}
@@ -0,0 +1,476 @@
+package tunnel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/pb"
+	"github.com/gohook/gohook-server/user"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// maxFrameSize bounds the messages read from WebSocket clients. They
+// only send small control messages.
+const maxFrameSize = 64 << 10
+
+// Messages are the JSON mapping of TunnelResponse and TunnelRequest
+// with the field names of the proto file.
+var jsonMarshaler = &jsonpb.Marshaler{OrigName: true}
+
+// HTTPOptions configures the WebSocket and Server-Sent Events
+// transports.
+type HTTPOptions struct {
+	// AllowedOrigins are the origins of the pages, besides the server's
+	// own, allowed to open WebSocket tunnels. "*" allows every page.
+	AllowedOrigins []string
+	// TicketSecret seals the tickets. Processes behind the same load
+	// balancer need the same secret.
+	TicketSecret []byte
+}
+
+// MakeTunnelHTTPHandler serves the tunnel over WebSocket and over
+// Server-Sent Events for clients that can not use gRPC streams. The
+// token is read from the Authorization header. Browsers can not set
+// headers on either, they get a ticket from /tunnel/ticket with their
+// token and pass it in the ticket query parameter instead.
+func MakeTunnelHTTPHandler(server *GohookTunnelServer, opts HTTPOptions, logger log.Logger) (http.Handler, error) {
+	tickets, err := newTicketSealer(opts.TicketSecret)
+	if err != nil {
+		return nil, err
+	}
+	h := tunnelHTTPHandler{
+		server:  server,
+		tickets: tickets,
+		logger:  logger,
+		upgrader: &websocket.Upgrader{
+			CheckOrigin: checkOrigin(opts.AllowedOrigins),
+		},
+	}
+	m := mux.NewRouter()
+	m.HandleFunc("/tunnel/ticket", h.serveTicket).Methods("POST")
+	m.HandleFunc("/tunnel/ws", h.serveWebSocket).Methods("GET")
+	m.HandleFunc("/tunnel/sse", h.serveSSE).Methods("GET")
+	return m, nil
+}
+
+// MakeHealthHandler answers 200 while the server receives the messages
+// of the queue, and 503 with the reason once it does not, so the
+// process can be taken out of rotation and restarted.
+func MakeHealthHandler(server *GohookTunnelServer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := server.Health(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"status": "unhealthy", "error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	})
+}
+
+type tunnelHTTPHandler struct {
+	server   *GohookTunnelServer
+	tickets  ticketSealer
+	upgrader *websocket.Upgrader
+	logger   log.Logger
+}
+
+// serveTicket issues a ticket for the token in the Authorization
+// header.
+func (h tunnelHTTPHandler) serveTicket(w http.ResponseWriter, r *http.Request) {
+	token := bearerToken(r)
+	if _, err := h.server.accept(token); err != nil {
+		encodeHTTPTunnelError(w, err)
+		return
+	}
+	expires := time.Now().Add(TicketTTL)
+	ticket, err := h.tickets.issue(token, expires)
+	if err != nil {
+		encodeHTTPTunnelError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(struct {
+		Ticket  string    `json:"ticket"`
+		Expires time.Time `json:"expires"`
+	}{ticket, expires})
+}
+
+// authenticate accepts the tunnel of the token in the Authorization
+// header, or of the ticket in the query string.
+func (h tunnelHTTPHandler) authenticate(r *http.Request) (*user.Account, error) {
+	token := bearerToken(r)
+	if ticket := r.URL.Query().Get("ticket"); token == "" && ticket != "" {
+		var err error
+		token, err = h.tickets.open(ticket, time.Now())
+		if err != nil {
+			return nil, grpc.Errorf(codes.Unauthenticated, "%v", err)
+		}
+	}
+	return h.server.accept(token)
+}
+
+func (h tunnelHTTPHandler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	account, err := h.authenticate(r)
+	if err != nil {
+		encodeHTTPTunnelError(w, err)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Log("msg", "WebSocket upgrade failed", "err", err)
+		return
+	}
+	defer conn.Close()
+	h.server.hijacked.add(conn)
+	defer h.server.hijacked.remove(conn)
+	conn.SetReadLimit(maxFrameSize)
+
+	// The connection is hijacked, so the request context does not end
+	// with it. The stream ends once reading from the client fails.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	stream := &wsStream{ctx: ctx, cancel: cancel, conn: conn}
+
+	err = h.server.serve(stream, account, remoteIP(r))
+	code, text := websocket.CloseNormalClosure, ""
+	if err != nil {
+		code, text = wsCloseCode(grpc.Code(err)), grpc.ErrorDesc(err)
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, truncate(text, 120)), time.Now().Add(time.Second))
+}
+
+func (h tunnelHTTPHandler) serveSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming Unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	account, err := h.authenticate(r)
+	if err != nil {
+		encodeHTTPTunnelError(w, err)
+		return
+	}
+
+	open, err := decodeSSEOpen(r)
+	if err != nil {
+		encodeHTTPTunnelError(w, grpc.Errorf(codes.InvalidArgument, "%v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	stream := &sseStream{ctx: r.Context(), w: w, flusher: flusher, open: open}
+	defer stream.close()
+	go stream.keepalive(DefaultHeartbeatInterval)
+
+	if err := h.server.serve(stream, account, remoteIP(r)); err != nil {
+		stream.sendError(err)
+	}
+}
+
+// wsStream is a tunnel stream over a WebSocket connection. Every
+// message is a JSON encoded TunnelResponse or TunnelRequest.
+type wsStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	conn   *websocket.Conn
+}
+
+func (s *wsStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *wsStream) Send(res *pb.TunnelResponse) error {
+	var buf bytes.Buffer
+	if err := jsonMarshaler.Marshal(&buf, res); err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(websocket.TextMessage, buf.Bytes())
+}
+
+func (s *wsStream) Recv() (*pb.TunnelRequest, error) {
+	_, data, err := s.conn.ReadMessage()
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	req := &pb.TunnelRequest{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(data), req); err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return req, nil
+}
+
+// sseStream is a tunnel stream over Server-Sent Events. The client can
+// not send anything once the stream is open, so the TunnelOpen message
+// is read from the query string and heartbeats are not answered.
+type sseStream struct {
+	ctx    context.Context
+	open   *pb.TunnelOpen
+	opened bool
+
+	mtx     sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	// set once the handler returned and w must not be used anymore
+	closed bool
+}
+
+func (s *sseStream) Context() context.Context {
+	return s.ctx
+}
+
+// Send writes the message as a data only event. Hook calls carry their
+// sequence number as the event id, so a reconnecting EventSource with
+// a resume token in its url sends the last sequence number it handled.
+func (s *sseStream) Send(res *pb.TunnelResponse) error {
+	var buf bytes.Buffer
+	if err := jsonMarshaler.Marshal(&buf, res); err != nil {
+		return err
+	}
+
+	var seq int64
+	switch {
+	case res.GetHook() != nil:
+		seq = res.GetHook().Seq
+	case len(res.GetBatch().GetCalls()) > 0:
+		calls := res.GetBatch().GetCalls()
+		seq = calls[len(calls)-1].Seq
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return ErrSessionClosed
+	}
+	if seq != 0 {
+		if _, err := fmt.Fprintf(s.w, "id: %d\n", seq); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", buf.Bytes()); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// Recv returns the TunnelOpen message read from the request once, then
+// reports the send side of the client as closed.
+func (s *sseStream) Recv() (*pb.TunnelRequest, error) {
+	if s.opened {
+		return nil, io.EOF
+	}
+	s.opened = true
+	return &pb.TunnelRequest{
+		Event: &pb.TunnelRequest_Open{
+			Open: s.open,
+		},
+	}, nil
+}
+
+// keepalive writes a comment at every interval so proxies do not drop
+// the connection while no pings are sent.
+func (s *sseStream) keepalive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.mtx.Lock()
+		if s.closed {
+			s.mtx.Unlock()
+			return
+		}
+		fmt.Fprint(s.w, ": keepalive\n\n")
+		s.flusher.Flush()
+		s.mtx.Unlock()
+	}
+}
+
+// sendError ends the stream with an error event.
+func (s *sseStream) sendError(err error) {
+	data, _ := json.Marshal(tunnelError{
+		Code:  grpc.Code(err).String(),
+		Error: grpc.ErrorDesc(err),
+	})
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	fmt.Fprintf(s.w, "event: error\ndata: %s\n\n", data)
+	s.flusher.Flush()
+}
+
+func (s *sseStream) close() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.closed = true
+}
+
+// decodeSSEOpen builds the TunnelOpen message from the query string.
+// Hook ids and label selectors are repeated parameters, labels are
+// given as key=value.
+func decodeSSEOpen(r *http.Request) (*pb.TunnelOpen, error) {
+	q := r.URL.Query()
+	open := &pb.TunnelOpen{
+		ResumeToken:   q.Get("resume_token"),
+		ClientName:    q.Get("client_name"),
+		ClientVersion: q.Get("client_version"),
+		Subscription: &pb.Subscription{
+			HookIds:  q["hook_id"],
+			Selector: map[string]string{},
+		},
+	}
+	for _, label := range q["label"] {
+		kv := strings.SplitN(label, "=", 2)
+		if len(kv) != 2 {
+			return nil, errInvalidParam("label")
+		}
+		open.Subscription.Selector[kv[0]] = kv[1]
+	}
+	if v := q.Get("capabilities"); v != "" {
+		open.Capabilities = strings.Split(v, ",")
+	}
+	if v := q.Get("protocol_version"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, errInvalidParam("protocol_version")
+		}
+		open.ProtocolVersion = int32(n)
+	}
+
+	lastSeq := q.Get("last_seq")
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		lastSeq = id
+	}
+	if lastSeq != "" {
+		n, err := strconv.ParseInt(lastSeq, 10, 64)
+		if err != nil {
+			return nil, errInvalidParam("last_seq")
+		}
+		open.LastSeq = n
+	}
+	return open, nil
+}
+
+func errInvalidParam(name string) error {
+	return fmt.Errorf("Invalid Parameter: %s", name)
+}
+
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// checkOrigin allows WebSocket upgrades from clients that are not
+// browsers, which send no Origin, from pages of the server itself, and
+// from the allowed origins. Other pages could otherwise open tunnels
+// with a ticket they got hold of.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, o := range allowed {
+			if o == "*" || strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type tunnelError struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+// encodeHTTPTunnelError answers a tunnel that was refused before the
+// stream started.
+func encodeHTTPTunnelError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch grpc.Code(err) {
+	case codes.Unauthenticated:
+		status = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		status = http.StatusForbidden
+	case codes.InvalidArgument:
+		status = http.StatusBadRequest
+	case codes.Unavailable:
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(tunnelError{
+		Code:  grpc.Code(err).String(),
+		Error: grpc.ErrorDesc(err),
+	})
+}
+
+// wsCloseCode maps the status a session ended with to a WebSocket
+// close code.
+func wsCloseCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return websocket.CloseNormalClosure
+	case codes.Unavailable:
+		return websocket.CloseGoingAway
+	case codes.ResourceExhausted:
+		return websocket.CloseTryAgainLater
+	case codes.PermissionDenied, codes.Aborted, codes.FailedPrecondition, codes.InvalidArgument:
+		return websocket.ClosePolicyViolation
+	}
+	return websocket.CloseInternalServerErr
+}
+
+// truncate keeps close reasons within the size of a control frame.
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,42 @@
+package tunnel
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gohook/gohook-server/pb"
+	"golang.org/x/net/context"
+)
+
+func TestSSESendEmptyBatch(t *testing.T) {
+	w := httptest.NewRecorder()
+	s := &sseStream{ctx: context.Background(), w: w, flusher: w}
+	err := s.Send(&pb.TunnelResponse{
+		Event: &pb.TunnelResponse_Batch{Batch: &pb.HookBatch{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(w.Body.String(), "id:") {
+		t.Fatalf("expected no event id for an empty batch, got %q", w.Body.String())
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	check := checkOrigin([]string{"https://app.example.com"})
+	for origin, want := range map[string]bool{
+		"":                        true,
+		"https://gohook.io":       true,
+		"https://app.example.com": true,
+		"https://evil.example":    false,
+	} {
+		r := httptest.NewRequest("GET", "https://gohook.io/tunnel/ws", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if got := check(r); got != want {
+			t.Errorf("origin %q: expected %v, got %v", origin, want, got)
+		}
+	}
+}
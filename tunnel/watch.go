@@ -0,0 +1,56 @@
+package tunnel
+
+import (
+	"sync"
+
+	"github.com/gohook/gohook-server/user"
+)
+
+// Watcher wakes up clients polling for hook calls.
+type Watcher interface {
+	// Watch returns a channel that is closed once a hook call for the
+	// account comes in, and a func to stop watching.
+	Watch(accountId user.AccountId) (<-chan struct{}, func())
+}
+
+// watchers holds the channels of the clients waiting for the hook
+// calls of an account.
+type watchers struct {
+	mtx     sync.Mutex
+	waiting map[user.AccountId]map[chan struct{}]bool
+}
+
+func newWatchers() *watchers {
+	return &watchers{
+		waiting: make(map[user.AccountId]map[chan struct{}]bool),
+	}
+}
+
+func (w *watchers) watch(accountId user.AccountId) (<-chan struct{}, func()) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	c := make(chan struct{})
+	if w.waiting[accountId] == nil {
+		w.waiting[accountId] = make(map[chan struct{}]bool)
+	}
+	w.waiting[accountId][c] = true
+
+	stop := func() {
+		w.mtx.Lock()
+		defer w.mtx.Unlock()
+		delete(w.waiting[accountId], c)
+		if len(w.waiting[accountId]) == 0 {
+			delete(w.waiting, accountId)
+		}
+	}
+	return c, stop
+}
+
+func (w *watchers) notify(accountId user.AccountId) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	for c := range w.waiting[accountId] {
+		close(c)
+	}
+	delete(w.waiting, accountId)
+}
@@ -1,5 +1,13 @@
 package user
 
+import (
+	"errors"
+
+	"golang.org/x/net/context"
+)
+
+var ErrAccountSuspended = errors.New("Account Suspended")
+
 type AccountId string
 
 type AccountToken string
@@ -7,11 +15,36 @@ type AccountToken string
 type Account struct {
 	Id    AccountId
 	Token AccountToken
+
+	// Limits holds the per account overrides of the default limits.
+	Limits Limits
+
+	// Suspended accounts can't trigger hooks, call the api or open
+	// tunnels.
+	Suspended     bool
+	SuspendReason string
 }
 
 type AccountStore interface {
 	Add(account *Account) error
+	Update(account *Account) error
 	Remove(accountId AccountId) (*Account, error)
 	Find(accountId AccountId) (*Account, error)
 	FindByToken(token AccountToken) (*Account, error)
 }
+
+// ContextAccountStore is an AccountStore that attributes changes to
+// the request they are made for.
+type ContextAccountStore interface {
+	AccountStore
+	WithContext(ctx context.Context) AccountStore
+}
+
+// AccountsWithContext returns accounts attributing its changes to the
+// request in ctx, when it is able to.
+func AccountsWithContext(ctx context.Context, accounts AccountStore) AccountStore {
+	if c, ok := accounts.(ContextAccountStore); ok {
+		return c.WithContext(ctx)
+	}
+	return accounts
+}
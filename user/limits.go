@@ -0,0 +1,147 @@
+package user
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	LimitHooks          = "hooks"
+	LimitSessions       = "sessions"
+	LimitTriggersPerDay = "triggers_per_day"
+	LimitPayloadSize    = "payload_size"
+	LimitHookRate       = "hook_rate"
+	LimitAccountRate    = "account_rate"
+)
+
+// Limits define the resources an account is allowed to use. A zero
+// value falls back to the default limit, a negative value means
+// the resource is unlimited.
+type Limits struct {
+	MaxHooks          int
+	MaxSessions       int
+	MaxTriggersPerDay int
+	MaxPayloadSize    int
+
+	// HookRate and AccountRate are the sustained number of triggers
+	// per second allowed for a single hook and for the whole account.
+	// The burst values define how many triggers can be made at once.
+	HookRate     int
+	HookBurst    int
+	AccountRate  int
+	AccountBurst int
+}
+
+// DefaultLimits apply to every account without an override.
+var DefaultLimits = Limits{
+	MaxHooks:          25,
+	MaxSessions:       5,
+	MaxTriggersPerDay: 10000,
+	MaxPayloadSize:    1 << 20,
+	HookRate:          10,
+	HookBurst:         20,
+	AccountRate:       50,
+	AccountBurst:      100,
+}
+
+// QuotaError is returned when an account exceeds one of its limits.
+type QuotaError struct {
+	Limit string
+	Max   int
+}
+
+func (e QuotaError) Error() string {
+	return fmt.Sprintf("Quota exceeded: %s is limited to %d", e.Limit, e.Max)
+}
+
+// EffectiveLimits merges the account overrides with the defaults.
+func (a *Account) EffectiveLimits() Limits {
+	l := a.Limits
+	if l.MaxHooks == 0 {
+		l.MaxHooks = DefaultLimits.MaxHooks
+	}
+	if l.MaxSessions == 0 {
+		l.MaxSessions = DefaultLimits.MaxSessions
+	}
+	if l.MaxTriggersPerDay == 0 {
+		l.MaxTriggersPerDay = DefaultLimits.MaxTriggersPerDay
+	}
+	if l.MaxPayloadSize == 0 {
+		l.MaxPayloadSize = DefaultLimits.MaxPayloadSize
+	}
+	if l.HookRate == 0 {
+		l.HookRate = DefaultLimits.HookRate
+	}
+	if l.HookBurst == 0 {
+		l.HookBurst = DefaultLimits.HookBurst
+	}
+	if l.AccountRate == 0 {
+		l.AccountRate = DefaultLimits.AccountRate
+	}
+	if l.AccountBurst == 0 {
+		l.AccountBurst = DefaultLimits.AccountBurst
+	}
+	return l
+}
+
+// CheckHooks checks if another hook can be added to the existing ones.
+func (l Limits) CheckHooks(count int) error {
+	return check(LimitHooks, l.MaxHooks, count+1)
+}
+
+// CheckSessions checks if another session can be opened next to
+// the existing ones.
+func (l Limits) CheckSessions(count int) error {
+	return check(LimitSessions, l.MaxSessions, count+1)
+}
+
+// CheckTriggers checks the number of triggers made today, including
+// the current one.
+func (l Limits) CheckTriggers(count int) error {
+	return check(LimitTriggersPerDay, l.MaxTriggersPerDay, count)
+}
+
+// CheckPayload checks the size of a hook call body in bytes.
+func (l Limits) CheckPayload(size int) error {
+	return check(LimitPayloadSize, l.MaxPayloadSize, size)
+}
+
+func check(limit string, max, n int) error {
+	if max >= 0 && n > max {
+		return QuotaError{Limit: limit, Max: max}
+	}
+	return nil
+}
+
+// UsageCounter counts the usage of an account within fixed windows
+// of time, such as the number of triggers per day.
+type UsageCounter interface {
+	// Incr increments the counter for the current window and
+	// returns the new count.
+	Incr(accountId AccountId, key string, window time.Duration) (int, error)
+	// Decr gives back a count taken by Incr for a call that was
+	// rejected. Counters of past windows are left alone.
+	Decr(accountId AccountId, key string, window time.Duration) error
+}
+
+// RateLimitError is returned when a token bucket is empty.
+type RateLimitError struct {
+	Limit      string
+	RetryAfter time.Duration
+}
+
+func (e RateLimitError) Error() string {
+	return fmt.Sprintf("Rate limit exceeded: %s, retry in %s", e.Limit, e.RetryAfter)
+}
+
+// RateLimiter implements token buckets. Each bucket holds up to
+// burst tokens and is refilled with rate tokens per second.
+type RateLimiter interface {
+	// Take removes a token from the bucket identified by key. When
+	// the bucket is empty it returns false together with the time
+	// until the next token becomes available.
+	Take(key string, rate, burst int) (bool, time.Duration, error)
+	// Refund puts back a token taken for a call that was rejected by
+	// another bucket, so the call does not count against this one.
+	Refund(key string, rate, burst int) error
+}
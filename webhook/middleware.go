@@ -5,11 +5,111 @@ import (
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
+	"github.com/gohook/gohook-server/gohookd"
+	"github.com/gohook/gohook-server/user"
 	"golang.org/x/net/context"
 )
 
 type Middleware func(Service) Service
 
+// EndpointAccessMiddleware enforces the access policy of the called
+// hook and rejects calls to suspended accounts. Rejected calls are
+// recorded in the hook history. The hook is passed on in the context
+// under "hook".
+func EndpointAccessMiddleware(hooks gohookd.HookStore, accounts user.AccountStore, history gohookd.HistoryStore) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			req := request.(TriggerRequest)
+			hook, err := hooks.Scope(req.AccountId).Find(req.HookId)
+			if err != nil {
+				return nil, err
+			}
+
+			account, err := accounts.Find(hook.AccountId)
+			if err != nil {
+				return nil, err
+			}
+
+			ip, err := hook.Access.ClientIP(req.RemoteAddr, req.ForwardedFor)
+			if err == nil {
+				req.ClientIP = ip.String()
+				switch {
+				case account.Suspended:
+					err = user.ErrAccountSuspended
+				case !hook.Access.AllowsIP(ip):
+					err = gohookd.ErrForbidden
+				default:
+					err = hook.Access.Authenticate(req.Credentials)
+				}
+			}
+			if err != nil {
+				history.Scope(req.AccountId).Add(&gohookd.Delivery{
+					HookId:   req.HookId,
+					Method:   req.Method,
+					RemoteIP: req.ClientIP,
+					Status:   gohookd.DeliveryRejected,
+					Reason:   err.Error(),
+					Time:     time.Now(),
+				})
+				return nil, err
+			}
+			ctx = context.WithValue(ctx, "hook", hook)
+			return next(ctx, req)
+		}
+	}
+}
+
+// EndpointRateLimitMiddleware limits the rate of triggers per hook
+// and per account. A call rejected by either bucket uses up neither.
+// Rejected calls are recorded in the hook history.
+func EndpointRateLimitMiddleware(accounts user.AccountStore, limiter user.RateLimiter, history gohookd.HistoryStore) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			req := request.(TriggerRequest)
+			account, err := accounts.Find(req.AccountId)
+			if err != nil {
+				return nil, err
+			}
+			limits := account.EffectiveLimits()
+
+			buckets := []struct {
+				limit string
+				key   string
+				rate  int
+				burst int
+			}{
+				{user.LimitHookRate, "hook:" + string(req.AccountId) + ":" + string(req.HookId), limits.HookRate, limits.HookBurst},
+				{user.LimitAccountRate, "account:" + string(req.AccountId), limits.AccountRate, limits.AccountBurst},
+			}
+			for i, b := range buckets {
+				ok, retryAfter, err := limiter.Take(b.key, b.rate, b.burst)
+				if err == nil && ok {
+					continue
+				}
+				// tokens taken from the buckets before are given back,
+				// the call is not made
+				for _, taken := range buckets[:i] {
+					limiter.Refund(taken.key, taken.rate, taken.burst)
+				}
+				if err != nil {
+					return nil, err
+				}
+				err = user.RateLimitError{Limit: b.limit, RetryAfter: retryAfter}
+				history.Scope(req.AccountId).Add(&gohookd.Delivery{
+					HookId:   req.HookId,
+					Method:   req.Method,
+					RemoteIP: req.ClientIP,
+					Status:   gohookd.DeliveryRejected,
+					Reason:   err.Error(),
+					Time:     time.Now(),
+				})
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
 func EndpointLoggingMiddleware(logger log.Logger) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
@@ -1,44 +1,194 @@
 package webhook
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gohook/gohook-server/gohookd"
 	"github.com/gohook/gohook-server/tunnel"
+	"github.com/gohook/gohook-server/user"
 	"golang.org/x/net/context"
 )
 
+const triggerWindow = 24 * time.Hour
+
+// quotaWarningPercent is the share of the daily triggers at which the
+// clients of the account are warned.
+const quotaWarningPercent = 80
+
+// OfflineRetryAfter is suggested to callers of hooks that reject calls
+// while no client is connected.
+const OfflineRetryAfter = 30 * time.Second
+
+var ErrNoListeners = errors.New("No Listeners")
+
 type Service interface {
 	Trigger(ctx context.Context, trigger TriggerRequest) (*TriggerResponse, error)
 }
 
-func NewBasicService(store gohookd.HookStore, queue tunnel.HookQueue) Service {
+func NewBasicService(store gohookd.HookStore, queue tunnel.HookQueue, history gohookd.HistoryStore, accounts user.AccountStore, usage user.UsageCounter, presence tunnel.Presence) Service {
 	return &basicService{
-		hooks: store,
-		queue: queue,
+		hooks:    store,
+		queue:    queue,
+		history:  history,
+		accounts: accounts,
+		usage:    usage,
+		presence: presence,
 	}
 }
 
 type basicService struct {
-	hooks gohookd.HookStore
-	queue tunnel.HookQueue
+	hooks    gohookd.HookStore
+	queue    tunnel.HookQueue
+	history  gohookd.HistoryStore
+	accounts user.AccountStore
+	usage    user.UsageCounter
+	presence tunnel.Presence
 }
 
-func (s basicService) Trigger(_ context.Context, trigger TriggerRequest) (*TriggerResponse, error) {
-	hook, err := s.hooks.Scope(trigger.AccountId).Find(trigger.HookId)
-	if err != nil {
+func (s basicService) Trigger(ctx context.Context, trigger TriggerRequest) (*TriggerResponse, error) {
+	var err error
+	// looked up already when the access middleware ran
+	hook, ok := ctx.Value("hook").(*gohookd.Hook)
+	if !ok {
+		hook, err = s.hooks.Scope(trigger.AccountId).Find(trigger.HookId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkLimits(hook.AccountId, len(trigger.Body)); err != nil {
+		s.reject(hook, trigger, err)
 		return nil, err
 	}
 
+	code := 200
+	if hook.Offline != gohookd.OfflineAccept {
+		presence, err := s.presence.Find(hook.AccountId)
+		if err != nil {
+			s.refundTrigger(hook.AccountId)
+			return nil, err
+		}
+		if !presence.Online() {
+			if hook.Offline == gohookd.OfflineReject {
+				s.refundTrigger(hook.AccountId)
+				s.reject(hook, trigger, ErrNoListeners)
+				return nil, ErrNoListeners
+			}
+			// kept in the tunnel backlog until a client resumes
+			code = 202
+		}
+	}
+
+	now := time.Now()
+	call := tunnel.HookCall{
+		Id:       string(hook.Id),
+		Method:   trigger.Method,
+		Body:     trigger.Body,
+		Labels:   hook.Labels,
+		Priority: hook.Priority,
+	}
+	if hook.TTL > 0 {
+		call.Expires = now.Add(hook.TTL)
+	}
+	if hook.Delay > 0 {
+		// held back by the queue, delivered later
+		call.NotBefore = now.Add(hook.Delay)
+		code = 202
+	}
+
 	// Broadcast message with the userid and hook data
-	err = s.queue.Broadcast(&tunnel.QueueMessage{
+	err = tunnel.BroadcastContext(ctx, s.queue, &tunnel.QueueMessage{
 		AccountId: hook.AccountId,
-		Hook: tunnel.HookCall{
-			Id:     string(hook.Id),
-			Method: trigger.Method,
-			Body:   trigger.Body,
-		},
+		Hook:      call,
 	})
 	if err != nil {
+		s.refundTrigger(hook.AccountId)
 		return nil, err
 	}
-	return &TriggerResponse{200}, nil
+
+	s.history.Scope(hook.AccountId).Add(&gohookd.Delivery{
+		HookId:   hook.Id,
+		Method:   trigger.Method,
+		RemoteIP: trigger.ClientIP,
+		Status:   gohookd.DeliveryQueued,
+		Time:     time.Now(),
+	})
+	return &TriggerResponse{code}, nil
+}
+
+func (s basicService) reject(hook *gohookd.Hook, trigger TriggerRequest, reason error) {
+	s.history.Scope(hook.AccountId).Add(&gohookd.Delivery{
+		HookId:   hook.Id,
+		Method:   trigger.Method,
+		RemoteIP: trigger.ClientIP,
+		Status:   gohookd.DeliveryRejected,
+		Reason:   reason.Error(),
+		Time:     time.Now(),
+	})
+}
+
+func (s basicService) checkLimits(accountId user.AccountId, size int) error {
+	account, err := s.accounts.Find(accountId)
+	if err != nil {
+		return err
+	}
+	limits := account.EffectiveLimits()
+	if err := limits.CheckPayload(size); err != nil {
+		return err
+	}
+	count, err := s.usage.Incr(accountId, user.LimitTriggersPerDay, triggerWindow)
+	if err != nil {
+		return err
+	}
+	max := limits.MaxTriggersPerDay
+	if max > 0 && (count == max*quotaWarningPercent/100 || count == max) {
+		s.warn(accountId, user.LimitTriggersPerDay, count, max)
+	}
+	if err := limits.CheckTriggers(count); err != nil {
+		s.refundTrigger(accountId)
+		return err
+	}
+	return nil
+}
+
+// refundTrigger gives back the count of a rejected trigger, so only
+// accepted calls use up the daily quota.
+func (s basicService) refundTrigger(accountId user.AccountId) {
+	s.usage.Decr(accountId, user.LimitTriggersPerDay, triggerWindow)
+}
+
+// warn tells the connected clients of the account that it is close
+// to one of its limits.
+func (s basicService) warn(accountId user.AccountId, limit string, used, max int) {
+	s.queue.Broadcast(&tunnel.QueueMessage{
+		Type:      tunnel.MessageEvent,
+		AccountId: accountId,
+		Event: &tunnel.Event{
+			Type:  tunnel.EventQuotaWarning,
+			Limit: limit,
+			Used:  used,
+			Max:   max,
+		},
+	})
+}
+
+// NewExpiryRecorder records the calls the tunnel dropped because they
+// expired in the history of their hook.
+func NewExpiryRecorder(history gohookd.HistoryStore) tunnel.ExpiryRecorder {
+	return expiryRecorder{history}
+}
+
+type expiryRecorder struct {
+	history gohookd.HistoryStore
+}
+
+func (r expiryRecorder) RecordExpired(accountId user.AccountId, call tunnel.HookCall, reason string) error {
+	return r.history.Scope(accountId).Add(&gohookd.Delivery{
+		HookId: gohookd.HookID(call.Id),
+		Method: call.Method,
+		Status: gohookd.DeliveryExpired,
+		Reason: reason,
+		Time:   time.Now(),
+	})
 }
@@ -3,8 +3,12 @@ package webhook
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-kit/kit/log"
 	httptransport "github.com/go-kit/kit/transport/http"
@@ -14,24 +18,59 @@ import (
 	"golang.org/x/net/context"
 )
 
-func MakeWebhookHTTPServer(ctx context.Context, endpoints Endpoints, logger log.Logger, origin string) http.Handler {
+func MakeWebhookHTTPServer(ctx context.Context, endpoints Endpoints, accounts user.AccountStore, logger log.Logger, origin string) http.Handler {
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorEncoder(errorEncoder),
 		httptransport.ServerErrorLogger(logger),
 	}
 	m := mux.NewRouter()
-	transportHandleFunc := httptransport.NewServer(
+	transportHandleFunc := limitPayload(accounts, httptransport.NewServer(
 		ctx,
 		endpoints.TriggerEndpoint,
 		DecodeHTTPTriggerRequest,
 		EncodeHTTPTriggerResponse,
 		options...,
-	)
+	))
 	m.Handle("/{accountId}/{hookId}", transportHandleFunc)
 	m.Handle("/{hookId}", transportHandleFunc).Host(fmt.Sprintf("{accountId}.%s", origin))
 	return m
 }
 
+// limitPayload stops reading the body of a call past the payload limit
+// of the account, so oversized bodies are never held in memory. The
+// service rejects bodies of exactly one byte over the limit, reading
+// any further fails with the quota error.
+func limitPayload(accounts user.AccountStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		max := user.DefaultLimits.MaxPayloadSize
+		if account, err := accounts.Find(user.AccountId(mux.Vars(r)["accountId"])); err == nil {
+			max = account.EffectiveLimits().MaxPayloadSize
+		}
+		if max >= 0 {
+			r.Body = &payloadReader{
+				ReadCloser: http.MaxBytesReader(w, r.Body, int64(max)+1),
+				max:        max,
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type payloadReader struct {
+	io.ReadCloser
+	max  int
+	read int
+}
+
+func (p *payloadReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	p.read += n
+	if err != nil && err != io.EOF && p.read > p.max {
+		err = user.QuotaError{Limit: user.LimitPayloadSize, Max: p.max}
+	}
+	return n, err
+}
+
 type errorWrapper struct {
 	Error string `json:"error"`
 }
@@ -45,9 +84,32 @@ func errorEncoder(_ context.Context, err error, w http.ResponseWriter) {
 		switch e.Domain {
 		case httptransport.DomainDecode:
 			code = http.StatusBadRequest
+			if _, ok := e.Err.(user.QuotaError); ok {
+				code = http.StatusRequestEntityTooLarge
+			}
 
 		case httptransport.DomainDo:
 			code = http.StatusBadRequest
+			switch doErr := e.Err.(type) {
+			case gohookd.AuthError:
+				code = http.StatusUnauthorized
+				w.Header().Set("WWW-Authenticate", doErr.Challenge())
+			case user.QuotaError:
+				code = http.StatusTooManyRequests
+				if doErr.Limit == user.LimitPayloadSize {
+					code = http.StatusRequestEntityTooLarge
+				}
+			case user.RateLimitError:
+				code = http.StatusTooManyRequests
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(doErr.RetryAfter.Seconds()))))
+			}
+			switch e.Err {
+			case gohookd.ErrForbidden, gohookd.ErrUnknownCaller, user.ErrAccountSuspended:
+				code = http.StatusForbidden
+			case ErrNoListeners:
+				code = http.StatusServiceUnavailable
+				w.Header().Set("Retry-After", strconv.Itoa(int(OfflineRetryAfter.Seconds())))
+			}
 		}
 	}
 
@@ -63,14 +125,36 @@ func DecodeHTTPTriggerRequest(_ context.Context, r *http.Request) (request inter
 		return nil, err
 	}
 	req := TriggerRequest{
-		AccountId: user.AccountId(accountId),
-		HookId:    gohookd.HookID(hookId),
-		Method:    r.Method,
-		Body:      body,
+		AccountId:    user.AccountId(accountId),
+		HookId:       gohookd.HookID(hookId),
+		Method:       r.Method,
+		Body:         body,
+		RemoteAddr:   r.RemoteAddr,
+		ForwardedFor: r.Header["X-Forwarded-For"],
+		Credentials:  credentialsFromRequest(r),
 	}
 	return req, nil
 }
 
+func credentialsFromRequest(r *http.Request) gohookd.Credentials {
+	if username, password, ok := r.BasicAuth(); ok {
+		return gohookd.Credentials{
+			Username: username,
+			Password: password,
+		}
+	}
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return gohookd.Credentials{
+			Token: strings.TrimSpace(auth[7:]),
+		}
+	}
+	return gohookd.Credentials{}
+}
+
 func EncodeHTTPTriggerResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	if res, ok := response.(*TriggerResponse); ok && res.Code != 0 {
+		w.WriteHeader(res.Code)
+	}
 	return json.NewEncoder(w).Encode(response)
 }
@@ -6,10 +6,17 @@ import (
 )
 
 type TriggerRequest struct {
-	AccountId user.AccountId
-	HookId    gohookd.HookID
-	Method    string
-	Body      []byte
+	AccountId    user.AccountId
+	HookId       gohookd.HookID
+	Method       string
+	Body         []byte
+	RemoteAddr   string
+	ForwardedFor []string
+	Credentials  gohookd.Credentials
+
+	// ClientIP is the resolved address of the original caller. It
+	// is set once the access policy of the hook has been checked.
+	ClientIP string
 }
 
 type TriggerResponse struct {